@@ -0,0 +1,356 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Applications checks the contract of the ApplicationRepository
+func Applications(t *testing.T, newRepos Factory) {
+	t.Run("find created application", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+		application := newApplication(student.ID, project.ID, now)
+		must(t, r.Applications.Create(application))
+
+		found, err := r.Applications.FindByID(application.ID)
+		must(t, err)
+		assertApplication(t, found, application)
+
+		found, err = r.Applications.FindByStudentAndProject(student.ID, project.ID)
+		must(t, err)
+		assertApplication(t, found, application)
+
+		if _, err := r.Applications.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown application should fail")
+		}
+	})
+
+	t.Run("student applies once per project", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+		must(t, r.Applications.Create(newApplication(student.ID, project.ID, now)))
+
+		if err := r.Applications.Create(newApplication(student.ID, project.ID, now)); err == nil {
+			t.Error("applying twice to the same project should fail")
+		}
+	})
+
+	t.Run("ordering", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		first := addProject(t, r, company.ID, owner.ID)
+		second := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+		other := createStudent(t, r)
+
+		older := newApplication(student.ID, first.ID, now)
+		newer := newApplication(student.ID, second.ID, now.Add(time.Hour))
+		latest := newApplication(other.ID, first.ID, now.Add(2*time.Hour))
+		must(t, r.Applications.Create(newer))
+		must(t, r.Applications.Create(older))
+		must(t, r.Applications.Create(latest))
+
+		found, err := r.Applications.FindByStudent(student.ID)
+		must(t, err)
+		if ids := applicationIDs(found); !sameStrings(ids, []string{newer.ID, older.ID}) {
+			t.Errorf("FindByStudent = %v, want newest first %v", ids, []string{newer.ID, older.ID})
+		}
+
+		found, err = r.Applications.FindByProject(first.ID)
+		must(t, err)
+		if ids := applicationIDs(found); !sameStrings(ids, []string{older.ID, latest.ID}) {
+			t.Errorf("FindByProject = %v, want oldest first %v", ids, []string{older.ID, latest.ID})
+		}
+	})
+
+	t.Run("update only changes status", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+		application := newApplication(student.ID, project.ID, now)
+		must(t, r.Applications.Create(application))
+
+		changed := application
+		changed.Status = d.ApplicationUnderReview
+		changed.UpdatedAt = now.Add(time.Hour)
+		changed.Motivation = "another motivation"
+		mail := newMail(student.User.Email, now)
+		must(t, r.Applications.UpdateWithMail(changed, mail))
+
+		found, err := r.Applications.FindByID(application.ID)
+		must(t, err)
+		application.Status = changed.Status
+		application.UpdatedAt = changed.UpdatedAt
+		assertApplication(t, found, application)
+
+		mails, err := r.Outbox.ClaimDue(now, time.Minute, 10)
+		must(t, err)
+		if ids := mailIDs(mails); !sameSet(ids, []string{mail.ID}) {
+			t.Errorf("queued mails = %v, want %v", ids, []string{mail.ID})
+		}
+	})
+}
+
+// Shortlist checks the contract of the ShortlistRepository
+func Shortlist(t *testing.T, newRepos Factory) {
+	t.Run("find created entry", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		entry := newShortlistEntry(company.ID, createStudent(t, r).ID, owner.ID, now)
+		must(t, r.Shortlist.Create(entry))
+
+		found, err := r.Shortlist.FindByID(entry.ID)
+		must(t, err)
+		assertShortlistEntry(t, found, entry)
+
+		if _, err := r.Shortlist.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown entry should fail")
+		}
+	})
+
+	t.Run("find by company recently updated first", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		other, otherOwner := createCompany(t, r)
+		older := newShortlistEntry(company.ID, createStudent(t, r).ID, owner.ID, now)
+		newer := newShortlistEntry(company.ID, createStudent(t, r).ID, owner.ID, now.Add(time.Hour))
+		must(t, r.Shortlist.Create(older))
+		must(t, r.Shortlist.Create(newer))
+		must(t, r.Shortlist.Create(newShortlistEntry(other.ID, createStudent(t, r).ID, otherOwner.ID, now)))
+
+		found, err := r.Shortlist.FindByCompany(company.ID)
+		must(t, err)
+		if ids := shortlistIDs(found); !sameStrings(ids, []string{newer.ID, older.ID}) {
+			t.Errorf("FindByCompany = %v, want %v", ids, []string{newer.ID, older.ID})
+		}
+
+		older.UpdatedAt = now.Add(2 * time.Hour)
+		must(t, r.Shortlist.Update(older))
+
+		found, err = r.Shortlist.FindByCompany(company.ID)
+		must(t, err)
+		if ids := shortlistIDs(found); !sameStrings(ids, []string{older.ID, newer.ID}) {
+			t.Errorf("FindByCompany after update = %v, want %v", ids, []string{older.ID, newer.ID})
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		entry := newShortlistEntry(company.ID, createStudent(t, r).ID, owner.ID, now)
+		must(t, r.Shortlist.Create(entry))
+
+		entry.ProjectID = project.ID
+		entry.Notes = "invite for an interview"
+		entry.Tags = nil
+		entry.UpdatedAt = now.Add(time.Hour)
+		must(t, r.Shortlist.Update(entry))
+
+		found, err := r.Shortlist.FindByID(entry.ID)
+		must(t, err)
+		assertShortlistEntry(t, found, entry)
+
+		must(t, r.Shortlist.Delete(entry.ID))
+		if _, err := r.Shortlist.FindByID(entry.ID); err == nil {
+			t.Error("deleted entry should not be found")
+		}
+	})
+
+	t.Run("student once per project", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+		entry := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		entry.ProjectID = project.ID
+		must(t, r.Shortlist.Create(entry))
+
+		duplicate := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		duplicate.ProjectID = project.ID
+		if err := r.Shortlist.Create(duplicate); err != e.ErrorAlreadyShortlisted {
+			t.Fatalf("Create of duplicate = %v, want %v", err, e.ErrorAlreadyShortlisted)
+		}
+
+		// an entry without a project can't be moved to the project either
+		other := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		must(t, r.Shortlist.Create(other))
+
+		other.ProjectID = project.ID
+		if err := r.Shortlist.Update(other); err != e.ErrorAlreadyShortlisted {
+			t.Fatalf("Update to duplicate = %v, want %v", err, e.ErrorAlreadyShortlisted)
+		}
+
+		// nor can the student be on the shortlist twice without a project
+		unlinked := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		if err := r.Shortlist.Create(unlinked); err != e.ErrorAlreadyShortlisted {
+			t.Fatalf("Create of duplicate without project = %v, want %v", err, e.ErrorAlreadyShortlisted)
+		}
+
+		found, err := r.Shortlist.FindByCompany(company.ID)
+		must(t, err)
+		if ids := shortlistIDs(found); !sameSet(ids, []string{entry.ID, other.ID}) {
+			t.Errorf("FindByCompany = %v, want %v", ids, []string{entry.ID, other.ID})
+		}
+	})
+}
+
+// Blocks checks the contract of the BlockRepository
+func Blocks(t *testing.T, newRepos Factory) {
+	t.Run("create, find and delete", func(t *testing.T) {
+		r := newRepos(t)
+		student := createStudent(t, r)
+		first, _ := createCompany(t, r)
+		second, _ := createCompany(t, r)
+
+		must(t, r.Blocks.Create(d.Block{StudentID: student.ID, CompanyID: first.ID, CreatedAt: now}))
+		must(t, r.Blocks.Create(d.Block{StudentID: student.ID, CompanyID: second.ID, CreatedAt: now.Add(time.Hour)}))
+
+		// blocking a company again changes nothing
+		must(t, r.Blocks.Create(d.Block{StudentID: student.ID, CompanyID: first.ID, CreatedAt: now.Add(2 * time.Hour)}))
+
+		blocks, err := r.Blocks.FindByStudent(student.ID)
+		must(t, err)
+		if len(blocks) != 2 || blocks[0].CompanyID != second.ID || blocks[1].CompanyID != first.ID ||
+			!blocks[1].CreatedAt.Equal(now) {
+			t.Errorf("FindByStudent = %+v, want newest first", blocks)
+		}
+
+		must(t, r.Blocks.Delete(student.ID, first.ID))
+		blocked, err := r.Blocks.Exists(student.ID, first.ID)
+		must(t, err)
+		if blocked {
+			t.Error("deleted block should not exist")
+		}
+
+		blocked, err = r.Blocks.Exists(student.ID, second.ID)
+		must(t, err)
+		if !blocked {
+			t.Error("block should exist")
+		}
+	})
+}
+
+// ResumeRequests checks the contract of the ResumeRequestRepository
+func ResumeRequests(t *testing.T, newRepos Factory) {
+	t.Run("create, grant and delete", func(t *testing.T) {
+		r := newRepos(t)
+		student := createStudent(t, r)
+		first, firstOwner := createCompany(t, r)
+		second, secondOwner := createCompany(t, r)
+		firstRecruiter := createRepresentative(t, r, first.ID, d.CompanyRecruiter)
+
+		older := d.ResumeRequest{StudentID: student.ID, CompanyID: first.ID, RequestedBy: firstOwner.ID, RequestedAt: now}
+		newer := d.ResumeRequest{StudentID: student.ID, CompanyID: second.ID, RequestedBy: secondOwner.ID, RequestedAt: now.Add(time.Hour)}
+		must(t, r.ResumeRequests.Create(older))
+		must(t, r.ResumeRequests.Create(newer))
+
+		// asking again keeps the first request
+		again := d.ResumeRequest{StudentID: student.ID, CompanyID: first.ID, RequestedBy: firstRecruiter.ID, RequestedAt: now.Add(2 * time.Hour)}
+		must(t, r.ResumeRequests.Create(again))
+
+		// a granted request keeps the moment it was first granted
+		must(t, r.ResumeRequests.Grant(student.ID, first.ID, now.Add(time.Hour)))
+		must(t, r.ResumeRequests.Grant(student.ID, first.ID, now.Add(2*time.Hour)))
+
+		requests, err := r.ResumeRequests.FindByStudent(student.ID)
+		must(t, err)
+		if len(requests) != 2 || requests[0].CompanyID != second.ID || requests[1].CompanyID != first.ID {
+			t.Fatalf("FindByStudent = %+v, want newest first", requests)
+		}
+
+		if requests[1].RequestedBy != firstOwner.ID || !requests[1].GrantedAt.Equal(now.Add(time.Hour)) ||
+			!requests[0].GrantedAt.IsZero() {
+			t.Errorf("FindByStudent = %+v, want only the first request granted", requests)
+		}
+
+		if err := r.ResumeRequests.Grant(student.ID, newID(), now); err == nil {
+			t.Error("Grant of unknown request should fail")
+		}
+
+		must(t, r.ResumeRequests.Delete(student.ID, second.ID))
+		requests, err = r.ResumeRequests.FindByStudent(student.ID)
+		must(t, err)
+		if len(requests) != 1 || requests[0].CompanyID != first.ID {
+			t.Errorf("FindByStudent after delete = %+v, want only the first request", requests)
+		}
+	})
+}
+
+// helper func that creates an application submitted at the provided moment
+func newApplication(studentID, projectID string, createdAt time.Time) d.Application {
+	return d.Application{
+		ID:         newID(),
+		StudentID:  studentID,
+		ProjectID:  projectID,
+		Motivation: "motivation",
+		Resume:     "resume.pdf",
+		Status:     d.ApplicationSubmitted,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+}
+
+// helper func that creates a shortlist entry added at the provided moment
+func newShortlistEntry(companyID, studentID, addedBy string, createdAt time.Time) d.ShortlistEntry {
+	return d.ShortlistEntry{
+		ID:        newID(),
+		CompanyID: companyID,
+		StudentID: studentID,
+		Notes:     "notes",
+		Tags:      []string{"backend", "go"},
+		AddedBy:   addedBy,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+// helper func that checks if the found application is the stored one
+func assertApplication(t *testing.T, got, want d.Application) {
+	t.Helper()
+	if got.ID != want.ID || got.StudentID != want.StudentID || got.ProjectID != want.ProjectID ||
+		got.Motivation != want.Motivation || got.Resume != want.Resume || got.Status != want.Status ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("application = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that checks if the found shortlist entry is the stored one
+func assertShortlistEntry(t *testing.T, got, want d.ShortlistEntry) {
+	t.Helper()
+	if got.ID != want.ID || got.CompanyID != want.CompanyID || got.StudentID != want.StudentID ||
+		got.ProjectID != want.ProjectID || got.Notes != want.Notes || got.AddedBy != want.AddedBy ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) ||
+		!sameStrings(got.Tags, want.Tags) {
+		t.Errorf("shortlist entry = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that returns the ids of the applications
+func applicationIDs(applications []d.Application) []string {
+	ids := []string{}
+	for _, application := range applications {
+		ids = append(ids, application.ID)
+	}
+
+	return ids
+}
+
+// helper func that returns the ids of the shortlist entries
+func shortlistIDs(entries []d.ShortlistEntry) []string {
+	ids := []string{}
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,340 @@
+package contract
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Companies checks the contracts of the CompanyRepository and ProjectRepository
+func Companies(t *testing.T, newRepos Factory) {
+	t.Run("find created company", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+
+		found, err := r.Companies.FindByID(company.ID)
+		must(t, err)
+		assertCompany(t, found, company)
+		if ids := representativeIDs(found.Representatives); !sameSet(ids, []string{owner.ID}) {
+			t.Errorf("representatives = %v, want %v", ids, []string{owner.ID})
+		}
+
+		found, err = r.Companies.FindByName(company.Name)
+		must(t, err)
+		if found.ID != company.ID {
+			t.Errorf("FindByName = %q, want %q", found.ID, company.ID)
+		}
+
+		if _, err := r.Companies.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown company should fail")
+		}
+
+		if _, err := r.Companies.FindByName("unknown"); err == nil {
+			t.Error("FindByName of unknown company should fail")
+		}
+	})
+
+	t.Run("add project", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		managed := addProject(t, r, company.ID, owner.ID)
+		unmanaged := addProject(t, r, company.ID, "")
+
+		found, err := r.Projects.FindByID(managed.ID)
+		must(t, err)
+		assertProject(t, found, managed)
+
+		found, err = r.Projects.FindByID(unmanaged.ID)
+		must(t, err)
+		assertProject(t, found, unmanaged)
+
+		withProjects, err := r.Companies.FindByID(company.ID)
+		must(t, err)
+		if ids := projectIDs(withProjects.Projects); !sameSet(ids, []string{managed.ID, unmanaged.ID}) {
+			t.Errorf("projects of company = %v, want %v", ids, []string{managed.ID, unmanaged.ID})
+		}
+
+		if _, err := r.Projects.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown project should fail")
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+
+		company.Name = "new name"
+		company.Description = "new description"
+		company.Locations[0].Street = "padualaan"
+		project.Description = "another project"
+		project.Recommendations = []string{"java"}
+		company.Projects = []d.Project{project}
+		must(t, r.Companies.Update(company))
+
+		found, err := r.Companies.FindByID(company.ID)
+		must(t, err)
+		assertCompany(t, found, company)
+
+		updated, err := r.Projects.FindByID(project.ID)
+		must(t, err)
+		assertProject(t, updated, project)
+	})
+
+	t.Run("pages are ordered on id", func(t *testing.T) {
+		r := newRepos(t)
+		first, owner := createCompany(t, r)
+		second, _ := createCompany(t, r)
+		ids := []string{first.ID, second.ID}
+		sort.Strings(ids)
+
+		companies, next, err := r.Companies.FindAll(d.Page{Limit: 1})
+		must(t, err)
+		if len(companies) != 1 || companies[0].ID != ids[0] || next != ids[0] {
+			t.Errorf("first page = %v, %q, want [%v], %q", companies, next, ids[0], ids[0])
+		}
+
+		companies, next, err = r.Companies.FindAll(d.Page{Limit: 1, After: next})
+		must(t, err)
+		if len(companies) != 1 || companies[0].ID != ids[1] || next != "" {
+			t.Errorf("last page = %v, %q, want [%v], %q", companies, next, ids[1], "")
+		}
+
+		sorted := []string{
+			addProject(t, r, first.ID, owner.ID).ID,
+			addProject(t, r, first.ID, owner.ID).ID,
+		}
+		sort.Strings(sorted)
+
+		projects, next, err := r.Projects.FindAll(d.Page{Limit: 1, After: sorted[0]})
+		must(t, err)
+		if len(projects) != 1 || projects[0].ID != sorted[1] || next != "" {
+			t.Errorf("projects after %q = %v, %q, want [%v], %q", sorted[0], projects, next, sorted[1], "")
+		}
+	})
+
+	t.Run("delete project", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+
+		application := newApplication(student.ID, project.ID, now)
+		entry := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		entry.ProjectID = project.ID
+		must(t, r.Applications.Create(application))
+		must(t, r.Shortlist.Create(entry))
+		must(t, r.Projects.Delete(project.ID))
+
+		if _, err := r.Projects.FindByID(project.ID); err == nil {
+			t.Error("deleted project should not be found")
+		}
+
+		if _, err := r.Applications.FindByID(application.ID); err == nil {
+			t.Error("application to deleted project should not be found")
+		}
+
+		kept, err := r.Shortlist.FindByID(entry.ID)
+		must(t, err)
+		if kept.ProjectID != "" {
+			t.Errorf("ProjectID of shortlist entry = %q, want it cleared", kept.ProjectID)
+		}
+	})
+
+	t.Run("delete project of student shortlisted without it", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+
+		unlinked := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		entry := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		entry.ProjectID = project.ID
+		must(t, r.Shortlist.Create(unlinked))
+		must(t, r.Shortlist.Create(entry))
+		must(t, r.Projects.Delete(project.ID))
+
+		found, err := r.Shortlist.FindByCompany(company.ID)
+		must(t, err)
+		if ids := shortlistIDs(found); !sameStrings(ids, []string{unlinked.ID}) {
+			t.Errorf("FindByCompany = %v, want only %v", ids, []string{unlinked.ID})
+		}
+	})
+}
+
+// InviteLinks checks the contract of the InviteLinkRepository
+func InviteLinks(t *testing.T, newRepos Factory) {
+	t.Run("find created invitelink", func(t *testing.T) {
+		r := newRepos(t)
+		inviteLink := newInviteLink(newID(), newID(), now)
+		must(t, r.InviteLinks.Create(inviteLink))
+
+		found, err := r.InviteLinks.FindByID(inviteLink.ID)
+		must(t, err)
+		assertInviteLink(t, found, inviteLink)
+
+		if _, err := r.InviteLinks.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown invitelink should fail")
+		}
+	})
+
+	t.Run("find by creator newest first", func(t *testing.T) {
+		r := newRepos(t)
+		creatorID := newID()
+		older := newInviteLink(creatorID, newID(), now)
+		newer := newInviteLink(creatorID, newID(), now.Add(time.Hour))
+		must(t, r.InviteLinks.Create(older))
+		must(t, r.InviteLinks.Create(newer))
+		must(t, r.InviteLinks.Create(newInviteLink(newID(), newID(), now)))
+
+		found, err := r.InviteLinks.FindByCreator(creatorID)
+		must(t, err)
+		if ids := inviteLinkIDs(found); !sameStrings(ids, []string{newer.ID, older.ID}) {
+			t.Errorf("FindByCreator = %v, want %v", ids, []string{newer.ID, older.ID})
+		}
+	})
+
+	t.Run("redeem", func(t *testing.T) {
+		r := newRepos(t)
+		inviteLink := newInviteLink(newID(), newID(), now)
+		must(t, r.InviteLinks.Create(inviteLink))
+		must(t, r.InviteLinks.Redeem(inviteLink.ID, now))
+
+		found, err := r.InviteLinks.FindByID(inviteLink.ID)
+		must(t, err)
+		if found.Uses != 1 {
+			t.Errorf("Uses = %d, want 1", found.Uses)
+		}
+
+		if err := r.InviteLinks.Redeem(inviteLink.ID, now); err != e.ErrorInviteNotPending {
+			t.Errorf("Redeem of used invitelink = %v, want %v", err, e.ErrorInviteNotPending)
+		}
+	})
+
+	t.Run("redeem expired or revoked", func(t *testing.T) {
+		r := newRepos(t)
+		expired := newInviteLink(newID(), newID(), now)
+		revoked := newInviteLink(newID(), newID(), now)
+		must(t, r.InviteLinks.Create(expired))
+		must(t, r.InviteLinks.Create(revoked))
+		must(t, r.InviteLinks.Revoke(revoked.ID, now))
+
+		// the invitelink can't be used at the moment it expires
+		if err := r.InviteLinks.Redeem(expired.ID, expired.ExpiryDate); err != e.ErrorInviteNotPending {
+			t.Errorf("Redeem of expired invitelink = %v, want %v", err, e.ErrorInviteNotPending)
+		}
+
+		if err := r.InviteLinks.Redeem(revoked.ID, now); err != e.ErrorInviteNotPending {
+			t.Errorf("Redeem of revoked invitelink = %v, want %v", err, e.ErrorInviteNotPending)
+		}
+
+		if err := r.InviteLinks.Revoke(revoked.ID, now); err != e.ErrorInviteNotPending {
+			t.Errorf("Revoke of revoked invitelink = %v, want %v", err, e.ErrorInviteNotPending)
+		}
+	})
+
+	t.Run("create and renew with mail", func(t *testing.T) {
+		r := newRepos(t)
+		inviteLink := newInviteLink(newID(), newID(), now)
+		sent := newMail(inviteLink.Email, now)
+		must(t, r.InviteLinks.CreateWithMail(inviteLink, sent))
+
+		inviteLink.ExpiryDate = now.Add(48 * time.Hour)
+		resent := newMail(inviteLink.Email, now)
+		must(t, r.InviteLinks.RenewWithMail(inviteLink, resent))
+
+		found, err := r.InviteLinks.FindByID(inviteLink.ID)
+		must(t, err)
+		assertInviteLink(t, found, inviteLink)
+
+		mails, err := r.Outbox.ClaimDue(now, time.Minute, 10)
+		must(t, err)
+		if ids := mailIDs(mails); !sameSet(ids, []string{sent.ID, resent.ID}) {
+			t.Errorf("queued mails = %v, want %v", ids, []string{sent.ID, resent.ID})
+		}
+
+		must(t, r.InviteLinks.Redeem(inviteLink.ID, now))
+		if err := r.InviteLinks.RenewWithMail(inviteLink, newMail(inviteLink.Email, now)); err != e.ErrorInviteNotPending {
+			t.Errorf("RenewWithMail of used invitelink = %v, want %v", err, e.ErrorInviteNotPending)
+		}
+	})
+}
+
+// helper func that creates an invitelink for a single use, created at the provided moment
+func newInviteLink(creatorID, companyID string, createdAt time.Time) d.InviteLink {
+	id := newID()
+	return d.InviteLink{
+		ID:         id,
+		Kind:       d.RepresentativeInvite,
+		TargetID:   companyID,
+		Role:       d.RepresentativeRole,
+		URL:        "http://localhost/invite/" + id,
+		Email:      id + "@example.com",
+		CreatedAt:  createdAt,
+		ExpiryDate: createdAt.Add(d.InviteLinkValidFor),
+		CreatedBy:  creatorID,
+		MaxUses:    1,
+	}
+}
+
+// helper func that checks if the found company is the stored one,
+// leaving out its representatives and projects
+func assertCompany(t *testing.T, got, want d.Company) {
+	t.Helper()
+	if got.ID != want.ID || got.Name != want.Name || got.Information != want.Information ||
+		got.Description != want.Description || len(got.Locations) != len(want.Locations) {
+		t.Errorf("company = %+v, want %+v", got, want)
+		return
+	}
+
+	for i := range want.Locations {
+		if got.Locations[i] != want.Locations[i] {
+			t.Errorf("location = %+v, want %+v", got.Locations[i], want.Locations[i])
+		}
+	}
+}
+
+// helper func that checks if the found project is the stored one
+func assertProject(t *testing.T, got, want d.Project) {
+	t.Helper()
+	if got.ID != want.ID || got.Description != want.Description || got.Compensation != want.Compensation ||
+		got.Duration != want.Duration || got.CompanyID != want.CompanyID || got.ContactID != want.ContactID ||
+		!sameStrings(got.Recommendations, want.Recommendations) {
+		t.Errorf("project = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that checks if the found invitelink is the stored one
+func assertInviteLink(t *testing.T, got, want d.InviteLink) {
+	t.Helper()
+	if got.ID != want.ID || got.Kind != want.Kind || got.TargetID != want.TargetID ||
+		got.Role != want.Role || got.URL != want.URL || got.Email != want.Email ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.ExpiryDate.Equal(want.ExpiryDate) ||
+		got.CreatedBy != want.CreatedBy || !got.RevokedAt.Equal(want.RevokedAt) ||
+		got.MaxUses != want.MaxUses || got.Uses != want.Uses {
+		t.Errorf("invitelink = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that returns the ids of the projects
+func projectIDs(projects []d.Project) []string {
+	ids := []string{}
+	for _, project := range projects {
+		ids = append(ids, project.ID)
+	}
+
+	return ids
+}
+
+// helper func that returns the ids of the invitelinks
+func inviteLinkIDs(inviteLinks []d.InviteLink) []string {
+	ids := []string{}
+	for _, inviteLink := range inviteLinks {
+		ids = append(ids, inviteLink.ID)
+	}
+
+	return ids
+}
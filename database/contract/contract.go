@@ -0,0 +1,210 @@
+// Package contract contains the behaviour every storage backend has to show.
+// The contracts are written against the repository interfaces of the domain,
+// so the same checks run against postgres and the in-memory backend.
+package contract
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// Repos holds the repositories of a single storage backend, which all
+// have to share the same data, together with the unit of work spanning them
+type Repos struct {
+	Users           d.UserRepository
+	Students        d.StudentRepository
+	Representatives d.RepresentativeRepository
+	Companies       d.CompanyRepository
+	Projects        d.ProjectRepository
+	InviteLinks     d.InviteLinkRepository
+	Conversations   d.ConversationRepository
+	Messages        d.MessageRepository
+	Blocks          d.BlockRepository
+	Applications    d.ApplicationRepository
+	Shortlist       d.ShortlistRepository
+	ResumeRequests  d.ResumeRequestRepository
+	Sessions        d.SessionRepository
+	UserTokens      d.UserTokenRepository
+	Outbox          d.OutboxRepository
+	UnitOfWork      d.UnitOfWork
+}
+
+// Factory creates the repositories of a backend on top of empty storage.
+// It is called for every check, so checks never see each other's data.
+type Factory func(t *testing.T) Repos
+
+// Run checks all contracts against the repositories the factory creates
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("users", func(t *testing.T) { Users(t, newRepos) })
+	t.Run("students", func(t *testing.T) { Students(t, newRepos) })
+	t.Run("representatives", func(t *testing.T) { Representatives(t, newRepos) })
+	t.Run("companies", func(t *testing.T) { Companies(t, newRepos) })
+	t.Run("invitelinks", func(t *testing.T) { InviteLinks(t, newRepos) })
+	t.Run("applications", func(t *testing.T) { Applications(t, newRepos) })
+	t.Run("shortlist", func(t *testing.T) { Shortlist(t, newRepos) })
+	t.Run("blocks", func(t *testing.T) { Blocks(t, newRepos) })
+	t.Run("resume requests", func(t *testing.T) { ResumeRequests(t, newRepos) })
+	t.Run("messaging", func(t *testing.T) { Messaging(t, newRepos) })
+	t.Run("sessions", func(t *testing.T) { Sessions(t, newRepos) })
+	t.Run("user tokens", func(t *testing.T) { UserTokens(t, newRepos) })
+	t.Run("outbox", func(t *testing.T) { Outbox(t, newRepos) })
+	t.Run("unit of work", func(t *testing.T) { UnitOfWork(t, newRepos) })
+	t.Run("not found", func(t *testing.T) { NotFound(t, newRepos) })
+}
+
+// now is the moment the contracts take place. It is in UTC and has
+// no fraction of a second, so it survives a TIMESTAMP column unchanged.
+var now = time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// helper func that creates a new id, which is a valid postgres UUID
+func newID() string {
+	return uuid.New().String()
+}
+
+// helper func that creates a user with a unique email
+func newUser(role string) d.User {
+	id := newID()
+	return d.User{
+		ID:             id,
+		Email:          id + "@example.com",
+		HashedPassword: "hash",
+		FirstName:      "jan",
+		LastName:       "jansen",
+		Role:           role,
+	}
+}
+
+// helper func that creates a student with all fields filled in
+func newStudent() d.Student {
+	return d.Student{
+		ID:               newID(),
+		University:       "hogeschool utrecht",
+		City:             "utrecht",
+		Skills:           []string{"go", "sql"},
+		Experiences:      []string{"backend developer"},
+		ShortExperiences: []string{"backend"},
+		Wishes:           "an internship",
+		Status:           d.Available,
+		Resume:           "resume.pdf",
+		Visibility:       d.VisibleToRepresentatives,
+		User:             newUser(d.StudentRole),
+	}
+}
+
+// helper func that creates a representative working for the company
+func newRepresentative(companyID string, role d.CompanyRole) d.Representative {
+	return d.Representative{
+		ID:          newID(),
+		JobTitle:    "recruiter",
+		CompanyID:   companyID,
+		CompanyRole: role,
+		User:        newUser(d.RepresentativeRole),
+	}
+}
+
+// helper func that stores a new student
+func createStudent(t *testing.T, r Repos) d.Student {
+	t.Helper()
+	student := newStudent()
+	must(t, r.Students.Create(student))
+	return student
+}
+
+// helper func that stores a new company together with its owner
+func createCompany(t *testing.T, r Repos) (d.Company, d.Representative) {
+	t.Helper()
+	id := newID()
+	owner := newRepresentative(id, d.CompanyOwner)
+	company := d.Company{
+		ID:          id,
+		Name:        "company " + id,
+		Information: "information",
+		Description: "description",
+		Locations: []d.Address{{
+			ID:      newID(),
+			Street:  "heidelberglaan",
+			Zipcode: "3584 CS",
+			City:    "utrecht",
+			Number:  "15",
+		}},
+		Representatives: []d.Representative{owner},
+	}
+
+	must(t, r.Companies.Create(company))
+	return company, owner
+}
+
+// helper func that stores a new representative of the company
+func createRepresentative(t *testing.T, r Repos, companyID string, role d.CompanyRole) d.Representative {
+	t.Helper()
+	repr := newRepresentative(companyID, role)
+	must(t, r.Representatives.Create(repr))
+	return repr
+}
+
+// helper func that adds a new project to the company
+func addProject(t *testing.T, r Repos, companyID, contactID string) d.Project {
+	t.Helper()
+	project := d.Project{
+		ID:              newID(),
+		Description:     "a project",
+		Compensation:    "500 euro",
+		Duration:        "5 months",
+		Recommendations: []string{"go", "docker"},
+		CompanyID:       companyID,
+		ContactID:       contactID,
+	}
+
+	must(t, r.Companies.AddProject(project))
+	return project
+}
+
+// helper func that creates a mail that is due at the provided moment
+func newMail(to string, due time.Time) d.OutboxMail {
+	return d.OutboxMail{
+		ID:            newID(),
+		Event:         d.InviteMail,
+		To:            to,
+		Data:          map[string]string{"url": "http://localhost/invite"},
+		CreatedAt:     due,
+		NextAttemptAt: due,
+	}
+}
+
+// helper func that stops the check when an unexpected error occurs
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// helper func that checks if both slices contain the same strings in the
+// same order. A nil slice equals an empty one, as postgres can't tell them apart.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// helper func that checks if both slices contain the same strings in any order
+func sameSet(a, b []string) bool {
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return sameStrings(a, b)
+}
@@ -0,0 +1,239 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// Messaging checks the contracts of the ConversationRepository and MessageRepository
+func Messaging(t *testing.T, newRepos Factory) {
+	t.Run("find created conversation", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+
+		conversation := newConversation(owner.User.ID, student.User.ID, project.ID)
+		conversation.ReadAt = map[string]time.Time{owner.User.ID: now}
+		must(t, r.Conversations.Create(conversation))
+
+		found, err := r.Conversations.FindByID(conversation.ID)
+		must(t, err)
+		assertConversation(t, found, conversation)
+
+		found, err = r.Conversations.FindBetween(student.User.ID, owner.User.ID, project.ID)
+		must(t, err)
+		assertConversation(t, found, conversation)
+
+		if _, err := r.Conversations.FindBetween(student.User.ID, owner.User.ID, ""); err == nil {
+			t.Error("FindBetween without project should not find the conversation about the project")
+		}
+
+		if _, err := r.Conversations.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown conversation should fail")
+		}
+	})
+
+	t.Run("messages oldest first", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		student := createStudent(t, r)
+		conversation := newConversation(student.User.ID, owner.User.ID, "")
+		must(t, r.Conversations.Create(conversation))
+
+		first := newMessage(conversation.ID, student.User.ID, owner.User.ID, now.Add(time.Minute))
+		second := newMessage(conversation.ID, owner.User.ID, student.User.ID, now.Add(2*time.Minute))
+		must(t, r.Messages.Create(first))
+		must(t, r.Messages.Create(second))
+
+		found, err := r.Messages.FindByID(first.ID)
+		must(t, err)
+		assertMessage(t, found, first)
+
+		messages, err := r.Messages.FindByConversation(conversation.ID)
+		must(t, err)
+		if ids := messageIDs(messages); !sameStrings(ids, []string{first.ID, second.ID}) {
+			t.Errorf("FindByConversation = %v, want %v", ids, []string{first.ID, second.ID})
+		}
+
+		active, err := r.Conversations.FindByID(conversation.ID)
+		must(t, err)
+		if !active.LastActivity.Equal(second.CreatedAt) {
+			t.Errorf("LastActivity = %v, want %v", active.LastActivity, second.CreatedAt)
+		}
+
+		if _, err := r.Messages.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown message should fail")
+		}
+	})
+
+	t.Run("conversations of participant", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		student := createStudent(t, r)
+		other := createStudent(t, r)
+
+		older := newConversation(student.User.ID, owner.User.ID, "")
+		newer := newConversation(other.User.ID, owner.User.ID, "")
+		must(t, r.Conversations.Create(older))
+		must(t, r.Conversations.Create(newer))
+		must(t, r.Messages.Create(newMessage(older.ID, student.User.ID, owner.User.ID, now.Add(time.Minute))))
+		must(t, r.Messages.Create(newMessage(older.ID, student.User.ID, owner.User.ID, now.Add(2*time.Minute))))
+		must(t, r.Messages.Create(newMessage(newer.ID, other.User.ID, owner.User.ID, now.Add(3*time.Minute))))
+
+		assertUnread(t, r, owner.User.ID, []string{newer.ID, older.ID}, []int{1, 2})
+
+		// messages sent at the moment the conversation was read have been read,
+		// and the messages the participant sent themselves are never unread
+		must(t, r.Conversations.MarkRead(older.ID, owner.User.ID, now.Add(time.Minute)))
+		must(t, r.Messages.Create(newMessage(older.ID, owner.User.ID, student.User.ID, now.Add(4*time.Minute))))
+		assertUnread(t, r, owner.User.ID, []string{older.ID, newer.ID}, []int{1, 1})
+		assertUnread(t, r, student.User.ID, []string{older.ID}, []int{1})
+	})
+
+	t.Run("count started conversations", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		student := createStudent(t, r)
+		other := createStudent(t, r)
+
+		earlier := newConversation(owner.User.ID, student.User.ID, "")
+		later := newConversation(owner.User.ID, other.User.ID, "")
+		later.CreatedAt = now.Add(time.Hour)
+		must(t, r.Conversations.Create(earlier))
+		must(t, r.Conversations.Create(later))
+		must(t, r.Conversations.Create(newConversation(student.User.ID, other.User.ID, "")))
+
+		count, err := r.Conversations.CountStartedBy([]string{owner.User.ID}, now)
+		must(t, err)
+		if count != 2 {
+			t.Errorf("CountStartedBy since %v = %d, want 2", now, count)
+		}
+
+		count, err = r.Conversations.CountStartedBy([]string{owner.User.ID}, later.CreatedAt)
+		must(t, err)
+		if count != 1 {
+			t.Errorf("CountStartedBy since %v = %d, want 1", later.CreatedAt, count)
+		}
+	})
+
+	t.Run("create with mail", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		student := createStudent(t, r)
+		conversation := newConversation(owner.User.ID, student.User.ID, "")
+		must(t, r.Conversations.Create(conversation))
+
+		message := newMessage(conversation.ID, owner.User.ID, student.User.ID, now)
+		mail := newMail(student.User.Email, now)
+		must(t, r.Messages.CreateWithMail(message, mail))
+
+		if _, err := r.Messages.FindByID(message.ID); err != nil {
+			t.Errorf("FindByID of created message = %v", err)
+		}
+
+		mails, err := r.Outbox.ClaimDue(now, time.Minute, 10)
+		must(t, err)
+		if ids := mailIDs(mails); !sameSet(ids, []string{mail.ID}) {
+			t.Errorf("queued mails = %v, want %v", ids, []string{mail.ID})
+		}
+	})
+}
+
+// helper func that creates a conversation between two users, started by the first
+func newConversation(userID, otherUserID, projectID string) d.Conversation {
+	return d.Conversation{
+		ID:           newID(),
+		Participants: []string{userID, otherUserID},
+		ProjectID:    projectID,
+		StartedBy:    userID,
+		CreatedAt:    now,
+		LastActivity: now,
+	}
+}
+
+// helper func that creates a message sent at the provided moment
+func newMessage(conversationID, sender, receiver string, createdAt time.Time) d.Message {
+	return d.Message{
+		ID:             newID(),
+		ConversationID: conversationID,
+		Sender:         sender,
+		Receiver:       receiver,
+		Body:           "hello",
+		CreatedAt:      createdAt,
+	}
+}
+
+// helper func that checks if the found conversation is the stored one
+func assertConversation(t *testing.T, got, want d.Conversation) {
+	t.Helper()
+	if got.ID != want.ID || got.ProjectID != want.ProjectID || got.StartedBy != want.StartedBy ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.LastActivity.Equal(want.LastActivity) ||
+		!sameSet(got.Participants, want.Participants) || len(got.ReadAt) != len(want.ReadAt) {
+		t.Errorf("conversation = %+v, want %+v", got, want)
+		return
+	}
+
+	for userID, at := range want.ReadAt {
+		if !got.ReadAt[userID].Equal(at) {
+			t.Errorf("ReadAt of %s = %v, want %v", userID, got.ReadAt[userID], at)
+		}
+	}
+}
+
+// helper func that checks if the found message is the stored one
+func assertMessage(t *testing.T, got, want d.Message) {
+	t.Helper()
+	if got.ID != want.ID || got.ConversationID != want.ConversationID || got.Sender != want.Sender ||
+		got.Receiver != want.Receiver || got.Body != want.Body || got.ProjectID != want.ProjectID ||
+		!got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("message = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that checks the conversations of the user, from most to
+// least recent activity, and how many of their messages are unread
+func assertUnread(t *testing.T, r Repos, userID string, ids []string, unread []int) {
+	t.Helper()
+	conversations, err := r.Conversations.FindByParticipant(userID)
+	must(t, err)
+
+	got := []int{}
+	for _, conversation := range conversations {
+		got = append(got, conversation.Unread)
+	}
+
+	if !sameStrings(conversationIDs(conversations), ids) || len(got) != len(unread) {
+		t.Errorf("FindByParticipant = %v, want %v", conversationIDs(conversations), ids)
+		return
+	}
+
+	for i := range unread {
+		if got[i] != unread[i] {
+			t.Errorf("unread counts = %v, want %v", got, unread)
+			return
+		}
+	}
+}
+
+// helper func that returns the ids of the conversations
+func conversationIDs(conversations []d.Conversation) []string {
+	ids := []string{}
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+	}
+
+	return ids
+}
+
+// helper func that returns the ids of the messages
+func messageIDs(messages []d.Message) []string {
+	ids := []string{}
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+
+	return ids
+}
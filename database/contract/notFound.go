@@ -0,0 +1,38 @@
+package contract
+
+import (
+	"testing"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// NotFound checks that all repositories report entities
+// that don't exist as e.ErrorEntityNotFound
+func NotFound(t *testing.T, newRepos Factory) {
+	r := newRepos(t)
+	finds := map[string]func(id string) error{
+		"user":            func(id string) error { _, err := r.Users.FindByID(id); return err },
+		"user by email":   func(id string) error { _, err := r.Users.FindByEmail(id + "@example.com"); return err },
+		"student":         func(id string) error { _, err := r.Students.FindByID(id); return err },
+		"representative":  func(id string) error { _, err := r.Representatives.FindByID(id); return err },
+		"company":         func(id string) error { _, err := r.Companies.FindByID(id); return err },
+		"company by name": func(id string) error { _, err := r.Companies.FindByName(id); return err },
+		"project":         func(id string) error { _, err := r.Projects.FindByID(id); return err },
+		"invitelink":      func(id string) error { _, err := r.InviteLinks.FindByID(id); return err },
+		"conversation":    func(id string) error { _, err := r.Conversations.FindByID(id); return err },
+		"message":         func(id string) error { _, err := r.Messages.FindByID(id); return err },
+		"application":     func(id string) error { _, err := r.Applications.FindByID(id); return err },
+		"shortlist entry": func(id string) error { _, err := r.Shortlist.FindByID(id); return err },
+		"session":         func(id string) error { _, err := r.Sessions.FindByTokenHash(id); return err },
+		"user token":      func(id string) error { _, err := r.UserTokens.FindByHash(id); return err },
+	}
+
+	// ids that aren't valid uuids can't belong to anything either
+	for _, id := range []string{newID(), "not-a-uuid"} {
+		for name, find := range finds {
+			if err := find(id); !e.Is(err, e.ErrorEntityNotFound) {
+				t.Errorf("finding %s %q = %v, want %v", name, id, err, e.ErrorEntityNotFound)
+			}
+		}
+	}
+}
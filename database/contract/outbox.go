@@ -0,0 +1,92 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// Outbox checks the contract of the OutboxRepository
+func Outbox(t *testing.T, newRepos Factory) {
+	t.Run("claim due mails", func(t *testing.T) {
+		r := newRepos(t)
+		early := newMail("early@example.com", now.Add(-time.Hour))
+		due := newMail("due@example.com", now)
+		later := newMail("later@example.com", now.Add(time.Hour))
+		dead := newMail("dead@example.com", now.Add(-time.Hour))
+		dead.Attempts = d.MaxMailAttempts
+		sent := newMail("sent@example.com", now.Add(-time.Hour))
+		for _, mail := range []d.OutboxMail{early, due, later, dead, sent} {
+			must(t, r.Outbox.Enqueue(mail))
+		}
+		must(t, r.Outbox.MarkSent(sent.ID, now))
+
+		mails, err := r.Outbox.ClaimDue(now, time.Minute, 10)
+		must(t, err)
+		if ids := mailIDs(mails); !sameSet(ids, []string{early.ID, due.ID}) {
+			t.Fatalf("ClaimDue = %v, want %v", ids, []string{early.ID, due.ID})
+		}
+
+		for _, mail := range mails {
+			if !mail.NextAttemptAt.Equal(now.Add(time.Minute)) || mail.Data["url"] != early.Data["url"] {
+				t.Errorf("claimed mail = %+v, want it leased until %v", mail, now.Add(time.Minute))
+			}
+		}
+
+		// claimed mails are leased, so they can't be claimed again meanwhile
+		mails, err = r.Outbox.ClaimDue(now, time.Minute, 10)
+		must(t, err)
+		if len(mails) != 0 {
+			t.Errorf("ClaimDue during lease = %v, want none", mailIDs(mails))
+		}
+	})
+
+	t.Run("claim earliest first", func(t *testing.T) {
+		r := newRepos(t)
+		early := newMail("early@example.com", now.Add(-time.Hour))
+		due := newMail("due@example.com", now)
+		must(t, r.Outbox.Enqueue(due))
+		must(t, r.Outbox.Enqueue(early))
+
+		mails, err := r.Outbox.ClaimDue(now, time.Minute, 1)
+		must(t, err)
+		if ids := mailIDs(mails); !sameStrings(ids, []string{early.ID}) {
+			t.Errorf("ClaimDue = %v, want %v", ids, []string{early.ID})
+		}
+	})
+
+	t.Run("mark failed", func(t *testing.T) {
+		r := newRepos(t)
+		mail := newMail("student@example.com", now)
+		must(t, r.Outbox.Enqueue(mail))
+
+		mail.Attempts = 1
+		mail.LastError = "connection refused"
+		mail.NextAttemptAt = now.Add(5 * time.Minute)
+		must(t, r.Outbox.MarkFailed(mail))
+
+		mails, err := r.Outbox.ClaimDue(now, time.Minute, 10)
+		must(t, err)
+		if len(mails) != 0 {
+			t.Errorf("ClaimDue before next attempt = %v, want none", mailIDs(mails))
+		}
+
+		mails, err = r.Outbox.ClaimDue(mail.NextAttemptAt, time.Minute, 10)
+		must(t, err)
+		if len(mails) != 1 || mails[0].Attempts != 1 || mails[0].LastError != mail.LastError ||
+			mails[0].To != mail.To || mails[0].Event != mail.Event {
+			t.Errorf("ClaimDue after next attempt = %+v, want the failed mail", mails)
+		}
+	})
+}
+
+// helper func that returns the ids of the mails
+func mailIDs(mails []d.OutboxMail) []string {
+	ids := []string{}
+	for _, mail := range mails {
+		ids = append(ids, mail.ID)
+	}
+
+	return ids
+}
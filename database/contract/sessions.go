@@ -0,0 +1,163 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Sessions checks the contract of the SessionRepository
+func Sessions(t *testing.T, newRepos Factory) {
+	t.Run("rotate", func(t *testing.T) {
+		r := newRepos(t)
+		user := newUser(d.StudentRole)
+		must(t, r.Users.Create(user))
+
+		session := newSession(user.ID, now)
+		must(t, r.Sessions.Create(session))
+
+		found, err := r.Sessions.FindByTokenHash(session.TokenHash)
+		must(t, err)
+		assertSession(t, found, session)
+
+		replacement := newSession(user.ID, now.Add(time.Minute))
+		replacement.FamilyID = session.FamilyID
+		must(t, r.Sessions.Rotate(session, replacement))
+
+		found, err = r.Sessions.FindByTokenHash(session.TokenHash)
+		must(t, err)
+		session.UsedAt = replacement.CreatedAt
+		assertSession(t, found, session)
+
+		found, err = r.Sessions.FindByTokenHash(replacement.TokenHash)
+		must(t, err)
+		assertSession(t, found, replacement)
+
+		reused := newSession(user.ID, now.Add(2*time.Minute))
+		reused.FamilyID = session.FamilyID
+		if err := r.Sessions.Rotate(session, reused); err != e.ErrorTokenReused {
+			t.Errorf("Rotate of used session = %v, want %v", err, e.ErrorTokenReused)
+		}
+
+		if _, err := r.Sessions.FindByTokenHash(reused.TokenHash); err == nil {
+			t.Error("replacement of used session should not be stored")
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		r := newRepos(t)
+		user := newUser(d.StudentRole)
+		other := newUser(d.StudentRole)
+		must(t, r.Users.Create(user))
+		must(t, r.Users.Create(other))
+
+		first := newSession(user.ID, now)
+		second := newSession(user.ID, now)
+		untouched := newSession(other.ID, now)
+		expired := newSession(other.ID, now)
+		expired.ExpiresAt = time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+		for _, session := range []d.Session{first, second, untouched, expired} {
+			must(t, r.Sessions.Create(session))
+		}
+
+		assertFamilyActive(t, r, first.FamilyID, true)
+		assertFamilyActive(t, r, expired.FamilyID, false)
+
+		must(t, r.Sessions.RevokeFamily(first.FamilyID, now))
+		assertFamilyActive(t, r, first.FamilyID, false)
+		assertFamilyActive(t, r, second.FamilyID, true)
+
+		found, err := r.Sessions.FindByTokenHash(first.TokenHash)
+		must(t, err)
+		if !found.RevokedAt.Equal(now) {
+			t.Errorf("RevokedAt = %v, want %v", found.RevokedAt, now)
+		}
+
+		must(t, r.Sessions.RevokeByUser(user.ID, now))
+		assertFamilyActive(t, r, second.FamilyID, false)
+		assertFamilyActive(t, r, untouched.FamilyID, true)
+	})
+}
+
+// UserTokens checks the contract of the UserTokenRepository
+func UserTokens(t *testing.T, newRepos Factory) {
+	t.Run("use once", func(t *testing.T) {
+		r := newRepos(t)
+		user := newUser(d.StudentRole)
+		must(t, r.Users.Create(user))
+
+		token := d.UserToken{
+			ID:         newID(),
+			UserID:     user.ID,
+			Purpose:    d.EmailVerification,
+			TokenHash:  newID(),
+			CreatedAt:  now,
+			ExpiryDate: now.Add(time.Hour),
+		}
+		must(t, r.UserTokens.Create(token))
+
+		found, err := r.UserTokens.FindByHash(token.TokenHash)
+		must(t, err)
+		assertUserToken(t, found, token)
+
+		must(t, r.UserTokens.MarkUsed(token.ID))
+		found, err = r.UserTokens.FindByHash(token.TokenHash)
+		must(t, err)
+		token.Used = true
+		assertUserToken(t, found, token)
+
+		if err := r.UserTokens.MarkUsed(token.ID); err != e.ErrorInvalidToken {
+			t.Errorf("MarkUsed of used token = %v, want %v", err, e.ErrorInvalidToken)
+		}
+
+		if _, err := r.UserTokens.FindByHash(newID()); err == nil {
+			t.Error("FindByHash of unknown token should fail")
+		}
+	})
+}
+
+// helper func that creates a session of a new family. It expires long after
+// the actual time, as backends check if a family is active against their clock.
+func newSession(userID string, createdAt time.Time) d.Session {
+	return d.Session{
+		ID:        newID(),
+		FamilyID:  newID(),
+		UserID:    userID,
+		TokenHash: newID(),
+		CreatedAt: createdAt,
+		ExpiresAt: time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second),
+	}
+}
+
+// helper func that checks if the found session is the stored one
+func assertSession(t *testing.T, got, want d.Session) {
+	t.Helper()
+	if got.ID != want.ID || got.FamilyID != want.FamilyID || got.UserID != want.UserID ||
+		got.TokenHash != want.TokenHash || !got.CreatedAt.Equal(want.CreatedAt) ||
+		!got.ExpiresAt.Equal(want.ExpiresAt) || !got.UsedAt.Equal(want.UsedAt) ||
+		!got.RevokedAt.Equal(want.RevokedAt) {
+		t.Errorf("session = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that checks if the session family is active
+func assertFamilyActive(t *testing.T, r Repos, familyID string, want bool) {
+	t.Helper()
+	active, err := r.Sessions.IsFamilyActive(familyID)
+	must(t, err)
+	if active != want {
+		t.Errorf("IsFamilyActive = %v, want %v", active, want)
+	}
+}
+
+// helper func that checks if the found token is the stored one
+func assertUserToken(t *testing.T, got, want d.UserToken) {
+	t.Helper()
+	if got.ID != want.ID || got.UserID != want.UserID || got.Purpose != want.Purpose ||
+		got.TokenHash != want.TokenHash || !got.CreatedAt.Equal(want.CreatedAt) ||
+		!got.ExpiryDate.Equal(want.ExpiryDate) || got.Used != want.Used {
+		t.Errorf("user token = %+v, want %+v", got, want)
+	}
+}
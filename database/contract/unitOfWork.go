@@ -0,0 +1,148 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// errFailed is returned by units of work that have to be rolled back
+var errFailed = errors.New("the unit of work failed")
+
+// UnitOfWork checks the contract of the UnitOfWork
+func UnitOfWork(t *testing.T, newRepos Factory) {
+	t.Run("commit", func(t *testing.T) {
+		r := newRepos(t)
+		student := newStudent()
+		mail := newMail(student.User.Email, now)
+		err := r.UnitOfWork.Do(func(repos d.Repositories) error {
+			if err := repos.Students.Create(student); err != nil {
+				return err
+			}
+
+			// changes are seen by the unit of work itself before they are committed
+			if _, err := repos.Students.FindByID(student.ID); err != nil {
+				return err
+			}
+
+			return repos.Outbox.Enqueue(mail)
+		})
+		must(t, err)
+
+		found, err := r.Students.FindByID(student.ID)
+		must(t, err)
+		assertStudent(t, found, student)
+
+		mails, err := r.Outbox.ClaimDue(now, 0, 10)
+		must(t, err)
+		if ids := mailIDs(mails); !sameStrings(ids, []string{mail.ID}) {
+			t.Errorf("ClaimDue = %v, want %v", ids, []string{mail.ID})
+		}
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		r := newRepos(t)
+		student := newStudent()
+		err := r.UnitOfWork.Do(func(repos d.Repositories) error {
+			if err := repos.Students.Create(student); err != nil {
+				return err
+			}
+
+			return errFailed
+		})
+		if err != errFailed {
+			t.Fatalf("Do = %v, want %v", err, errFailed)
+		}
+
+		if _, err := r.Students.FindByID(student.ID); err == nil {
+			t.Error("student of a rolled back unit of work should not exist")
+		}
+
+		if _, err := r.Users.FindByEmail(student.User.Email); err == nil {
+			t.Error("user of a rolled back unit of work should not exist")
+		}
+	})
+
+	t.Run("rollback after failed repository", func(t *testing.T) {
+		r := newRepos(t)
+		existing := createStudent(t, r)
+		student := newStudent()
+		duplicate := newStudent()
+		duplicate.User.Email = existing.User.Email
+		err := r.UnitOfWork.Do(func(repos d.Repositories) error {
+			if err := repos.Students.Create(student); err != nil {
+				return err
+			}
+
+			return repos.Students.Create(duplicate)
+		})
+		if err == nil {
+			t.Fatal("unit of work creating a user with a used email should fail")
+		}
+
+		if _, err := r.Students.FindByID(student.ID); err == nil {
+			t.Error("student created before the failure should not exist")
+		}
+	})
+
+	t.Run("rollback of deleted student", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		student := createStudent(t, r)
+		conversation := newConversation(student.User.ID, owner.User.ID, "")
+		must(t, r.Conversations.Create(conversation))
+		must(t, r.Conversations.MarkRead(conversation.ID, student.User.ID, now))
+
+		err := r.UnitOfWork.Do(func(repos d.Repositories) error {
+			if err := repos.Students.Delete(student); err != nil {
+				return err
+			}
+
+			return errFailed
+		})
+		if err != errFailed {
+			t.Fatalf("Do = %v, want %v", err, errFailed)
+		}
+
+		found, err := r.Conversations.FindByID(conversation.ID)
+		must(t, err)
+		if _, ok := found.ReadAt[student.User.ID]; !ok || !sameSet(found.Participants, conversation.Participants) {
+			t.Errorf("conversation = %+v, want it untouched by the rolled back deletion", found)
+		}
+	})
+
+	t.Run("signup by invitation", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		inviteLink := newInviteLink(owner.ID, company.ID, now)
+		must(t, r.InviteLinks.Create(inviteLink))
+
+		signup := func(repr d.Representative) error {
+			return r.UnitOfWork.Do(func(repos d.Repositories) error {
+				if err := repos.Representatives.Create(repr); err != nil {
+					return err
+				}
+
+				return repos.InviteLinks.Redeem(inviteLink.ID, now)
+			})
+		}
+
+		first := newRepresentative(company.ID, d.CompanyRecruiter)
+		must(t, signup(first))
+
+		second := newRepresentative(company.ID, d.CompanyRecruiter)
+		if err := signup(second); err != e.ErrorInviteNotPending {
+			t.Fatalf("second signup = %v, want %v", err, e.ErrorInviteNotPending)
+		}
+
+		if _, err := r.Representatives.FindByID(first.ID); err != nil {
+			t.Errorf("representative that redeemed the invitation should exist: %v", err)
+		}
+
+		if _, err := r.Representatives.FindByID(second.ID); err == nil {
+			t.Error("representative without a usable invitation should not exist")
+		}
+	})
+}
@@ -0,0 +1,507 @@
+package contract
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Users checks the contract of the UserRepository
+func Users(t *testing.T, newRepos Factory) {
+	t.Run("find created user", func(t *testing.T) {
+		r := newRepos(t)
+		user := newUser(d.StudentRole)
+		must(t, r.Users.Create(user))
+
+		found, err := r.Users.FindByID(user.ID)
+		must(t, err)
+		if found != user {
+			t.Errorf("FindByID = %+v, want %+v", found, user)
+		}
+
+		found, err = r.Users.FindByEmail(user.Email)
+		must(t, err)
+		if found != user {
+			t.Errorf("FindByEmail = %+v, want %+v", found, user)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		r := newRepos(t)
+		if _, err := r.Users.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown user should fail")
+		}
+
+		if _, err := r.Users.FindByEmail("nobody@example.com"); err == nil {
+			t.Error("FindByEmail of unknown user should fail")
+		}
+	})
+
+	t.Run("email is unique", func(t *testing.T) {
+		r := newRepos(t)
+		user := newUser(d.StudentRole)
+		must(t, r.Users.Create(user))
+
+		other := newUser(d.StudentRole)
+		other.Email = user.Email
+		if err := r.Users.Create(other); err != e.ErrorEmailAlreadyUsed {
+			t.Errorf("Create with used email = %v, want %v", err, e.ErrorEmailAlreadyUsed)
+		}
+	})
+
+	t.Run("update password and verify email", func(t *testing.T) {
+		r := newRepos(t)
+		user := newUser(d.StudentRole)
+		must(t, r.Users.Create(user))
+		must(t, r.Users.UpdatePassword(user.ID, "new hash"))
+		must(t, r.Users.MarkEmailVerified(user.ID))
+
+		found, err := r.Users.FindByID(user.ID)
+		must(t, err)
+		if found.HashedPassword != "new hash" || !found.EmailVerified {
+			t.Errorf("FindByID = %+v, want new hash and verified email", found)
+		}
+	})
+
+	t.Run("role id", func(t *testing.T) {
+		r := newRepos(t)
+		student := createStudent(t, r)
+		_, owner := createCompany(t, r)
+		repr := createRepresentative(t, r, owner.CompanyID, d.CompanyRecruiter)
+
+		id, err := r.Users.FindRoleID(student.User)
+		must(t, err)
+		if id != student.ID {
+			t.Errorf("FindRoleID of student = %q, want %q", id, student.ID)
+		}
+
+		id, err = r.Users.FindRoleID(repr.User)
+		must(t, err)
+		if id != repr.ID {
+			t.Errorf("FindRoleID of representative = %q, want %q", id, repr.ID)
+		}
+
+		must(t, r.Representatives.Remove(repr.ID, owner.ID, now))
+		if _, err := r.Users.FindRoleID(repr.User); err == nil {
+			t.Error("FindRoleID of removed representative should fail")
+		}
+	})
+}
+
+// Students checks the contract of the StudentRepository
+func Students(t *testing.T, newRepos Factory) {
+	t.Run("find created student", func(t *testing.T) {
+		r := newRepos(t)
+		student := createStudent(t, r)
+
+		found, err := r.Students.FindByID(student.ID)
+		must(t, err)
+		assertStudent(t, found, student)
+
+		if _, err := r.Students.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown student should fail")
+		}
+	})
+
+	t.Run("found students are copies", func(t *testing.T) {
+		r := newRepos(t)
+		student := createStudent(t, r)
+
+		found, err := r.Students.FindByID(student.ID)
+		must(t, err)
+		found.Skills[0] = "changed"
+
+		found, err = r.Students.FindByID(student.ID)
+		must(t, err)
+		assertStudent(t, found, student)
+	})
+
+	t.Run("update keeps privacy settings", func(t *testing.T) {
+		r := newRepos(t)
+		student := createStudent(t, r)
+		must(t, r.Users.MarkEmailVerified(student.User.ID))
+		must(t, r.Students.UpdatePrivacy(student.ID, d.Hidden, true))
+
+		student.University = "universiteit utrecht"
+		student.Skills = []string{"java"}
+		student.Experiences = nil
+		student.User.Email = newID() + "@example.com"
+		must(t, r.Students.Update(student))
+
+		found, err := r.Students.FindByID(student.ID)
+		must(t, err)
+		student.Visibility = d.Hidden
+		student.ResumeOnRequest = true
+		assertStudent(t, found, student)
+
+		user, err := r.Users.FindByID(student.User.ID)
+		must(t, err)
+		if user.EmailVerified {
+			t.Error("changed email should have to be verified again")
+		}
+	})
+
+	t.Run("update privacy of unknown student", func(t *testing.T) {
+		r := newRepos(t)
+		if err := r.Students.UpdatePrivacy(newID(), d.Hidden, true); err == nil {
+			t.Error("UpdatePrivacy of unknown student should fail")
+		}
+	})
+
+	t.Run("find by query", func(t *testing.T) {
+		r := newRepos(t)
+		unavailable := d.Unavailable
+		match := newStudent()
+		match.Skills = []string{"Go", "Postgres"}
+		match.Wishes = "an internship in fintech"
+		must(t, r.Students.Create(match))
+
+		other := newStudent()
+		other.University = "tu delft"
+		other.City = "delft"
+		other.Skills = []string{"java"}
+		other.Status = d.Unavailable
+		must(t, r.Students.Create(other))
+
+		cases := map[string]struct {
+			query d.StudentQuery
+			want  []string
+		}{
+			"no filters": {d.StudentQuery{}, []string{match.ID, other.ID}},
+			"university": {d.StudentQuery{University: "UTRECHT"}, []string{match.ID}},
+			"city":       {d.StudentQuery{City: "delf"}, []string{other.ID}},
+			"status":     {d.StudentQuery{Status: &unavailable}, []string{other.ID}},
+			"any skill":  {d.StudentQuery{Skills: []string{"go", "java"}}, []string{match.ID, other.ID}},
+			"all skills": {d.StudentQuery{Skills: []string{"go", "postgres"}, AllSkills: true}, []string{match.ID}},
+			"text":       {d.StudentQuery{Text: "FinTech"}, []string{match.ID}},
+			"no match":   {d.StudentQuery{City: "utrecht", Skills: []string{"java"}}, []string{}},
+		}
+
+		for name, c := range cases {
+			found, _, err := r.Students.FindByQuery(c.query, d.Page{Limit: 10})
+			must(t, err)
+			if ids := studentIDs(found); !sameSet(ids, c.want) {
+				t.Errorf("%s: FindByQuery = %v, want %v", name, ids, c.want)
+			}
+		}
+	})
+
+	t.Run("pages are ordered on id", func(t *testing.T) {
+		r := newRepos(t)
+		ids := []string{}
+		for i := 0; i < 3; i++ {
+			ids = append(ids, createStudent(t, r).ID)
+		}
+		sort.Strings(ids)
+
+		first, next, err := r.Students.FindAll(d.Page{Limit: 2})
+		must(t, err)
+		if !sameStrings(studentIDs(first), ids[:2]) || next != ids[1] {
+			t.Errorf("first page = %v, %q, want %v, %q", studentIDs(first), next, ids[:2], ids[1])
+		}
+
+		last, next, err := r.Students.FindAll(d.Page{Limit: 2, After: next})
+		must(t, err)
+		if !sameStrings(studentIDs(last), ids[2:]) || next != "" {
+			t.Errorf("last page = %v, %q, want %v, %q", studentIDs(last), next, ids[2:], "")
+		}
+	})
+
+	t.Run("visible to company", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+
+		visible := createStudentWith(t, r, d.VisibleToRepresentatives)
+		applied := createStudentWith(t, r, d.VisibleToAppliedCompanies)
+		createStudentWith(t, r, d.VisibleToAppliedCompanies)
+		createStudentWith(t, r, d.Hidden)
+		must(t, r.Applications.Create(newApplication(applied.ID, project.ID, now)))
+
+		found, _, err := r.Students.FindByQuery(d.StudentQuery{VisibleTo: company.ID}, d.Page{Limit: 10})
+		must(t, err)
+		if want := []string{visible.ID, applied.ID}; !sameSet(studentIDs(found), want) {
+			t.Errorf("FindByQuery = %v, want %v", studentIDs(found), want)
+		}
+	})
+
+	t.Run("visible to company and filtered", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		other, _ := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+
+		applied := createStudentWith(t, r, d.VisibleToAppliedCompanies)
+		must(t, r.Applications.Create(newApplication(applied.ID, project.ID, now)))
+
+		query := d.StudentQuery{City: applied.City, Skills: applied.Skills, VisibleTo: company.ID}
+		found, _, err := r.Students.FindByQuery(query, d.Page{Limit: 10})
+		must(t, err)
+		if want := []string{applied.ID}; !sameSet(studentIDs(found), want) {
+			t.Errorf("FindByQuery for the company applied to = %v, want %v", studentIDs(found), want)
+		}
+
+		query.VisibleTo = other.ID
+		found, _, err = r.Students.FindByQuery(query, d.Page{Limit: 10})
+		must(t, err)
+		if len(found) != 0 {
+			t.Errorf("FindByQuery for another company = %v, want none", studentIDs(found))
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		project := addProject(t, r, company.ID, owner.ID)
+		student := createStudent(t, r)
+
+		application := newApplication(student.ID, project.ID, now)
+		entry := newShortlistEntry(company.ID, student.ID, owner.ID, now)
+		conversation := newConversation(student.User.ID, owner.User.ID, "")
+		sent := newMessage(conversation.ID, student.User.ID, owner.User.ID, now)
+		received := newMessage(conversation.ID, owner.User.ID, student.User.ID, now.Add(time.Minute))
+
+		must(t, r.Applications.Create(application))
+		must(t, r.Shortlist.Create(entry))
+		must(t, r.Blocks.Create(d.Block{StudentID: student.ID, CompanyID: company.ID, CreatedAt: now}))
+		must(t, r.ResumeRequests.Create(d.ResumeRequest{StudentID: student.ID, CompanyID: company.ID, RequestedBy: owner.ID, RequestedAt: now}))
+		must(t, r.Conversations.Create(conversation))
+		must(t, r.Messages.Create(sent))
+		must(t, r.Messages.Create(received))
+		must(t, r.Students.Delete(student))
+
+		if _, err := r.Students.FindByID(student.ID); err == nil {
+			t.Error("deleted student should not be found")
+		}
+
+		if _, err := r.Users.FindByID(student.User.ID); err == nil {
+			t.Error("user of deleted student should not be found")
+		}
+
+		if _, err := r.Applications.FindByID(application.ID); err == nil {
+			t.Error("application of deleted student should not be found")
+		}
+
+		if _, err := r.Shortlist.FindByID(entry.ID); err == nil {
+			t.Error("shortlist entry of deleted student should not be found")
+		}
+
+		blocked, err := r.Blocks.Exists(student.ID, company.ID)
+		must(t, err)
+		if blocked {
+			t.Error("block of deleted student should not exist")
+		}
+
+		requests, err := r.ResumeRequests.FindByStudent(student.ID)
+		must(t, err)
+		if len(requests) != 0 {
+			t.Errorf("resume requests of deleted student = %v, want none", requests)
+		}
+
+		found, err := r.Messages.FindByID(sent.ID)
+		must(t, err)
+		if found.Sender != "" || found.Body != d.DeletedMessageBody {
+			t.Errorf("sent message = %+v, want it anonymised", found)
+		}
+
+		found, err = r.Messages.FindByID(received.ID)
+		must(t, err)
+		if found.Receiver != "" || found.Body != received.Body {
+			t.Errorf("received message = %+v, want only its receiver removed", found)
+		}
+
+		kept, err := r.Conversations.FindByID(conversation.ID)
+		must(t, err)
+		if !sameSet(kept.Participants, []string{owner.User.ID}) || kept.StartedBy != "" {
+			t.Errorf("conversation = %+v, want the student removed", kept)
+		}
+	})
+}
+
+// Representatives checks the contract of the RepresentativeRepository
+func Representatives(t *testing.T, newRepos Factory) {
+	t.Run("find created representative", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		repr := createRepresentative(t, r, owner.CompanyID, d.CompanyRecruiter)
+
+		found, err := r.Representatives.FindByID(repr.ID)
+		must(t, err)
+		assertRepresentative(t, found, repr)
+
+		if _, err := r.Representatives.FindByID(newID()); err == nil {
+			t.Error("FindByID of unknown representative should fail")
+		}
+	})
+
+	t.Run("update keeps company role", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		repr := createRepresentative(t, r, owner.CompanyID, d.CompanyRecruiter)
+
+		repr.JobTitle = "hr manager"
+		repr.User.FirstName = "piet"
+		repr.CompanyRole = d.CompanyOwner
+		must(t, r.Representatives.Update(repr))
+
+		found, err := r.Representatives.FindByID(repr.ID)
+		must(t, err)
+		repr.CompanyRole = d.CompanyRecruiter
+		assertRepresentative(t, found, repr)
+	})
+
+	t.Run("update company role", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		repr := createRepresentative(t, r, owner.CompanyID, d.CompanyRecruiter)
+		must(t, r.Representatives.UpdateCompanyRole(repr.ID, d.CompanyAdmin))
+
+		found, err := r.Representatives.FindByID(repr.ID)
+		must(t, err)
+		if found.CompanyRole != d.CompanyAdmin {
+			t.Errorf("CompanyRole = %q, want %q", found.CompanyRole, d.CompanyAdmin)
+		}
+
+		if err := r.Representatives.UpdateCompanyRole(newID(), d.CompanyAdmin); err == nil {
+			t.Error("UpdateCompanyRole of unknown representative should fail")
+		}
+	})
+
+	t.Run("remove hands over to successor", func(t *testing.T) {
+		r := newRepos(t)
+		company, owner := createCompany(t, r)
+		repr := createRepresentative(t, r, company.ID, d.CompanyRecruiter)
+		project := addProject(t, r, company.ID, repr.ID)
+		student := createStudent(t, r)
+
+		conversation := newConversation(repr.User.ID, student.User.ID, project.ID)
+		inviteLink := newInviteLink(repr.ID, company.ID, now)
+		session := newSession(repr.User.ID, now)
+		must(t, r.Conversations.Create(conversation))
+		must(t, r.InviteLinks.Create(inviteLink))
+		must(t, r.Sessions.Create(session))
+		must(t, r.Representatives.Remove(repr.ID, owner.ID, now))
+
+		removed, err := r.Representatives.FindByID(repr.ID)
+		must(t, err)
+		if !removed.RemovedAt.Equal(now) {
+			t.Errorf("RemovedAt = %v, want %v", removed.RemovedAt, now)
+		}
+
+		found, err := r.Companies.FindByID(company.ID)
+		must(t, err)
+		if ids := representativeIDs(found.Representatives); !sameSet(ids, []string{owner.ID}) {
+			t.Errorf("representatives of company = %v, want %v", ids, []string{owner.ID})
+		}
+
+		handedOver, err := r.Projects.FindByID(project.ID)
+		must(t, err)
+		if handedOver.ContactID != owner.ID {
+			t.Errorf("ContactID = %q, want %q", handedOver.ContactID, owner.ID)
+		}
+
+		kept, err := r.Conversations.FindByID(conversation.ID)
+		must(t, err)
+		if want := []string{owner.User.ID, student.User.ID}; !sameSet(kept.Participants, want) {
+			t.Errorf("participants = %v, want %v", kept.Participants, want)
+		}
+
+		revoked, err := r.InviteLinks.FindByID(inviteLink.ID)
+		must(t, err)
+		if !revoked.RevokedAt.Equal(now) {
+			t.Errorf("RevokedAt of invitelink = %v, want %v", revoked.RevokedAt, now)
+		}
+
+		signedOut, err := r.Sessions.FindByTokenHash(session.TokenHash)
+		must(t, err)
+		if !signedOut.RevokedAt.Equal(now) {
+			t.Errorf("RevokedAt of session = %v, want %v", signedOut.RevokedAt, now)
+		}
+
+		if err := r.Representatives.Remove(repr.ID, owner.ID, now); err == nil {
+			t.Error("removing a representative twice should fail")
+		}
+	})
+
+	t.Run("transfer ownership", func(t *testing.T) {
+		r := newRepos(t)
+		_, owner := createCompany(t, r)
+		admin := createRepresentative(t, r, owner.CompanyID, d.CompanyAdmin)
+		must(t, r.Representatives.TransferOwnership(owner.ID, admin.ID))
+
+		found, err := r.Representatives.FindByID(owner.ID)
+		must(t, err)
+		if found.CompanyRole != d.CompanyAdmin {
+			t.Errorf("role of old owner = %q, want %q", found.CompanyRole, d.CompanyAdmin)
+		}
+
+		found, err = r.Representatives.FindByID(admin.ID)
+		must(t, err)
+		if found.CompanyRole != d.CompanyOwner {
+			t.Errorf("role of new owner = %q, want %q", found.CompanyRole, d.CompanyOwner)
+		}
+	})
+}
+
+// helper func that stores a new student with the provided visibility
+func createStudentWith(t *testing.T, r Repos, visibility d.Visibility) d.Student {
+	t.Helper()
+	student := newStudent()
+	student.Visibility = visibility
+	must(t, r.Students.Create(student))
+	return student
+}
+
+// helper func that checks if the found student is the stored
+// one, whose user is returned without their password hash
+func assertStudent(t *testing.T, got, want d.Student) {
+	t.Helper()
+	want.User.HashedPassword = ""
+	want.User.EmailVerified = false
+
+	if got.ID != want.ID || got.University != want.University || got.City != want.City ||
+		got.Wishes != want.Wishes || got.Status != want.Status || got.Resume != want.Resume ||
+		got.Visibility != want.Visibility || got.ResumeOnRequest != want.ResumeOnRequest ||
+		got.User != want.User ||
+		!sameStrings(got.Skills, want.Skills) ||
+		!sameStrings(got.Experiences, want.Experiences) ||
+		!sameStrings(got.ShortExperiences, want.ShortExperiences) {
+		t.Errorf("student = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that checks if the found representative is the stored one.
+// Whether their email has been verified is left out, as it isn't returned.
+func assertRepresentative(t *testing.T, got, want d.Representative) {
+	t.Helper()
+	if got.ID != want.ID || got.JobTitle != want.JobTitle || got.CompanyID != want.CompanyID ||
+		got.CompanyRole != want.CompanyRole || !got.RemovedAt.Equal(want.RemovedAt) ||
+		got.User.ID != want.User.ID || got.User.Email != want.User.Email ||
+		got.User.FirstName != want.User.FirstName || got.User.LastName != want.User.LastName ||
+		got.User.Role != want.User.Role {
+		t.Errorf("representative = %+v, want %+v", got, want)
+	}
+}
+
+// helper func that returns the ids of the students
+func studentIDs(students []d.Student) []string {
+	ids := []string{}
+	for _, student := range students {
+		ids = append(ids, student.ID)
+	}
+
+	return ids
+}
+
+// helper func that returns the ids of the representatives
+func representativeIDs(representatives []d.Representative) []string {
+	ids := []string{}
+	for _, repr := range representatives {
+		ids = append(ids, repr.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ApplicationRepo ...
+type ApplicationRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (a ApplicationRepo) Create(application domain.Application) error {
+	a.Store.mu.Lock()
+	defer a.Store.mu.Unlock()
+
+	if _, ok := a.Store.applications[application.ID]; ok {
+		return e.New(e.Conflict, "application with id: "+application.ID+" already exists")
+	}
+
+	// a student can only apply once to a project, like the constraint in postgres
+	for _, other := range a.Store.applications {
+		if other.StudentID == application.StudentID && other.ProjectID == application.ProjectID {
+			return e.ErrorAlreadyApplied
+		}
+	}
+
+	a.Store.applications[application.ID] = application
+	return nil
+}
+
+// FindByID ...
+func (a ApplicationRepo) FindByID(id string) (domain.Application, error) {
+	a.Store.mu.RLock()
+	defer a.Store.mu.RUnlock()
+
+	if application, ok := a.Store.applications[id]; ok {
+		return application, nil
+	}
+
+	return domain.Application{}, e.ErrorEntityNotFound
+}
+
+// FindByStudentAndProject ...
+func (a ApplicationRepo) FindByStudentAndProject(studentID, projectID string) (domain.Application, error) {
+	a.Store.mu.RLock()
+	defer a.Store.mu.RUnlock()
+
+	for _, application := range a.Store.applications {
+		if application.StudentID == studentID && application.ProjectID == projectID {
+			return application, nil
+		}
+	}
+
+	return domain.Application{}, e.ErrorEntityNotFound
+}
+
+// FindByStudent ...
+func (a ApplicationRepo) FindByStudent(studentID string) ([]domain.Application, error) {
+	a.Store.mu.RLock()
+	defer a.Store.mu.RUnlock()
+
+	applications := []domain.Application{}
+	for _, application := range a.Store.applications {
+		if application.StudentID == studentID {
+			applications = append(applications, application)
+		}
+	}
+
+	sort.Slice(applications, func(i, j int) bool {
+		return applications[i].CreatedAt.After(applications[j].CreatedAt)
+	})
+
+	return applications, nil
+}
+
+// FindByProject ...
+func (a ApplicationRepo) FindByProject(projectID string) ([]domain.Application, error) {
+	a.Store.mu.RLock()
+	defer a.Store.mu.RUnlock()
+
+	applications := []domain.Application{}
+	for _, application := range a.Store.applications {
+		if application.ProjectID == projectID {
+			applications = append(applications, application)
+		}
+	}
+
+	sort.Slice(applications, func(i, j int) bool {
+		return applications[i].CreatedAt.Before(applications[j].CreatedAt)
+	})
+
+	return applications, nil
+}
+
+// Update ...
+func (a ApplicationRepo) Update(application domain.Application) error {
+	a.Store.mu.Lock()
+	defer a.Store.mu.Unlock()
+
+	return a.Store.updateApplication(application)
+}
+
+// UpdateWithMail ...
+func (a ApplicationRepo) UpdateWithMail(application domain.Application, mail domain.OutboxMail) error {
+	a.Store.mu.Lock()
+	defer a.Store.mu.Unlock()
+
+	if _, ok := a.Store.outbox[mail.ID]; ok {
+		return e.New(e.Conflict, "mail with id: "+mail.ID+" already exists")
+	}
+
+	err := a.Store.updateApplication(application)
+	if err != nil {
+		return err
+	}
+
+	return a.Store.enqueue(mail)
+}
+
+// helper func that updates the status of the application, the only
+// part of it that can change. The caller holds the lock.
+func (s *Store) updateApplication(application domain.Application) error {
+	stored, ok := s.applications[application.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	stored.Status = application.Status
+	stored.UpdatedAt = application.UpdatedAt
+	s.applications[application.ID] = stored
+	return nil
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// BlockRepo ...
+type BlockRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (b BlockRepo) Create(block domain.Block) error {
+	b.Store.mu.Lock()
+	defer b.Store.mu.Unlock()
+
+	// blocking a company again is a no-op, like in postgres
+	key := pairKey(block.StudentID, block.CompanyID)
+	if _, ok := b.Store.blocks[key]; !ok {
+		b.Store.blocks[key] = block
+	}
+
+	return nil
+}
+
+// Delete ...
+func (b BlockRepo) Delete(studentID, companyID string) error {
+	b.Store.mu.Lock()
+	defer b.Store.mu.Unlock()
+
+	delete(b.Store.blocks, pairKey(studentID, companyID))
+	return nil
+}
+
+// Exists ...
+func (b BlockRepo) Exists(studentID, companyID string) (bool, error) {
+	b.Store.mu.RLock()
+	defer b.Store.mu.RUnlock()
+
+	_, ok := b.Store.blocks[pairKey(studentID, companyID)]
+	return ok, nil
+}
+
+// FindByStudent ...
+func (b BlockRepo) FindByStudent(studentID string) ([]domain.Block, error) {
+	b.Store.mu.RLock()
+	defer b.Store.mu.RUnlock()
+
+	blocks := []domain.Block{}
+	for _, block := range b.Store.blocks {
+		if block.StudentID == studentID {
+			blocks = append(blocks, block)
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].CreatedAt.After(blocks[j].CreatedAt)
+	})
+
+	return blocks, nil
+}
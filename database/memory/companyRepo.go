@@ -1,48 +1,172 @@
 package memory
 
 import (
-	"errors"
+	"sort"
 
 	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // CompanyRepo ..
 type CompanyRepo struct {
-	DB map[string]domain.Company
+	Store *Store
 }
 
 // Create ...
 func (c CompanyRepo) Create(company domain.Company) error {
-	c.DB[company.ID] = company
+	c.Store.mu.Lock()
+	defer c.Store.mu.Unlock()
+
+	if _, ok := c.Store.companies[company.ID]; ok {
+		return e.New(e.Conflict, "company with id: "+company.ID+" already exists")
+	}
+
+	for _, repr := range company.Representatives {
+		err := c.Store.createRepresentative(repr)
+		if err != nil {
+			return err
+		}
+	}
+
+	company = cloneCompany(company)
+	company.Representatives = nil
+	company.Projects = nil
+	c.Store.companies[company.ID] = company
 	return nil
 }
 
 // FindByID ...
 func (c CompanyRepo) FindByID(id string) (domain.Company, error) {
-	if company, ok := c.DB[id]; ok {
-		return company, nil
+	c.Store.mu.RLock()
+	defer c.Store.mu.RUnlock()
+
+	if company, ok := c.Store.companies[id]; ok {
+		return c.Store.companyWithMembers(company), nil
 	}
 
-	return domain.Company{}, errors.New("no company with id: " + id)
+	return domain.Company{}, e.ErrorEntityNotFound
 }
 
 // FindByName ...
 func (c CompanyRepo) FindByName(name string) (domain.Company, error) {
-	for _, company := range c.DB {
+	c.Store.mu.RLock()
+	defer c.Store.mu.RUnlock()
+
+	for _, company := range c.Store.companies {
 		if company.Name == name {
-			return company, nil
+			return c.Store.companyWithMembers(company), nil
 		}
 	}
 
-	return domain.Company{}, errors.New("no company with name: " + name)
+	return domain.Company{}, e.ErrorEntityNotFound
 }
 
 // FindAll ...
-func (c CompanyRepo) FindAll() ([]domain.Company, error) {
+func (c CompanyRepo) FindAll(page domain.Page) ([]domain.Company, string, error) {
+	c.Store.mu.RLock()
+	defer c.Store.mu.RUnlock()
+
+	ids := []string{}
+	for id := range c.Store.companies {
+		ids = append(ids, id)
+	}
+
+	ids, next := paginate(ids, page)
 	companies := []domain.Company{}
-	for _, company := range c.DB {
-		companies = append(companies, company)
+	for _, id := range ids {
+		companies = append(companies, c.Store.companyWithMembers(c.Store.companies[id]))
 	}
 
-	return companies, nil
+	return companies, next, nil
+}
+
+// AddProject ...
+func (c CompanyRepo) AddProject(p domain.Project) error {
+	c.Store.mu.Lock()
+	defer c.Store.mu.Unlock()
+
+	if _, ok := c.Store.companies[p.CompanyID]; !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	if _, ok := c.Store.projects[p.ID]; ok {
+		return e.New(e.Conflict, "project with id: "+p.ID+" already exists")
+	}
+
+	c.Store.projects[p.ID] = cloneProject(p)
+	return nil
+}
+
+// Update ...
+func (c CompanyRepo) Update(company domain.Company) error {
+	c.Store.mu.Lock()
+	defer c.Store.mu.Unlock()
+
+	stored, ok := c.Store.companies[company.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	stored = cloneCompany(stored)
+	stored.Name = company.Name
+	stored.Description = company.Description
+	stored.Information = company.Information
+
+	// like postgres, only the locations and projects the company already has are updated
+	for _, location := range company.Locations {
+		for i := range stored.Locations {
+			if stored.Locations[i].ID == location.ID {
+				stored.Locations[i] = location
+			}
+		}
+	}
+
+	for _, p := range company.Projects {
+		project, ok := c.Store.projects[p.ID]
+		if !ok {
+			continue
+		}
+
+		project.Description = p.Description
+		project.Compensation = p.Compensation
+		project.Duration = p.Duration
+		project.Recommendations = cloneStrings(p.Recommendations)
+		c.Store.projects[p.ID] = project
+	}
+
+	c.Store.companies[company.ID] = stored
+	return nil
+}
+
+// helper func that copies the company together with its projects and
+// the representatives that work for it. The caller holds the lock.
+func (s *Store) companyWithMembers(company domain.Company) domain.Company {
+	company = cloneCompany(company)
+	if company.Locations == nil {
+		company.Locations = []domain.Address{}
+	}
+
+	company.Projects = []domain.Project{}
+	for _, project := range s.projects {
+		if project.CompanyID == company.ID {
+			company.Projects = append(company.Projects, cloneProject(project))
+		}
+	}
+
+	company.Representatives = []domain.Representative{}
+	for _, repr := range s.representatives {
+		if repr.CompanyID == company.ID && !repr.IsRemoved() {
+			company.Representatives = append(company.Representatives, s.representativeWithUser(repr))
+		}
+	}
+
+	sort.Slice(company.Projects, func(i, j int) bool {
+		return company.Projects[i].ID < company.Projects[j].ID
+	})
+
+	sort.Slice(company.Representatives, func(i, j int) bool {
+		return company.Representatives[i].ID < company.Representatives[j].ID
+	})
+
+	return company
 }
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ConversationRepo ...
+type ConversationRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (c ConversationRepo) Create(conversation domain.Conversation) error {
+	c.Store.mu.Lock()
+	defer c.Store.mu.Unlock()
+
+	if _, ok := c.Store.conversations[conversation.ID]; ok {
+		return e.New(e.Conflict, "conversation with id: "+conversation.ID+" already exists")
+	}
+
+	conversation = cloneConversation(conversation)
+	if conversation.Participants == nil {
+		conversation.Participants = []string{}
+	}
+
+	// like postgres, only the read markers of participants are kept
+	readAt := map[string]time.Time{}
+	for _, participant := range conversation.Participants {
+		if at, ok := conversation.ReadAt[participant]; ok {
+			readAt[participant] = at
+		}
+	}
+
+	conversation.ReadAt = readAt
+	conversation.Unread = 0
+	c.Store.conversations[conversation.ID] = conversation
+	return nil
+}
+
+// FindByID ...
+func (c ConversationRepo) FindByID(id string) (domain.Conversation, error) {
+	c.Store.mu.RLock()
+	defer c.Store.mu.RUnlock()
+
+	if conversation, ok := c.Store.conversations[id]; ok {
+		return cloneConversation(conversation), nil
+	}
+
+	return domain.Conversation{}, e.ErrorEntityNotFound
+}
+
+// FindBetween ...
+func (c ConversationRepo) FindBetween(userID, otherUserID, projectID string) (domain.Conversation, error) {
+	c.Store.mu.RLock()
+	defer c.Store.mu.RUnlock()
+
+	for _, conversation := range c.Store.conversations {
+		if conversation.ProjectID == projectID &&
+			contains(conversation.Participants, userID) &&
+			contains(conversation.Participants, otherUserID) {
+			return cloneConversation(conversation), nil
+		}
+	}
+
+	return domain.Conversation{}, e.ErrorEntityNotFound
+}
+
+// FindByParticipant ...
+func (c ConversationRepo) FindByParticipant(userID string) ([]domain.Conversation, error) {
+	c.Store.mu.RLock()
+	defer c.Store.mu.RUnlock()
+
+	conversations := []domain.Conversation{}
+	for _, conversation := range c.Store.conversations {
+		if !contains(conversation.Participants, userID) {
+			continue
+		}
+
+		conversation = cloneConversation(conversation)
+		readAt := conversation.ReadAt[userID]
+		for _, message := range c.Store.messages {
+			if message.ConversationID == conversation.ID && message.Sender != userID && message.CreatedAt.After(readAt) {
+				conversation.Unread++
+			}
+		}
+
+		conversations = append(conversations, conversation)
+	}
+
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].LastActivity.After(conversations[j].LastActivity)
+	})
+
+	return conversations, nil
+}
+
+// MarkRead ...
+func (c ConversationRepo) MarkRead(conversationID, userID string, at time.Time) error {
+	c.Store.mu.Lock()
+	defer c.Store.mu.Unlock()
+
+	conversation, ok := c.Store.conversations[conversationID]
+	if !ok || !contains(conversation.Participants, userID) {
+		return nil
+	}
+
+	conversation = cloneConversation(conversation)
+	conversation.ReadAt[userID] = at
+	c.Store.conversations[conversationID] = conversation
+	return nil
+}
+
+// CountStartedBy ...
+func (c ConversationRepo) CountStartedBy(userIDs []string, since time.Time) (int, error) {
+	c.Store.mu.RLock()
+	defer c.Store.mu.RUnlock()
+
+	count := 0
+	for _, conversation := range c.Store.conversations {
+		if contains(userIDs, conversation.StartedBy) && !conversation.CreatedAt.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -1,33 +1,142 @@
 package memory
 
 import (
-	"errors"
+	"sort"
+	"time"
 
 	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // InviteLinkRepo ...
 type InviteLinkRepo struct {
-	DB map[string]domain.InviteLink
+	Store *Store
 }
 
 // Create ...
 func (i InviteLinkRepo) Create(inviteLink domain.InviteLink) error {
-	i.DB[inviteLink.ID] = inviteLink
-	return nil
+	i.Store.mu.Lock()
+	defer i.Store.mu.Unlock()
+
+	return i.Store.createInviteLink(inviteLink)
 }
 
 // FindByID ...
 func (i InviteLinkRepo) FindByID(id string) (domain.InviteLink, error) {
-	if inviteLink, ok := i.DB[id]; ok {
+	i.Store.mu.RLock()
+	defer i.Store.mu.RUnlock()
+
+	if inviteLink, ok := i.Store.inviteLinks[id]; ok {
 		return inviteLink, nil
 	}
 
-	return domain.InviteLink{}, errors.New("no invitelink with id: " + id)
+	return domain.InviteLink{}, e.ErrorEntityNotFound
+}
+
+// CreateWithMail ...
+func (i InviteLinkRepo) CreateWithMail(inviteLink domain.InviteLink, mail domain.OutboxMail) error {
+	i.Store.mu.Lock()
+	defer i.Store.mu.Unlock()
+
+	if _, ok := i.Store.outbox[mail.ID]; ok {
+		return e.New(e.Conflict, "mail with id: "+mail.ID+" already exists")
+	}
+
+	err := i.Store.createInviteLink(inviteLink)
+	if err != nil {
+		return err
+	}
+
+	return i.Store.enqueue(mail)
+}
+
+// FindByCreator ...
+func (i InviteLinkRepo) FindByCreator(creatorID string) ([]domain.InviteLink, error) {
+	i.Store.mu.RLock()
+	defer i.Store.mu.RUnlock()
+
+	inviteLinks := []domain.InviteLink{}
+	for _, inviteLink := range i.Store.inviteLinks {
+		if inviteLink.CreatedBy == creatorID {
+			inviteLinks = append(inviteLinks, inviteLink)
+		}
+	}
+
+	sort.Slice(inviteLinks, func(i, j int) bool {
+		return inviteLinks[i].CreatedAt.After(inviteLinks[j].CreatedAt)
+	})
+
+	return inviteLinks, nil
+}
+
+// Redeem ...
+func (i InviteLinkRepo) Redeem(id string, at time.Time) error {
+	i.Store.mu.Lock()
+	defer i.Store.mu.Unlock()
+
+	inviteLink, ok := i.Store.inviteLinks[id]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	if inviteLink.HasBeenUsed() || inviteLink.IsRevoked() || !at.Before(inviteLink.ExpiryDate) {
+		return e.ErrorInviteNotPending
+	}
+
+	inviteLink.Uses++
+	i.Store.inviteLinks[id] = inviteLink
+	return nil
+}
+
+// Revoke ...
+func (i InviteLinkRepo) Revoke(id string, at time.Time) error {
+	i.Store.mu.Lock()
+	defer i.Store.mu.Unlock()
+
+	inviteLink, ok := i.Store.inviteLinks[id]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	if inviteLink.HasBeenUsed() || inviteLink.IsRevoked() {
+		return e.ErrorInviteNotPending
+	}
+
+	inviteLink.RevokedAt = at
+	i.Store.inviteLinks[id] = inviteLink
+	return nil
+}
+
+// RenewWithMail ...
+func (i InviteLinkRepo) RenewWithMail(inviteLink domain.InviteLink, mail domain.OutboxMail) error {
+	i.Store.mu.Lock()
+	defer i.Store.mu.Unlock()
+
+	stored, ok := i.Store.inviteLinks[inviteLink.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	if stored.HasBeenUsed() || stored.IsRevoked() {
+		return e.ErrorInviteNotPending
+	}
+
+	err := i.Store.enqueue(mail)
+	if err != nil {
+		return err
+	}
+
+	stored.ExpiryDate = inviteLink.ExpiryDate
+	i.Store.inviteLinks[inviteLink.ID] = stored
+	return nil
 }
 
-// Update ...
-func (i InviteLinkRepo) Update(inviteLink domain.InviteLink) error {
-	i.DB[inviteLink.ID] = inviteLink
+// helper func that stores the invitelink. The caller holds the lock.
+func (s *Store) createInviteLink(inviteLink domain.InviteLink) error {
+	if _, ok := s.inviteLinks[inviteLink.ID]; ok {
+		return e.New(e.Conflict, "invitelink with id: "+inviteLink.ID+" already exists")
+	}
+
+	s.inviteLinks[inviteLink.ID] = inviteLink
 	return nil
 }
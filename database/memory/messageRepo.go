@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// MessageRepo ...
+type MessageRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (m MessageRepo) Create(message domain.Message) error {
+	m.Store.mu.Lock()
+	defer m.Store.mu.Unlock()
+
+	return m.Store.createMessage(message)
+}
+
+// FindByID ...
+func (m MessageRepo) FindByID(id string) (domain.Message, error) {
+	m.Store.mu.RLock()
+	defer m.Store.mu.RUnlock()
+
+	if message, ok := m.Store.messages[id]; ok {
+		return message, nil
+	}
+
+	return domain.Message{}, e.ErrorEntityNotFound
+}
+
+// FindByConversation ...
+func (m MessageRepo) FindByConversation(conversationID string) ([]domain.Message, error) {
+	m.Store.mu.RLock()
+	defer m.Store.mu.RUnlock()
+
+	messages := []domain.Message{}
+	for _, message := range m.Store.messages {
+		if message.ConversationID == conversationID {
+			messages = append(messages, message)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+
+	return messages, nil
+}
+
+// CreateWithMail ...
+func (m MessageRepo) CreateWithMail(message domain.Message, mail domain.OutboxMail) error {
+	m.Store.mu.Lock()
+	defer m.Store.mu.Unlock()
+
+	if _, ok := m.Store.outbox[mail.ID]; ok {
+		return e.New(e.Conflict, "mail with id: "+mail.ID+" already exists")
+	}
+
+	err := m.Store.createMessage(message)
+	if err != nil {
+		return err
+	}
+
+	return m.Store.enqueue(mail)
+}
+
+// helper func that stores the message and updates the last activity
+// of the conversation it is part of. The caller holds the lock.
+func (s *Store) createMessage(message domain.Message) error {
+	conversation, ok := s.conversations[message.ConversationID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	if _, ok := s.messages[message.ID]; ok {
+		return e.New(e.Conflict, "message with id: "+message.ID+" already exists")
+	}
+
+	s.messages[message.ID] = message
+	conversation.LastActivity = message.CreatedAt
+	s.conversations[message.ConversationID] = conversation
+	return nil
+}
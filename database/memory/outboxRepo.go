@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// OutboxRepo ...
+type OutboxRepo struct {
+	Store *Store
+}
+
+// Enqueue ...
+func (o OutboxRepo) Enqueue(mail domain.OutboxMail) error {
+	o.Store.mu.Lock()
+	defer o.Store.mu.Unlock()
+
+	return o.Store.enqueue(mail)
+}
+
+// ClaimDue ...
+func (o OutboxRepo) ClaimDue(at time.Time, lease time.Duration, limit int) ([]domain.OutboxMail, error) {
+	o.Store.mu.Lock()
+	defer o.Store.mu.Unlock()
+
+	mails := []domain.OutboxMail{}
+	for _, mail := range o.Store.outbox {
+		if mail.SentAt.IsZero() && !mail.IsDead() && !mail.NextAttemptAt.After(at) {
+			mails = append(mails, mail)
+		}
+	}
+
+	sort.Slice(mails, func(i, j int) bool {
+		return mails[i].NextAttemptAt.Before(mails[j].NextAttemptAt)
+	})
+
+	if len(mails) > limit {
+		mails = mails[:limit]
+	}
+
+	for i := range mails {
+		mails[i].NextAttemptAt = at.Add(lease)
+		o.Store.outbox[mails[i].ID] = mails[i]
+		mails[i] = cloneOutboxMail(mails[i])
+	}
+
+	return mails, nil
+}
+
+// MarkSent ...
+func (o OutboxRepo) MarkSent(id string, at time.Time) error {
+	o.Store.mu.Lock()
+	defer o.Store.mu.Unlock()
+
+	mail, ok := o.Store.outbox[id]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	mail.SentAt = at
+	o.Store.outbox[id] = mail
+	return nil
+}
+
+// MarkFailed ...
+func (o OutboxRepo) MarkFailed(mail domain.OutboxMail) error {
+	o.Store.mu.Lock()
+	defer o.Store.mu.Unlock()
+
+	stored, ok := o.Store.outbox[mail.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	stored.Attempts = mail.Attempts
+	stored.LastError = mail.LastError
+	stored.NextAttemptAt = mail.NextAttemptAt
+	o.Store.outbox[mail.ID] = stored
+	return nil
+}
+
+// helper func that queues the mail. The caller holds the lock.
+func (s *Store) enqueue(mail domain.OutboxMail) error {
+	if _, ok := s.outbox[mail.ID]; ok {
+		return e.New(e.Conflict, "mail with id: "+mail.ID+" already exists")
+	}
+
+	s.outbox[mail.ID] = cloneOutboxMail(mail)
+	return nil
+}
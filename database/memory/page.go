@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// helper func that sorts the provided ids and returns the ids on
+// the requested page, along with the id to continue after
+func paginate(ids []string, page domain.Page) ([]string, string) {
+	sort.Strings(ids)
+
+	start := sort.SearchStrings(ids, page.After)
+	if start < len(ids) && ids[start] == page.After {
+		start++
+	}
+
+	ids = ids[start:]
+	if len(ids) > page.Limit {
+		return ids[:page.Limit], ids[page.Limit-1]
+	}
+
+	return ids, ""
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ProjectRepo ...
+type ProjectRepo struct {
+	Store *Store
+}
+
+// FindByID ...
+func (p ProjectRepo) FindByID(id string) (domain.Project, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	if project, ok := p.Store.projects[id]; ok {
+		return cloneProject(project), nil
+	}
+
+	return domain.Project{}, e.ErrorEntityNotFound
+}
+
+// Delete ...
+func (p ProjectRepo) Delete(id string) error {
+	p.Store.mu.Lock()
+	defer p.Store.mu.Unlock()
+
+	// like postgres, applications to the project are deleted along with it,
+	// while shortlist entries stay without a project, unless the student
+	// is already on the shortlist without one
+	for applicationID, application := range p.Store.applications {
+		if application.ProjectID == id {
+			delete(p.Store.applications, applicationID)
+		}
+	}
+
+	for entryID, entry := range p.Store.shortlist {
+		if entry.ProjectID != id {
+			continue
+		}
+
+		entry.ProjectID = ""
+		if (ShortlistRepo{Store: p.Store}).isDuplicate(entry) {
+			delete(p.Store.shortlist, entryID)
+			continue
+		}
+
+		p.Store.shortlist[entryID] = entry
+	}
+
+	delete(p.Store.projects, id)
+	return nil
+}
+
+// FindAll ...
+func (p ProjectRepo) FindAll(page domain.Page) ([]domain.Project, string, error) {
+	p.Store.mu.RLock()
+	defer p.Store.mu.RUnlock()
+
+	ids := []string{}
+	for id := range p.Store.projects {
+		ids = append(ids, id)
+	}
+
+	ids, next := paginate(ids, page)
+	projects := []domain.Project{}
+	for _, id := range ids {
+		projects = append(projects, cloneProject(p.Store.projects[id]))
+	}
+
+	return projects, next, nil
+}
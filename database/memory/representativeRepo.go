@@ -1,27 +1,179 @@
 package memory
 
 import (
-	"errors"
+	"time"
 
 	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // RepresentativeRepo ...
 type RepresentativeRepo struct {
-	DB map[string]domain.Representative
+	Store *Store
 }
 
 // Create ...
 func (r RepresentativeRepo) Create(repr domain.Representative) error {
-	r.DB[repr.ID] = repr
-	return nil
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	return r.Store.createRepresentative(repr)
 }
 
 // FindByID ...
 func (r RepresentativeRepo) FindByID(id string) (domain.Representative, error) {
-	if repr, ok := r.DB[id]; ok {
-		return repr, nil
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	if repr, ok := r.Store.representatives[id]; ok {
+		return r.Store.representativeWithUser(repr), nil
+	}
+
+	return domain.Representative{}, e.ErrorEntityNotFound
+}
+
+// Update ...
+func (r RepresentativeRepo) Update(repr domain.Representative) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	old, ok := r.Store.representatives[repr.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	err := r.Store.updateUser(old.User.ID, repr.User)
+	if err != nil {
+		return err
+	}
+
+	old.JobTitle = repr.JobTitle
+	r.Store.representatives[repr.ID] = old
+	return nil
+}
+
+// UpdateCompanyRole ...
+func (r RepresentativeRepo) UpdateCompanyRole(representativeID string, role domain.CompanyRole) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	repr, ok := r.Store.representatives[representativeID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	repr.CompanyRole = role
+	r.Store.representatives[representativeID] = repr
+	return nil
+}
+
+// Remove ...
+func (r RepresentativeRepo) Remove(representativeID, successorID string, at time.Time) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	repr, ok := r.Store.representatives[representativeID]
+	if !ok || repr.IsRemoved() {
+		return e.ErrorEntityNotFound
+	}
+
+	successor, ok := r.Store.representatives[successorID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	repr.RemovedAt = at
+	r.Store.representatives[representativeID] = repr
+
+	for id, project := range r.Store.projects {
+		if project.ContactID == representativeID {
+			project.ContactID = successorID
+			r.Store.projects[id] = project
+		}
+	}
+
+	// the successor takes the place of the representative in their conversations,
+	// unless the successor already takes part in it. Sent messages keep their sender.
+	userID, successorUserID := repr.User.ID, successor.User.ID
+	for id, conversation := range r.Store.conversations {
+		if !contains(conversation.Participants, userID) || contains(conversation.Participants, successorUserID) {
+			continue
+		}
+
+		conversation = cloneConversation(conversation)
+		for i, participant := range conversation.Participants {
+			if participant == userID {
+				conversation.Participants[i] = successorUserID
+			}
+		}
+
+		delete(conversation.ReadAt, userID)
+		r.Store.conversations[id] = conversation
+	}
+
+	for id, inviteLink := range r.Store.inviteLinks {
+		if inviteLink.CreatedBy == representativeID && !inviteLink.HasBeenUsed() && !inviteLink.IsRevoked() {
+			inviteLink.RevokedAt = at
+			r.Store.inviteLinks[id] = inviteLink
+		}
+	}
+
+	r.Store.revokeSessions(userID, at)
+	return nil
+}
+
+// TransferOwnership ...
+func (r RepresentativeRepo) TransferOwnership(ownerID, newOwnerID string) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	owner, ok := r.Store.representatives[ownerID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	newOwner, ok := r.Store.representatives[newOwnerID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	owner.CompanyRole = domain.CompanyAdmin
+	newOwner.CompanyRole = domain.CompanyOwner
+	r.Store.representatives[ownerID] = owner
+	r.Store.representatives[newOwnerID] = newOwner
+	return nil
+}
+
+// helper func that stores the representative together with
+// their user account. The caller holds the lock.
+func (s *Store) createRepresentative(repr domain.Representative) error {
+	if _, ok := s.representatives[repr.ID]; ok {
+		return e.New(e.Conflict, "representative with id: "+repr.ID+" already exists")
+	}
+
+	err := s.createUser(repr.User)
+	if err != nil {
+		return err
+	}
+
+	repr.User = domain.User{ID: repr.User.ID}
+	s.representatives[repr.ID] = repr
+	return nil
+}
+
+// helper func that fills in the user of the representative. The caller holds the lock.
+func (s *Store) representativeWithUser(repr domain.Representative) domain.Representative {
+	repr.User = s.users[repr.User.ID]
+	return repr
+}
+
+// helper func that checks if the ids contain the provided id
+func contains(ids []string, id string) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
 	}
 
-	return domain.Representative{}, errors.New("no representative with id: " + id)
+	return false
 }
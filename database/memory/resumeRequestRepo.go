@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ResumeRequestRepo ...
+type ResumeRequestRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (r ResumeRequestRepo) Create(request domain.ResumeRequest) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	// asking for the resume again is a no-op, like in postgres
+	key := pairKey(request.StudentID, request.CompanyID)
+	if _, ok := r.Store.resumeRequests[key]; !ok {
+		request.GrantedAt = time.Time{}
+		r.Store.resumeRequests[key] = request
+	}
+
+	return nil
+}
+
+// FindByStudent ...
+func (r ResumeRequestRepo) FindByStudent(studentID string) ([]domain.ResumeRequest, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	requests := []domain.ResumeRequest{}
+	for _, request := range r.Store.resumeRequests {
+		if request.StudentID == studentID {
+			requests = append(requests, request)
+		}
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].RequestedAt.After(requests[j].RequestedAt)
+	})
+
+	return requests, nil
+}
+
+// Grant ...
+func (r ResumeRequestRepo) Grant(studentID, companyID string, at time.Time) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	key := pairKey(studentID, companyID)
+	request, ok := r.Store.resumeRequests[key]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	if !request.IsGranted() {
+		request.GrantedAt = at
+		r.Store.resumeRequests[key] = request
+	}
+
+	return nil
+}
+
+// Delete ...
+func (r ResumeRequestRepo) Delete(studentID, companyID string) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	delete(r.Store.resumeRequests, pairKey(studentID, companyID))
+	return nil
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// snippetRadius is the amount of characters shown
+// around the first keyword found in a snippet
+const snippetRadius = 60
+
+// SearchRepo searches the companies, and their projects, on substrings
+type SearchRepo struct {
+	Store *Store
+}
+
+// Search ...
+func (s SearchRepo) Search(query domain.SearchQuery) ([]domain.SearchHit, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	terms := query.Terms()
+	hits := []domain.SearchHit{}
+
+	for _, company := range s.Store.companies {
+		company = s.Store.companyWithMembers(company)
+		companyText := company.Name + " " + company.Description
+		if rank := rank(companyText, terms); rank > 0 {
+			hits = append(hits, domain.SearchHit{
+				Kind:      domain.CompanyHit,
+				ID:        company.ID,
+				CompanyID: company.ID,
+				Title:     company.Name,
+				Snippet:   snippet(company.Description, terms),
+				Rank:      rank,
+			})
+		}
+
+		for _, project := range company.Projects {
+			projectText := project.Description + " " + strings.Join(project.Recommendations, " ")
+			if rank := rank(projectText+" "+companyText, terms); rank > 0 {
+				hits = append(hits, domain.SearchHit{
+					Kind:      domain.ProjectHit,
+					ID:        project.ID,
+					CompanyID: company.ID,
+					Title:     company.Name,
+					Snippet:   snippet(projectText, terms),
+					Rank:      rank,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Rank > hits[j].Rank
+	})
+
+	if len(hits) > query.Limit {
+		hits = hits[:query.Limit]
+	}
+
+	return hits, nil
+}
+
+// helper func that ranks the text on the amount of times the terms occur
+// in it. The rank is 0 if one of the terms does not occur at all.
+func rank(text string, terms []string) float64 {
+	text = strings.ToLower(text)
+
+	total := 0
+	for _, term := range terms {
+		count := strings.Count(text, term)
+		if count == 0 {
+			return 0
+		}
+
+		total += count
+	}
+
+	return float64(total)
+}
+
+// helper func that cuts the part around the first term found out of the
+// lowercased text, highlighting the terms in it the way postgres does.
+// The text itself is escaped, so only the highlighting is html.
+func snippet(text string, terms []string) string {
+	text = strings.ToLower(text)
+
+	first := -1
+	for _, term := range terms {
+		if i := strings.Index(text, term); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+
+	if first < 0 {
+		first = 0
+	}
+
+	start, end := first-snippetRadius, first+snippetRadius
+	if start < 0 {
+		start = 0
+	}
+
+	if end > len(text) {
+		end = len(text)
+	}
+
+	// don't cut multi byte characters in half
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	part := text[start:end]
+	highlighted := ""
+	for i := 0; i < len(part); {
+		matched := ""
+		for _, term := range terms {
+			if strings.HasPrefix(part[i:], term) && len(term) > len(matched) {
+				matched = term
+			}
+		}
+
+		if matched == "" {
+			highlighted += html.EscapeString(part[i : i+1])
+			i++
+			continue
+		}
+
+		highlighted += "<b>" + html.EscapeString(matched) + "</b>"
+		i += len(matched)
+	}
+
+	return highlighted
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// SessionRepo ...
+type SessionRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (s SessionRepo) Create(session domain.Session) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	return s.Store.createSession(session)
+}
+
+// FindByTokenHash ...
+func (s SessionRepo) FindByTokenHash(tokenHash string) (domain.Session, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	for _, session := range s.Store.sessions {
+		if session.TokenHash == tokenHash {
+			return session, nil
+		}
+	}
+
+	return domain.Session{}, e.ErrorEntityNotFound
+}
+
+// Rotate ...
+func (s SessionRepo) Rotate(old, replacement domain.Session) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	stored, ok := s.Store.sessions[old.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	// a token can't be rotated twice by concurrent requests
+	if !stored.UsedAt.IsZero() {
+		return e.ErrorTokenReused
+	}
+
+	err := s.Store.createSession(replacement)
+	if err != nil {
+		return err
+	}
+
+	stored.UsedAt = replacement.CreatedAt
+	s.Store.sessions[old.ID] = stored
+	return nil
+}
+
+// RevokeFamily ...
+func (s SessionRepo) RevokeFamily(familyID string, at time.Time) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	for id, session := range s.Store.sessions {
+		if session.FamilyID == familyID && session.RevokedAt.IsZero() {
+			session.RevokedAt = at
+			s.Store.sessions[id] = session
+		}
+	}
+
+	return nil
+}
+
+// RevokeByUser ...
+func (s SessionRepo) RevokeByUser(userID string, at time.Time) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	s.Store.revokeSessions(userID, at)
+	return nil
+}
+
+// IsFamilyActive ...
+func (s SessionRepo) IsFamilyActive(familyID string) (bool, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	now := time.Now()
+	for _, session := range s.Store.sessions {
+		if session.FamilyID == familyID && session.RevokedAt.IsZero() && session.ExpiresAt.After(now) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// helper func that stores the session, unless its id or token
+// is already in use. The caller holds the lock.
+func (s *Store) createSession(session domain.Session) error {
+	for _, other := range s.sessions {
+		if other.ID == session.ID || other.TokenHash == session.TokenHash {
+			return e.New(e.Conflict, "session with id: "+session.ID+" or its token already exists")
+		}
+	}
+
+	session.UsedAt = time.Time{}
+	session.RevokedAt = time.Time{}
+	s.sessions[session.ID] = session
+	return nil
+}
+
+// helper func that revokes all sessions of the user that
+// haven't been revoked yet. The caller holds the lock.
+func (s *Store) revokeSessions(userID string, at time.Time) {
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt.IsZero() {
+			session.RevokedAt = at
+			s.sessions[id] = session
+		}
+	}
+}
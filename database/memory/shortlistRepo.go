@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ShortlistRepo ...
+type ShortlistRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (s ShortlistRepo) Create(entry domain.ShortlistEntry) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	if _, ok := s.Store.shortlist[entry.ID]; ok {
+		return e.New(e.Conflict, "shortlist entry with id: "+entry.ID+" already exists")
+	}
+
+	if s.isDuplicate(entry) {
+		return e.ErrorAlreadyShortlisted
+	}
+
+	s.Store.shortlist[entry.ID] = cloneShortlistEntry(entry)
+	return nil
+}
+
+// FindByID ...
+func (s ShortlistRepo) FindByID(id string) (domain.ShortlistEntry, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	if entry, ok := s.Store.shortlist[id]; ok {
+		return cloneShortlistEntry(entry), nil
+	}
+
+	return domain.ShortlistEntry{}, e.ErrorEntityNotFound
+}
+
+// FindByCompany ...
+func (s ShortlistRepo) FindByCompany(companyID string) ([]domain.ShortlistEntry, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	entries := []domain.ShortlistEntry{}
+	for _, entry := range s.Store.shortlist {
+		if entry.CompanyID == companyID {
+			entries = append(entries, cloneShortlistEntry(entry))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].UpdatedAt.After(entries[j].UpdatedAt)
+	})
+
+	return entries, nil
+}
+
+// Update ...
+func (s ShortlistRepo) Update(entry domain.ShortlistEntry) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	stored, ok := s.Store.shortlist[entry.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	stored.ProjectID = entry.ProjectID
+	if s.isDuplicate(stored) {
+		return e.ErrorAlreadyShortlisted
+	}
+
+	stored.Notes = entry.Notes
+	stored.Tags = cloneStrings(entry.Tags)
+	stored.UpdatedAt = entry.UpdatedAt
+	s.Store.shortlist[entry.ID] = stored
+	return nil
+}
+
+// Delete ...
+func (s ShortlistRepo) Delete(id string) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	delete(s.Store.shortlist, id)
+	return nil
+}
+
+// helper func that checks if another entry puts the same student on the
+// shortlist for the same project, or without a project if the entry has
+// none, like the unique indexes of postgres. The lock has to be held by the caller.
+func (s ShortlistRepo) isDuplicate(entry domain.ShortlistEntry) bool {
+	for _, other := range s.Store.shortlist {
+		if other.ID != entry.ID &&
+			other.CompanyID == entry.CompanyID &&
+			other.StudentID == entry.StudentID &&
+			other.ProjectID == entry.ProjectID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// Store holds all data of the in-memory storage backend, guarded by a single
+// lock. Repos created from the same store share its data, so changes spanning
+// several tables, like deleting a student, happen all at once as they do in
+// postgres. Entities are copied on the way in and out, so callers never share
+// slices or maps with the store.
+type Store struct {
+	mu sync.RWMutex
+	tables
+}
+
+// tables holds the entities of a store, keyed by id unless stated otherwise
+type tables struct {
+	users           map[string]domain.User
+	students        map[string]domain.Student        // only the id of their user is kept
+	representatives map[string]domain.Representative // only the id of their user is kept
+	companies       map[string]domain.Company        // without their representatives and projects
+	projects        map[string]domain.Project
+	inviteLinks     map[string]domain.InviteLink
+	conversations   map[string]domain.Conversation
+	messages        map[string]domain.Message
+	blocks          map[string]domain.Block // keyed by pairKey(student, company)
+	applications    map[string]domain.Application
+	shortlist       map[string]domain.ShortlistEntry
+	resumeRequests  map[string]domain.ResumeRequest // keyed by pairKey(student, company)
+	sessions        map[string]domain.Session
+	userTokens      map[string]domain.UserToken
+	outbox          map[string]domain.OutboxMail
+}
+
+// NewStore creates a new, empty store
+func NewStore() *Store {
+	return &Store{tables: tables{
+		users:           map[string]domain.User{},
+		students:        map[string]domain.Student{},
+		representatives: map[string]domain.Representative{},
+		companies:       map[string]domain.Company{},
+		projects:        map[string]domain.Project{},
+		inviteLinks:     map[string]domain.InviteLink{},
+		conversations:   map[string]domain.Conversation{},
+		messages:        map[string]domain.Message{},
+		blocks:          map[string]domain.Block{},
+		applications:    map[string]domain.Application{},
+		shortlist:       map[string]domain.ShortlistEntry{},
+		resumeRequests:  map[string]domain.ResumeRequest{},
+		sessions:        map[string]domain.Session{},
+		userTokens:      map[string]domain.UserToken{},
+		outbox:          map[string]domain.OutboxMail{},
+	}}
+}
+
+// clone copies the tables. The entities themselves are shared, which is
+// safe as the store replaces entities instead of changing them in place.
+func (t tables) clone() tables {
+	c := tables{
+		users:           make(map[string]domain.User, len(t.users)),
+		students:        make(map[string]domain.Student, len(t.students)),
+		representatives: make(map[string]domain.Representative, len(t.representatives)),
+		companies:       make(map[string]domain.Company, len(t.companies)),
+		projects:        make(map[string]domain.Project, len(t.projects)),
+		inviteLinks:     make(map[string]domain.InviteLink, len(t.inviteLinks)),
+		conversations:   make(map[string]domain.Conversation, len(t.conversations)),
+		messages:        make(map[string]domain.Message, len(t.messages)),
+		blocks:          make(map[string]domain.Block, len(t.blocks)),
+		applications:    make(map[string]domain.Application, len(t.applications)),
+		shortlist:       make(map[string]domain.ShortlistEntry, len(t.shortlist)),
+		resumeRequests:  make(map[string]domain.ResumeRequest, len(t.resumeRequests)),
+		sessions:        make(map[string]domain.Session, len(t.sessions)),
+		userTokens:      make(map[string]domain.UserToken, len(t.userTokens)),
+		outbox:          make(map[string]domain.OutboxMail, len(t.outbox)),
+	}
+
+	for k, v := range t.users {
+		c.users[k] = v
+	}
+	for k, v := range t.students {
+		c.students[k] = v
+	}
+	for k, v := range t.representatives {
+		c.representatives[k] = v
+	}
+	for k, v := range t.companies {
+		c.companies[k] = v
+	}
+	for k, v := range t.projects {
+		c.projects[k] = v
+	}
+	for k, v := range t.inviteLinks {
+		c.inviteLinks[k] = v
+	}
+	for k, v := range t.conversations {
+		c.conversations[k] = v
+	}
+	for k, v := range t.messages {
+		c.messages[k] = v
+	}
+	for k, v := range t.blocks {
+		c.blocks[k] = v
+	}
+	for k, v := range t.applications {
+		c.applications[k] = v
+	}
+	for k, v := range t.shortlist {
+		c.shortlist[k] = v
+	}
+	for k, v := range t.resumeRequests {
+		c.resumeRequests[k] = v
+	}
+	for k, v := range t.sessions {
+		c.sessions[k] = v
+	}
+	for k, v := range t.userTokens {
+		c.userTokens[k] = v
+	}
+	for k, v := range t.outbox {
+		c.outbox[k] = v
+	}
+
+	return c
+}
+
+// helper func that builds the key of entities identified by two ids
+func pairKey(id, otherID string) string {
+	return id + "/" + otherID
+}
+
+// helper func that copies a slice of strings, keeping nil slices nil
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(s)), s...)
+}
+
+// helper func that deep copies a student
+func cloneStudent(s domain.Student) domain.Student {
+	s.Skills = cloneStrings(s.Skills)
+	s.Experiences = cloneStrings(s.Experiences)
+	s.ShortExperiences = cloneStrings(s.ShortExperiences)
+	return s
+}
+
+// helper func that deep copies a project
+func cloneProject(p domain.Project) domain.Project {
+	p.Recommendations = cloneStrings(p.Recommendations)
+	return p
+}
+
+// helper func that deep copies a company
+func cloneCompany(c domain.Company) domain.Company {
+	if c.Locations != nil {
+		c.Locations = append(make([]domain.Address, 0, len(c.Locations)), c.Locations...)
+	}
+
+	if c.Representatives != nil {
+		c.Representatives = append(make([]domain.Representative, 0, len(c.Representatives)), c.Representatives...)
+	}
+
+	if c.Projects != nil {
+		projects := make([]domain.Project, 0, len(c.Projects))
+		for _, p := range c.Projects {
+			projects = append(projects, cloneProject(p))
+		}
+		c.Projects = projects
+	}
+
+	return c
+}
+
+// helper func that deep copies a conversation
+func cloneConversation(c domain.Conversation) domain.Conversation {
+	c.Participants = cloneStrings(c.Participants)
+	if c.ReadAt != nil {
+		readAt := make(map[string]time.Time, len(c.ReadAt))
+		for userID, at := range c.ReadAt {
+			readAt[userID] = at
+		}
+		c.ReadAt = readAt
+	}
+
+	return c
+}
+
+// helper func that deep copies a shortlist entry
+func cloneShortlistEntry(s domain.ShortlistEntry) domain.ShortlistEntry {
+	s.Tags = cloneStrings(s.Tags)
+	return s
+}
+
+// helper func that deep copies an outbox mail
+func cloneOutboxMail(m domain.OutboxMail) domain.OutboxMail {
+	if m.Data != nil {
+		data := make(map[string]string, len(m.Data))
+		for key, value := range m.Data {
+			data[key] = value
+		}
+		m.Data = data
+	}
+
+	return m
+}
@@ -1,43 +1,247 @@
 package memory
 
 import (
-	"errors"
-
 	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // StudentRepo ...
 type StudentRepo struct {
-	DB map[string]domain.Student
+	Store *Store
 }
 
 // Create ...
 func (s StudentRepo) Create(student domain.Student) error {
-	s.DB[student.ID] = student
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	if _, ok := s.Store.students[student.ID]; ok {
+		return e.New(e.Conflict, "Student with id "+student.ID+" already exists")
+	}
+
+	err := s.Store.createUser(student.User)
+	if err != nil {
+		return err
+	}
+
+	student.User = domain.User{ID: student.User.ID}
+	s.Store.students[student.ID] = cloneStudent(student)
 	return nil
 }
 
 // Update ...
 func (s StudentRepo) Update(student domain.Student) error {
-	s.DB[student.ID] = student
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	old, ok := s.Store.students[student.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	err := s.Store.updateUser(old.User.ID, student.User)
+	if err != nil {
+		return err
+	}
+
+	student.User = old.User
+	student.Visibility = old.Visibility
+	student.ResumeOnRequest = old.ResumeOnRequest
+	s.Store.students[student.ID] = cloneStudent(student)
+	return nil
+}
+
+// UpdatePrivacy ...
+func (s StudentRepo) UpdatePrivacy(studentID string, visibility domain.Visibility, resumeOnRequest bool) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	student, ok := s.Store.students[studentID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	student.Visibility = visibility
+	student.ResumeOnRequest = resumeOnRequest
+	s.Store.students[studentID] = student
+	return nil
+}
+
+// Delete ...
+func (s StudentRepo) Delete(student domain.Student) error {
+	s.Store.mu.Lock()
+	defer s.Store.mu.Unlock()
+
+	stored, ok := s.Store.students[student.ID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	userID := stored.User.ID
+	email := s.Store.users[userID].Email
+
+	// the messages of the student are kept for the other participant
+	for id, message := range s.Store.messages {
+		if message.Sender == userID {
+			message.Sender = ""
+			message.Body = domain.DeletedMessageBody
+		}
+
+		if message.Receiver == userID {
+			message.Receiver = ""
+		}
+
+		s.Store.messages[id] = message
+	}
+
+	for id, conversation := range s.Store.conversations {
+		conversation = cloneConversation(conversation)
+		if conversation.StartedBy == userID {
+			conversation.StartedBy = ""
+		}
+
+		conversation.Participants = without(conversation.Participants, userID)
+		delete(conversation.ReadAt, userID)
+		s.Store.conversations[id] = conversation
+	}
+
+	for id, application := range s.Store.applications {
+		if application.StudentID == student.ID {
+			delete(s.Store.applications, id)
+		}
+	}
+
+	for id, entry := range s.Store.shortlist {
+		if entry.StudentID == student.ID {
+			delete(s.Store.shortlist, id)
+		}
+	}
+
+	for key, block := range s.Store.blocks {
+		if block.StudentID == student.ID {
+			delete(s.Store.blocks, key)
+		}
+	}
+
+	for key, request := range s.Store.resumeRequests {
+		if request.StudentID == student.ID {
+			delete(s.Store.resumeRequests, key)
+		}
+	}
+
+	for id, mail := range s.Store.outbox {
+		if mail.To == email {
+			delete(s.Store.outbox, id)
+		}
+	}
+
+	delete(s.Store.students, student.ID)
+	s.Store.deleteUser(userID)
 	return nil
 }
 
 // FindByID ...
 func (s StudentRepo) FindByID(id string) (domain.Student, error) {
-	if student, ok := s.DB[id]; ok {
-		return student, nil
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	if student, ok := s.Store.students[id]; ok {
+		return s.Store.studentWithUser(student), nil
 	}
 
-	return domain.Student{}, errors.New("No student with id " + id)
+	return domain.Student{}, e.ErrorEntityNotFound
 }
 
 // FindAll ...
-func (s StudentRepo) FindAll() ([]domain.Student, error) {
+func (s StudentRepo) FindAll(page domain.Page) ([]domain.Student, string, error) {
+	return s.FindByQuery(domain.StudentQuery{}, page)
+}
+
+// FindByQuery ...
+func (s StudentRepo) FindByQuery(query domain.StudentQuery, page domain.Page) ([]domain.Student, string, error) {
+	s.Store.mu.RLock()
+	defer s.Store.mu.RUnlock()
+
+	// the visibility is checked here, as only the store
+	// knows the companies the student applied to
+	ids := []string{}
+	for id, student := range s.Store.students {
+		if student.Matches(query) && s.Store.isVisibleTo(student, query.VisibleTo) {
+			ids = append(ids, id)
+		}
+	}
+
+	ids, next := paginate(ids, page)
 	students := []domain.Student{}
-	for _, student := range s.DB {
-		students = append(students, student)
+	for _, id := range ids {
+		students = append(students, s.Store.studentWithUser(s.Store.students[id]))
+	}
+
+	return students, next, nil
+}
+
+// helper func that copies the student together with their user, leaving
+// out the password hash like postgres does. The caller holds the lock.
+func (s *Store) studentWithUser(student domain.Student) domain.Student {
+	user := s.users[student.User.ID]
+	student.User = domain.User{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      user.Role,
+	}
+
+	return cloneStudent(student)
+}
+
+// helper func that checks if representatives of the company may view the profile
+// of the student, which any company may if none is provided. The caller holds the lock.
+func (s *Store) isVisibleTo(student domain.Student, companyID string) bool {
+	if companyID == "" {
+		return true
+	}
+
+	switch student.Visibility {
+	case domain.VisibleToRepresentatives, "":
+		return true
+	case domain.VisibleToAppliedCompanies:
+		for _, application := range s.applications {
+			if application.StudentID == student.ID && s.projects[application.ProjectID].CompanyID == companyID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// helper func that deletes the user, together with their sessions
+// and tokens like postgres cascades. The caller holds the lock.
+func (s *Store) deleteUser(userID string) {
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+
+	for id, token := range s.userTokens {
+		if token.UserID == userID {
+			delete(s.userTokens, id)
+		}
+	}
+
+	delete(s.users, userID)
+}
+
+// helper func that returns a copy of the ids without the provided id
+func without(ids []string, id string) []string {
+	rest := []string{}
+	for _, other := range ids {
+		if other != id {
+			rest = append(rest, other)
+		}
 	}
 
-	return students, nil
+	return rest
 }
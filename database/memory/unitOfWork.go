@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"github.com/janabe/cscoupler/domain"
+)
+
+// UnitOfWork runs use cases on a copy of the tables of the store, which
+// replaces them when the use case succeeds. The store stays locked in the
+// meantime, so other repos of the store wait until the unit of work is done.
+type UnitOfWork struct {
+	Store *Store
+}
+
+// Do ...
+func (u UnitOfWork) Do(fn func(repos domain.Repositories) error) error {
+	u.Store.mu.Lock()
+	defer u.Store.mu.Unlock()
+
+	work := &Store{tables: u.Store.tables.clone()}
+	err := fn(NewRepositories(work))
+	if err != nil {
+		return err
+	}
+
+	u.Store.tables = work.tables
+	return nil
+}
+
+// NewRepositories creates all repositories on top of the store
+func NewRepositories(store *Store) domain.Repositories {
+	return domain.Repositories{
+		Users:           UserRepo{Store: store},
+		Students:        StudentRepo{Store: store},
+		Representatives: RepresentativeRepo{Store: store},
+		Companies:       CompanyRepo{Store: store},
+		Projects:        ProjectRepo{Store: store},
+		InviteLinks:     InviteLinkRepo{Store: store},
+		Conversations:   ConversationRepo{Store: store},
+		Messages:        MessageRepo{Store: store},
+		Blocks:          BlockRepo{Store: store},
+		Applications:    ApplicationRepo{Store: store},
+		Shortlist:       ShortlistRepo{Store: store},
+		ResumeRequests:  ResumeRequestRepo{Store: store},
+		Search:          SearchRepo{Store: store},
+		Sessions:        SessionRepo{Store: store},
+		UserTokens:      UserTokenRepo{Store: store},
+		Outbox:          OutboxRepo{Store: store},
+	}
+}
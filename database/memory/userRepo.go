@@ -1,38 +1,140 @@
 package memory
 
 import (
-	"errors"
-
 	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // UserRepo ...
 type UserRepo struct {
-	DB map[string]domain.User
+	Store *Store
 }
 
 // Create ...
 func (u UserRepo) Create(user domain.User) error {
-	u.DB[user.ID] = user
-	return nil
+	u.Store.mu.Lock()
+	defer u.Store.mu.Unlock()
+
+	return u.Store.createUser(user)
 }
 
 // FindByID ...
 func (u UserRepo) FindByID(id string) (domain.User, error) {
-	if user, ok := u.DB[id]; ok {
+	u.Store.mu.RLock()
+	defer u.Store.mu.RUnlock()
+
+	if user, ok := u.Store.users[id]; ok {
 		return user, nil
 	}
 
-	return domain.User{}, errors.New("No user with id " + id)
+	return domain.User{}, e.ErrorEntityNotFound
 }
 
 // FindByEmail ...
 func (u UserRepo) FindByEmail(email string) (domain.User, error) {
-	for _, user := range u.DB {
+	u.Store.mu.RLock()
+	defer u.Store.mu.RUnlock()
+
+	for _, user := range u.Store.users {
 		if user.Email == email {
 			return user, nil
 		}
 	}
 
-	return domain.User{}, errors.New("No user with email " + email)
+	return domain.User{}, e.ErrorEntityNotFound
+}
+
+// FindRoleID ...
+func (u UserRepo) FindRoleID(user domain.User) (string, error) {
+	u.Store.mu.RLock()
+	defer u.Store.mu.RUnlock()
+
+	switch user.Role {
+	case domain.StudentRole:
+		for _, student := range u.Store.students {
+			if student.User.ID == user.ID {
+				return student.ID, nil
+			}
+		}
+	case domain.RepresentativeRole:
+		// representatives that have been removed from their company no longer have a role
+		for _, repr := range u.Store.representatives {
+			if repr.User.ID == user.ID && !repr.IsRemoved() {
+				return repr.ID, nil
+			}
+		}
+	default:
+		return "", nil
+	}
+
+	return "", e.ErrorEntityNotFound
+}
+
+// UpdatePassword ...
+func (u UserRepo) UpdatePassword(userID, hashedPassword string) error {
+	u.Store.mu.Lock()
+	defer u.Store.mu.Unlock()
+
+	user, ok := u.Store.users[userID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	user.HashedPassword = hashedPassword
+	u.Store.users[userID] = user
+	return nil
+}
+
+// MarkEmailVerified ...
+func (u UserRepo) MarkEmailVerified(userID string) error {
+	u.Store.mu.Lock()
+	defer u.Store.mu.Unlock()
+
+	user, ok := u.Store.users[userID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	user.EmailVerified = true
+	u.Store.users[userID] = user
+	return nil
+}
+
+// helper func that stores the user, unless their id or email is
+// already in use, like the constraints in postgres. The caller holds the lock.
+func (s *Store) createUser(user domain.User) error {
+	if _, ok := s.users[user.ID]; ok {
+		return e.New(e.Conflict, "User with id "+user.ID+" already exists")
+	}
+
+	for _, other := range s.users {
+		if other.Email == user.Email {
+			return e.ErrorEmailAlreadyUsed
+		}
+	}
+
+	s.users[user.ID] = user
+	return nil
+}
+
+// helper func that updates the name and email of the user with the provided id,
+// their email has to be verified again when it changed. The caller holds the lock.
+func (s *Store) updateUser(userID string, user domain.User) error {
+	old, ok := s.users[userID]
+	if !ok {
+		return e.ErrorEntityNotFound
+	}
+
+	for _, other := range s.users {
+		if other.ID != userID && other.Email == user.Email {
+			return e.ErrorEmailAlreadyUsed
+		}
+	}
+
+	old.EmailVerified = old.EmailVerified && old.Email == user.Email
+	old.FirstName = user.FirstName
+	old.LastName = user.LastName
+	old.Email = user.Email
+	s.users[userID] = old
+	return nil
 }
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// UserTokenRepo ...
+type UserTokenRepo struct {
+	Store *Store
+}
+
+// Create ...
+func (u UserTokenRepo) Create(token domain.UserToken) error {
+	u.Store.mu.Lock()
+	defer u.Store.mu.Unlock()
+
+	for _, other := range u.Store.userTokens {
+		if other.ID == token.ID || other.TokenHash == token.TokenHash {
+			return e.New(e.Conflict, "user token with id: "+token.ID+" or its hash already exists")
+		}
+	}
+
+	u.Store.userTokens[token.ID] = token
+	return nil
+}
+
+// FindByHash ...
+func (u UserTokenRepo) FindByHash(tokenHash string) (domain.UserToken, error) {
+	u.Store.mu.RLock()
+	defer u.Store.mu.RUnlock()
+
+	for _, token := range u.Store.userTokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+
+	return domain.UserToken{}, e.ErrorEntityNotFound
+}
+
+// MarkUsed ...
+func (u UserTokenRepo) MarkUsed(id string) error {
+	u.Store.mu.Lock()
+	defer u.Store.mu.Unlock()
+
+	token, ok := u.Store.userTokens[id]
+	if !ok || token.Used {
+		return e.ErrorInvalidToken
+	}
+
+	token.Used = true
+	u.Store.userTokens[id] = token
+	return nil
+}
@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// ApplicationRepo struct for postgres database
+type ApplicationRepo struct {
+	DB     *sql.DB
+	Outbox OutboxRepo
+	work   *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (a ApplicationRepo) begin() (Tx, error) {
+	return begin(a.DB, a.work)
+}
+
+// Create inserts an application in the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (a ApplicationRepo) Create(application d.Application) error {
+	tx, err := a.begin()
+	if err != nil {
+		return err
+	}
+
+	err = a.CreateTx(tx, application)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByID finds an application in the DB based on id. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (a ApplicationRepo) FindByID(id string) (d.Application, error) {
+	tx, err := a.begin()
+	if err != nil {
+		return d.Application{}, err
+	}
+
+	const selectQuery = `SELECT a.application_id, a.ref_student, a.ref_project, a.motivation, a.resume,
+	a.status, a.created_at, a.updated_at FROM "Application" a WHERE a.application_id=$1;`
+	application, err := a.findOneTx(tx, selectQuery, id)
+	if err != nil {
+		return d.Application{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.Application{}, err
+	}
+
+	return application, nil
+}
+
+// FindByStudentAndProject finds the application of the student to the project. It should
+// be used as a single unit of work, as it has its own transaction inside.
+func (a ApplicationRepo) FindByStudentAndProject(studentID, projectID string) (d.Application, error) {
+	tx, err := a.begin()
+	if err != nil {
+		return d.Application{}, err
+	}
+
+	const selectQuery = `SELECT a.application_id, a.ref_student, a.ref_project, a.motivation, a.resume,
+	a.status, a.created_at, a.updated_at FROM "Application" a WHERE a.ref_student=$1 AND a.ref_project=$2;`
+	application, err := a.findOneTx(tx, selectQuery, studentID, projectID)
+	if err != nil {
+		return d.Application{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.Application{}, err
+	}
+
+	return application, nil
+}
+
+// FindByStudent finds all applications of the student, newest first. It should be
+// used as a single unit of work, as it has its own transaction inside.
+func (a ApplicationRepo) FindByStudent(studentID string) ([]d.Application, error) {
+	tx, err := a.begin()
+	if err != nil {
+		return []d.Application{}, err
+	}
+
+	const selectQuery = `SELECT a.application_id, a.ref_student, a.ref_project, a.motivation, a.resume,
+	a.status, a.created_at, a.updated_at FROM "Application" a WHERE a.ref_student=$1
+	ORDER BY a.created_at DESC;`
+	applications, err := a.findManyTx(tx, selectQuery, studentID)
+	if err != nil {
+		return []d.Application{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.Application{}, err
+	}
+
+	return applications, nil
+}
+
+// FindByProject finds all applications to the project, oldest first. It should be
+// used as a single unit of work, as it has its own transaction inside.
+func (a ApplicationRepo) FindByProject(projectID string) ([]d.Application, error) {
+	tx, err := a.begin()
+	if err != nil {
+		return []d.Application{}, err
+	}
+
+	const selectQuery = `SELECT a.application_id, a.ref_student, a.ref_project, a.motivation, a.resume,
+	a.status, a.created_at, a.updated_at FROM "Application" a WHERE a.ref_project=$1
+	ORDER BY a.created_at ASC;`
+	applications, err := a.findManyTx(tx, selectQuery, projectID)
+	if err != nil {
+		return []d.Application{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.Application{}, err
+	}
+
+	return applications, nil
+}
+
+// Update updates an application in the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (a ApplicationRepo) Update(application d.Application) error {
+	tx, err := a.begin()
+	if err != nil {
+		return err
+	}
+
+	err = a.UpdateTx(tx, application)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateWithMail updates an application in the DB and queues the mail
+// about it in the same transaction. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (a ApplicationRepo) UpdateWithMail(application d.Application, mail d.OutboxMail) error {
+	tx, err := a.begin()
+	if err != nil {
+		return err
+	}
+
+	err = a.UpdateTx(tx, application)
+	if err != nil {
+		return err
+	}
+
+	err = a.Outbox.EnqueueTx(tx, mail)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateTx inserts an application in the DB. It should be used as PART of a
+// unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (a ApplicationRepo) CreateTx(tx Tx, application d.Application) error {
+	const insertQuery = `INSERT INTO "Application"(application_id, ref_student, ref_project,
+	motivation, resume, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+	_, err := tx.Exec(insertQuery,
+		application.ID,
+		application.StudentID,
+		application.ProjectID,
+		application.Motivation,
+		application.Resume,
+		application.Status,
+		application.CreatedAt,
+		application.UpdatedAt,
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTx updates the status of an application in the DB. It should be used as PART of a
+// unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (a ApplicationRepo) UpdateTx(tx Tx, application d.Application) error {
+	const updateQuery = `UPDATE "Application" a SET status=$1, updated_at=$2
+	WHERE a.application_id=$3;`
+	_, err := tx.Exec(updateQuery, application.Status, application.UpdatedAt, application.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// helper func that runs the provided query, which selects a single application
+func (a ApplicationRepo) findOneTx(tx Tx, query string, args ...interface{}) (d.Application, error) {
+	application, err := scanApplication(tx.QueryRow(query, args...))
+	if err != nil {
+		_ = tx.Rollback()
+		return d.Application{}, notFound(err)
+	}
+
+	return application, nil
+}
+
+// helper func that runs the provided query, which selects a list of applications
+func (a ApplicationRepo) findManyTx(tx Tx, query string, args ...interface{}) ([]d.Application, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.Application{}, err
+	}
+	defer rows.Close()
+
+	applications := []d.Application{}
+	for rows.Next() {
+		application, err := scanApplication(rows)
+		if err != nil {
+			_ = tx.Rollback()
+			return []d.Application{}, err
+		}
+
+		applications = append(applications, application)
+	}
+
+	return applications, nil
+}
+
+// helper func to scan a single application row
+func scanApplication(row scanner) (d.Application, error) {
+	var aID, sID, pID, motivation, resume string
+	var status d.ApplicationStatus
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(&aID, &sID, &pID, &motivation, &resume, &status, &createdAt, &updatedAt)
+	if err != nil {
+		return d.Application{}, err
+	}
+
+	return d.Application{
+		ID:         aID,
+		StudentID:  sID,
+		ProjectID:  pID,
+		Motivation: motivation,
+		Resume:     resume,
+		Status:     status,
+		CreatedAt:  createdAt,
+		UpdatedAt:  updatedAt,
+	}, nil
+}
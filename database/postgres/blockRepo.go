@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// BlockRepo struct for postgres database
+type BlockRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (b BlockRepo) begin() (Tx, error) {
+	return begin(b.DB, b.work)
+}
+
+// Create inserts a block in the DB. Blocking an already blocked company
+// is a no-op. It should be used as a single unit of work, as it has its
+// own transaction inside.
+func (b BlockRepo) Create(block d.Block) error {
+	tx, err := b.begin()
+	if err != nil {
+		return err
+	}
+
+	const insertQuery = `INSERT INTO "Block"(ref_student, ref_company, created_at)
+	VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;`
+	_, err = tx.Exec(insertQuery, block.StudentID, block.CompanyID, block.CreatedAt)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes a block from the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (b BlockRepo) Delete(studentID, companyID string) error {
+	tx, err := b.begin()
+	if err != nil {
+		return err
+	}
+
+	const deleteQuery = `DELETE FROM "Block" WHERE ref_student=$1 AND ref_company=$2;`
+	_, err = tx.Exec(deleteQuery, studentID, companyID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Exists checks if the student has blocked the company. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (b BlockRepo) Exists(studentID, companyID string) (bool, error) {
+	tx, err := b.begin()
+	if err != nil {
+		return false, err
+	}
+
+	const selectQuery = `SELECT EXISTS(SELECT 1 FROM "Block" WHERE ref_student=$1 AND ref_company=$2);`
+	var exists bool
+	result := tx.QueryRow(selectQuery, studentID, companyID)
+	err = result.Scan(&exists)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// FindByStudent finds all blocks of the student. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (b BlockRepo) FindByStudent(studentID string) ([]d.Block, error) {
+	tx, err := b.begin()
+	if err != nil {
+		return []d.Block{}, err
+	}
+
+	const selectQuery = `SELECT ref_student, ref_company, created_at
+	FROM "Block" WHERE ref_student=$1 ORDER BY created_at DESC;`
+	rows, err := tx.Query(selectQuery, studentID)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.Block{}, err
+	}
+	defer rows.Close()
+
+	blocks := []d.Block{}
+	for rows.Next() {
+		var sID, cID string
+		var createdAt time.Time
+
+		if err := rows.Scan(&sID, &cID, &createdAt); err != nil {
+			_ = tx.Rollback()
+			return []d.Block{}, err
+		}
+
+		blocks = append(blocks, d.Block{
+			StudentID: sID,
+			CompanyID: cID,
+			CreatedAt: createdAt,
+		})
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.Block{}, err
+	}
+
+	return blocks, nil
+}
@@ -12,12 +12,18 @@ import (
 type CompanyRepo struct {
 	DB       *sql.DB
 	ReprRepo RepresentativeRepo
+	work     *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (c CompanyRepo) begin() (Tx, error) {
+	return begin(c.DB, c.work)
 }
 
 // Create inserts a company in the DB. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (c CompanyRepo) Create(company d.Company) error {
-	tx, err := c.DB.Begin()
+	tx, err := c.begin()
 	if err != nil {
 		return err
 	}
@@ -62,7 +68,7 @@ func (c CompanyRepo) Create(company d.Company) error {
 // FindByID finds a company in the DB based on id. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (c CompanyRepo) FindByID(id string) (d.Company, error) {
-	tx, err := c.DB.Begin()
+	tx, err := c.begin()
 	if err != nil {
 		return d.Company{}, err
 	}
@@ -83,7 +89,7 @@ func (c CompanyRepo) FindByID(id string) (d.Company, error) {
 // FindByName finds a company in the DB based on name. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (c CompanyRepo) FindByName(name string) (d.Company, error) {
-	tx, err := c.DB.Begin()
+	tx, err := c.begin()
 	if err != nil {
 		return d.Company{}, err
 	}
@@ -101,17 +107,22 @@ func (c CompanyRepo) FindByName(name string) (d.Company, error) {
 	return company, nil
 }
 
-// FindAll finds all companies in the DB. It should be used as a single
-// unit of work, as it has its own transaction inside.
-func (c CompanyRepo) FindAll() ([]d.Company, error) {
-	tx, err := c.DB.Begin()
+// FindAll finds a page of all companies in the DB, ordered on id. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (c CompanyRepo) FindAll(page d.Page) ([]d.Company, string, error) {
+	tx, err := c.begin()
 	if err != nil {
-		return []d.Company{}, err
+		return []d.Company{}, "", err
 	}
 
-	companies := []d.Company{}
-	const selectIDSQuery = `SELECT company_id FROM "Company";`
-	rows, err := tx.Query(selectIDSQuery)
+	// one company more than the limit is fetched to know if there is a next page
+	const selectIDSQuery = `SELECT company_id FROM "Company"
+	WHERE $1 = '' OR company_id::text > $1 ORDER BY company_id::text LIMIT $2;`
+	rows, err := tx.Query(selectIDSQuery, page.After, page.Limit+1)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.Company{}, "", err
+	}
 	defer rows.Close()
 
 	ids := []string{}
@@ -119,40 +130,47 @@ func (c CompanyRepo) FindAll() ([]d.Company, error) {
 		var id string
 		if err := rows.Scan(&id); err != nil {
 			_ = tx.Rollback()
-			return []d.Company{}, err
+			return []d.Company{}, "", err
 		}
 		ids = append(ids, id)
 	}
 
+	next := ""
+	if len(ids) > page.Limit {
+		ids = ids[:page.Limit]
+		next = ids[page.Limit-1]
+	}
+
 	// added the extra ids slice because it wasn't possible
 	// to execute a new query inside the 'select all ids' query.
+	companies := []d.Company{}
 	for _, id := range ids {
 		company, err := c.FindByIDTx(tx, id)
 		if err != nil {
-			return []d.Company{}, err
+			return []d.Company{}, "", err
 		}
 		companies = append(companies, company)
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return []d.Company{}, err
+		return []d.Company{}, "", err
 	}
 
-	return companies, nil
+	return companies, next, nil
 }
 
 // AddProject adds a project to the company in the db. It should be used as a
 // single unit of work, as it has its own transaction inside.
 func (c CompanyRepo) AddProject(p d.Project) error {
-	tx, err := c.DB.Begin()
+	tx, err := c.begin()
 	if err != nil {
 		return err
 	}
 
 	const insertQuery = `INSERT INTO "Project"(project_id, description, 
-	compensation, duration, recommendations, ref_company)
-	VALUES($1, $2, $3, $4, $5, $6);`
+	compensation, duration, recommendations, ref_company, ref_representative)
+	VALUES($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid);`
 
 	_, err = tx.Exec(insertQuery,
 		p.ID,
@@ -161,6 +179,7 @@ func (c CompanyRepo) AddProject(p d.Project) error {
 		p.Duration,
 		pq.Array(p.Recommendations),
 		p.CompanyID,
+		p.ContactID,
 	)
 
 	if err != nil {
@@ -179,7 +198,7 @@ func (c CompanyRepo) AddProject(p d.Project) error {
 // Update updates a company in the DB. It should be used as a single unit of work
 // as it has its own transaction inside.
 func (c CompanyRepo) Update(company d.Company) error {
-	tx, err := c.DB.Begin()
+	tx, err := c.begin()
 	if err != nil {
 		return err
 	}
@@ -201,7 +220,7 @@ func (c CompanyRepo) Update(company d.Company) error {
 // a unit of work, as a transaction gets passed in but will not be commited.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong.
-func (c CompanyRepo) UpdateTx(tx *sql.Tx, company d.Company) error {
+func (c CompanyRepo) UpdateTx(tx Tx, company d.Company) error {
 	const updateCompanyQuery = `UPDATE "Company" c
 	SET name=$1, description=$2, information=$3 WHERE c.company_id=$4;`
 
@@ -259,12 +278,12 @@ func (c CompanyRepo) UpdateTx(tx *sql.Tx, company d.Company) error {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (c CompanyRepo) FindByIDTx(tx *sql.Tx, id string) (d.Company, error) {
+func (c CompanyRepo) FindByIDTx(tx Tx, id string) (d.Company, error) {
 	var cID, info, cDescription, name string
 	var aID, street, zip, city, num string
-	var pID, desc, comp, dur string
+	var pID, desc, comp, dur, contactID string
 	var recommendations []string
-	var rID, jobTitle string
+	var rID, jobTitle, companyRole string
 	var uID, fname, lname, email, hash, role string
 
 	const selectCompanyQuery = `
@@ -278,21 +297,22 @@ func (c CompanyRepo) FindByIDTx(tx *sql.Tx, id string) (d.Company, error) {
 		WHERE ref_company = $1;
 	`
 	const selectProjectsQuery = `
-		SELECT p.project_id, p.description, p.compensation, p.duration, p.recommendations
+		SELECT p.project_id, p.description, p.compensation, p.duration, p.recommendations,
+		COALESCE(p.ref_representative::text, '')
 		FROM "Project" p
 		WHERE p.ref_company = $1;
 	`
 	const selectRepresentativesQuery = `
-		SELECT 	r.representative_id, r.job_title, u.user_id, u.first_name, u.last_name, u.email, u.hashed_password, u.role
+		SELECT 	r.representative_id, r.job_title, r.company_role, u.user_id, u.first_name, u.last_name, u.email, u.hashed_password, u.role
 		FROM "Representative" r
 		JOIN "User" u on r.ref_user = u.user_id
-		WHERE r.ref_company = $1;
+		WHERE r.ref_company = $1 AND r.removed_at IS NULL;
 	`
 	companyResult := tx.QueryRow(selectCompanyQuery, id)
 	err := companyResult.Scan(&cID, &cDescription, &info, &name)
 	if err != nil {
 		_ = tx.Rollback()
-		return d.Company{}, err
+		return d.Company{}, notFound(err)
 	}
 
 	addresses := []d.Address{}
@@ -326,7 +346,7 @@ func (c CompanyRepo) FindByIDTx(tx *sql.Tx, id string) (d.Company, error) {
 	defer projectRows.Close()
 
 	for projectRows.Next() {
-		if err = projectRows.Scan(&pID, &desc, &comp, &dur, pq.Array(&recommendations)); err != nil {
+		if err = projectRows.Scan(&pID, &desc, &comp, &dur, pq.Array(&recommendations), &contactID); err != nil {
 			_ = tx.Rollback()
 			return d.Company{}, err
 		}
@@ -337,6 +357,7 @@ func (c CompanyRepo) FindByIDTx(tx *sql.Tx, id string) (d.Company, error) {
 			Compensation:    comp,
 			Recommendations: recommendations,
 			CompanyID:       cID,
+			ContactID:       contactID,
 		})
 	}
 
@@ -349,14 +370,15 @@ func (c CompanyRepo) FindByIDTx(tx *sql.Tx, id string) (d.Company, error) {
 	defer reprRows.Close()
 
 	for reprRows.Next() {
-		if err = reprRows.Scan(&rID, &jobTitle, &uID, &fname, &lname, &email, &hash, &role); err != nil {
+		if err = reprRows.Scan(&rID, &jobTitle, &companyRole, &uID, &fname, &lname, &email, &hash, &role); err != nil {
 			_ = tx.Rollback()
 			return d.Company{}, err
 		}
 		representatives = append(representatives, d.Representative{
-			ID:        rID,
-			JobTitle:  jobTitle,
-			CompanyID: cID,
+			ID:          rID,
+			JobTitle:    jobTitle,
+			CompanyID:   cID,
+			CompanyRole: d.CompanyRole(companyRole),
 			User: d.User{
 				ID:             uID,
 				FirstName:      fname,
@@ -383,12 +405,12 @@ func (c CompanyRepo) FindByIDTx(tx *sql.Tx, id string) (d.Company, error) {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (c CompanyRepo) FindByNameTx(tx *sql.Tx, name string) (d.Company, error) {
+func (c CompanyRepo) FindByNameTx(tx Tx, name string) (d.Company, error) {
 	var cID, info, cName string
 	var street, zip, city, num string
-	var pID, desc, comp, dur string
+	var pID, desc, comp, dur, contactID string
 	var recommendations []string
-	var rID, jobTitle string
+	var rID, jobTitle, companyRole string
 	var uID, fname, lname, email, hash, role string
 
 	const selectCompanyQuery = `
@@ -402,21 +424,22 @@ func (c CompanyRepo) FindByNameTx(tx *sql.Tx, name string) (d.Company, error) {
 		WHERE a.ref_company = $1;
 	`
 	const selectProjectsQuery = `
-		SELECT p.project_id, p.description, p.compensation, p.duration, p.recommendations
+		SELECT p.project_id, p.description, p.compensation, p.duration, p.recommendations,
+		COALESCE(p.ref_representative::text, '')
 		FROM "Project" p
 		WHERE p.ref_company = $1;
 	`
 	const selectRepresentativesQuery = `
-		SELECT 	r.representative_id, r.job_title, u.user_id, u.first_name, u.last_name, u.email, u.hashed_password, u.role
+		SELECT 	r.representative_id, r.job_title, r.company_role, u.user_id, u.first_name, u.last_name, u.email, u.hashed_password, u.role
 		FROM "Representative" r
 		JOIN "User" u on r.ref_user = u.user_id
-		WHERE r.ref_company = $1;
+		WHERE r.ref_company = $1 AND r.removed_at IS NULL;
 	`
 	companyResult := tx.QueryRow(selectCompanyQuery, name)
 	err := companyResult.Scan(&cID, &info, &cName)
 	if err != nil {
 		_ = tx.Rollback()
-		return d.Company{}, err
+		return d.Company{}, notFound(err)
 	}
 
 	addresses := []d.Address{}
@@ -449,7 +472,7 @@ func (c CompanyRepo) FindByNameTx(tx *sql.Tx, name string) (d.Company, error) {
 	defer projectRows.Close()
 
 	for projectRows.Next() {
-		if err = projectRows.Scan(&pID, &desc, &comp, &dur, pq.Array(&recommendations)); err != nil {
+		if err = projectRows.Scan(&pID, &desc, &comp, &dur, pq.Array(&recommendations), &contactID); err != nil {
 			_ = tx.Rollback()
 			return d.Company{}, err
 		}
@@ -460,6 +483,7 @@ func (c CompanyRepo) FindByNameTx(tx *sql.Tx, name string) (d.Company, error) {
 			Compensation:    comp,
 			Recommendations: recommendations,
 			CompanyID:       cID,
+			ContactID:       contactID,
 		})
 	}
 
@@ -472,14 +496,15 @@ func (c CompanyRepo) FindByNameTx(tx *sql.Tx, name string) (d.Company, error) {
 	defer reprRows.Close()
 
 	for reprRows.Next() {
-		if err = reprRows.Scan(&rID, &jobTitle, &uID, &fname, &lname, &email, &hash, &role); err != nil {
+		if err = reprRows.Scan(&rID, &jobTitle, &companyRole, &uID, &fname, &lname, &email, &hash, &role); err != nil {
 			_ = tx.Rollback()
 			return d.Company{}, err
 		}
 		representatives = append(representatives, d.Representative{
-			ID:        rID,
-			JobTitle:  jobTitle,
-			CompanyID: cID,
+			ID:          rID,
+			JobTitle:    jobTitle,
+			CompanyID:   cID,
+			CompanyRole: d.CompanyRole(companyRole),
 			User: d.User{
 				ID:             uID,
 				FirstName:      fname,
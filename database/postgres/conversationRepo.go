@@ -0,0 +1,393 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// ConversationRepo struct for postgres database
+type ConversationRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (c ConversationRepo) begin() (Tx, error) {
+	return begin(c.DB, c.work)
+}
+
+// Create inserts a conversation and its participants in the DB. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (c ConversationRepo) Create(conversation d.Conversation) error {
+	tx, err := c.begin()
+	if err != nil {
+		return err
+	}
+
+	err = c.CreateTx(tx, conversation)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByID finds a conversation in the DB based on id. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (c ConversationRepo) FindByID(id string) (d.Conversation, error) {
+	tx, err := c.begin()
+	if err != nil {
+		return d.Conversation{}, err
+	}
+
+	conversation, err := c.FindByIDTx(tx, id)
+	if err != nil {
+		return d.Conversation{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.Conversation{}, err
+	}
+
+	return conversation, nil
+}
+
+// FindBetween finds the conversation between two users about the provided project.
+// It should be used as a single unit of work, as it has its own transaction inside.
+func (c ConversationRepo) FindBetween(userID, otherUserID, projectID string) (d.Conversation, error) {
+	tx, err := c.begin()
+	if err != nil {
+		return d.Conversation{}, err
+	}
+
+	conversation, err := c.FindBetweenTx(tx, userID, otherUserID, projectID)
+	if err != nil {
+		return d.Conversation{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.Conversation{}, err
+	}
+
+	return conversation, nil
+}
+
+// FindByParticipant finds all conversations the user is part of. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (c ConversationRepo) FindByParticipant(userID string) ([]d.Conversation, error) {
+	tx, err := c.begin()
+	if err != nil {
+		return []d.Conversation{}, err
+	}
+
+	conversations, err := c.FindByParticipantTx(tx, userID)
+	if err != nil {
+		return []d.Conversation{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.Conversation{}, err
+	}
+
+	return conversations, nil
+}
+
+// MarkRead marks the conversation as read by the user at the provided moment. It
+// should be used as a single unit of work, as it has its own transaction inside.
+func (c ConversationRepo) MarkRead(conversationID, userID string, at time.Time) error {
+	tx, err := c.begin()
+	if err != nil {
+		return err
+	}
+
+	err = c.MarkReadTx(tx, conversationID, userID, at)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CountStartedBy counts the conversations started by any of the users since the provided
+// moment. It should be used as a single unit of work, as it has its own transaction inside.
+func (c ConversationRepo) CountStartedBy(userIDs []string, since time.Time) (int, error) {
+	tx, err := c.begin()
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := c.CountStartedByTx(tx, userIDs, since)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CreateTx inserts a conversation and its participants in the DB. It should be used
+// as PART of a unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (c ConversationRepo) CreateTx(tx Tx, conversation d.Conversation) error {
+	const insertConversationQuery = `INSERT INTO "Conversation"(conversation_id, created_at, last_activity, started_by, ref_project)
+	VALUES ($1, $2, $3, $4, $5);`
+	_, err := tx.Exec(insertConversationQuery,
+		conversation.ID,
+		conversation.CreatedAt,
+		conversation.LastActivity,
+		conversation.StartedBy,
+		sql.NullString{String: conversation.ProjectID, Valid: conversation.ProjectID != ""},
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	const insertParticipantQuery = `INSERT INTO "Conversation_Participant"(ref_conversation, ref_user, read_at)
+	VALUES ($1, $2, $3);`
+	for _, p := range conversation.Participants {
+		readAt, hasRead := conversation.ReadAt[p]
+		_, err = tx.Exec(insertParticipantQuery,
+			conversation.ID,
+			p,
+			pq.NullTime{Time: readAt, Valid: hasRead},
+		)
+
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindByIDTx finds a conversation in the DB based on id. It should be used as PART of a
+// unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (c ConversationRepo) FindByIDTx(tx Tx, id string) (d.Conversation, error) {
+	const selectQuery = `SELECT c.conversation_id, c.created_at, c.last_activity, c.started_by, c.ref_project
+	FROM "Conversation" c WHERE c.conversation_id=$1;`
+
+	var cID string
+	var startedBy, projectID sql.NullString
+	var createdAt, lastActivity time.Time
+
+	result := tx.QueryRow(selectQuery, id)
+	err := result.Scan(&cID, &createdAt, &lastActivity, &startedBy, &projectID)
+	if err != nil {
+		_ = tx.Rollback()
+		return d.Conversation{}, notFound(err)
+	}
+
+	conversations := []d.Conversation{{
+		ID:           cID,
+		ProjectID:    projectID.String,
+		StartedBy:    startedBy.String,
+		CreatedAt:    createdAt,
+		LastActivity: lastActivity,
+	}}
+
+	err = c.findParticipantsTx(tx, conversations)
+	if err != nil {
+		return d.Conversation{}, err
+	}
+
+	return conversations[0], nil
+}
+
+// FindBetweenTx finds the conversation between two users about the provided project.
+// It should be used as PART of a unit of work, as a transaction gets passed in but will
+// not be committed. This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (c ConversationRepo) FindBetweenTx(tx Tx, userID, otherUserID, projectID string) (d.Conversation, error) {
+	const selectQuery = `SELECT c.conversation_id
+	FROM "Conversation" c
+	JOIN "Conversation_Participant" a ON a.ref_conversation = c.conversation_id AND a.ref_user = $1
+	JOIN "Conversation_Participant" b ON b.ref_conversation = c.conversation_id AND b.ref_user = $2
+	WHERE c.ref_project IS NOT DISTINCT FROM $3;`
+
+	var cID string
+	result := tx.QueryRow(selectQuery,
+		userID,
+		otherUserID,
+		sql.NullString{String: projectID, Valid: projectID != ""},
+	)
+
+	err := result.Scan(&cID)
+	if err != nil {
+		_ = tx.Rollback()
+		return d.Conversation{}, notFound(err)
+	}
+
+	return c.FindByIDTx(tx, cID)
+}
+
+// FindByParticipantTx finds all conversations the user is part of, ordered from most
+// to least recent activity, including the amount of messages the user has not read yet.
+// It should be used as PART of a unit of work, as a transaction gets passed in but will
+// not be committed. This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (c ConversationRepo) FindByParticipantTx(tx Tx, userID string) ([]d.Conversation, error) {
+	const selectQuery = `SELECT c.conversation_id, c.created_at, c.last_activity, c.started_by, c.ref_project,
+	(
+		SELECT count(*) FROM "Message" m
+		WHERE m.ref_conversation = c.conversation_id
+		AND m.sender IS DISTINCT FROM p.ref_user
+		AND m.created_at > COALESCE(p.read_at, '-infinity')
+	) AS unread
+	FROM "Conversation" c
+	JOIN "Conversation_Participant" p ON p.ref_conversation = c.conversation_id
+	WHERE p.ref_user = $1
+	ORDER BY c.last_activity DESC;`
+
+	rows, err := tx.Query(selectQuery, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.Conversation{}, err
+	}
+	defer rows.Close()
+
+	conversations := []d.Conversation{}
+	for rows.Next() {
+		var cID string
+		var startedBy, projectID sql.NullString
+		var createdAt, lastActivity time.Time
+		var unread int
+
+		if err := rows.Scan(&cID, &createdAt, &lastActivity, &startedBy, &projectID, &unread); err != nil {
+			_ = tx.Rollback()
+			return []d.Conversation{}, err
+		}
+
+		conversations = append(conversations, d.Conversation{
+			ID:           cID,
+			ProjectID:    projectID.String,
+			StartedBy:    startedBy.String,
+			CreatedAt:    createdAt,
+			LastActivity: lastActivity,
+			Unread:       unread,
+		})
+	}
+
+	err = c.findParticipantsTx(tx, conversations)
+	if err != nil {
+		return []d.Conversation{}, err
+	}
+
+	return conversations, nil
+}
+
+// MarkReadTx marks the conversation as read by the user at the provided moment. It should
+// be used as PART of a unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (c ConversationRepo) MarkReadTx(tx Tx, conversationID, userID string, at time.Time) error {
+	const updateQuery = `UPDATE "Conversation_Participant" p SET read_at=$1
+	WHERE p.ref_conversation=$2 AND p.ref_user=$3;`
+	_, err := tx.Exec(updateQuery, at, conversationID, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// CountStartedByTx counts the conversations started by any of the users since the provided
+// moment. The users are locked until the transaction ends, so units of work that count
+// conversations of the same users before starting one wait for each other. It should be used
+// as PART of a unit of work, as a transaction gets passed in but will not be committed. This
+// is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (c ConversationRepo) CountStartedByTx(tx Tx, userIDs []string, since time.Time) (int, error) {
+	// locked in the same order every time, so units of work can't deadlock
+	const lockQuery = `SELECT user_id FROM "User" WHERE user_id = ANY($1) ORDER BY user_id FOR UPDATE;`
+	_, err := tx.Exec(lockQuery, pq.Array(userIDs))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	const countQuery = `SELECT count(*) FROM "Conversation" c
+	WHERE c.started_by = ANY($1) AND c.created_at >= $2;`
+
+	var count int
+	result := tx.QueryRow(countQuery, pq.Array(userIDs), since)
+	err = result.Scan(&count)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// helper func that fills in the participants and their read markers
+// of the provided conversations, using a single query
+func (c ConversationRepo) findParticipantsTx(tx Tx, conversations []d.Conversation) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	ids := []string{}
+	index := map[string]int{}
+	for i, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+		index[conversation.ID] = i
+		conversations[i].Participants = []string{}
+		conversations[i].ReadAt = map[string]time.Time{}
+	}
+
+	const selectQuery = `SELECT p.ref_conversation, p.ref_user, p.read_at
+	FROM "Conversation_Participant" p WHERE p.ref_conversation = ANY($1);`
+
+	rows, err := tx.Query(selectQuery, pq.Array(ids))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cID, uID string
+		var readAt pq.NullTime
+
+		if err := rows.Scan(&cID, &uID, &readAt); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		i := index[cID]
+		conversations[i].Participants = append(conversations[i].Participants, uID)
+		if readAt.Valid {
+			conversations[i].ReadAt[uID] = readAt.Time
+		}
+	}
+
+	return nil
+}
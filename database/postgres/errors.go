@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// invalidTextRepresentation is the code of the postgres error
+// returned when e.g. an id isn't a valid uuid
+const invalidTextRepresentation = "22P02"
+
+// helper func that reports a violated unique constraint as the provided conflict
+func conflict(err error, target error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return target
+	}
+
+	return err
+}
+
+// helper func that reports a row that doesn't exist as e.ErrorEntityNotFound.
+// Rows can't exist either when the id they are looked up by isn't a valid uuid.
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return e.ErrorEntityNotFound
+	}
+
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == invalidTextRepresentation {
+		return e.ErrorEntityNotFound
+	}
+
+	return err
+}
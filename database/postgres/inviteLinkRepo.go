@@ -4,18 +4,50 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/lib/pq"
+
 	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // InviteLinkRepo struct for postgres database
 type InviteLinkRepo struct {
-	DB *sql.DB
+	DB     *sql.DB
+	Outbox OutboxRepo
+	work   *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (i InviteLinkRepo) begin() (Tx, error) {
+	return begin(i.DB, i.work)
 }
 
 // Create inserts an InviteLink in the DB. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (i InviteLinkRepo) Create(inviteLink d.InviteLink) error {
-	tx, err := i.DB.Begin()
+	tx, err := i.begin()
+	if err != nil {
+		return err
+	}
+
+	err = i.CreateTx(tx, inviteLink)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateWithMail inserts an InviteLink in the DB and queues the mail
+// about it in the same transaction. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (i InviteLinkRepo) CreateWithMail(inviteLink d.InviteLink, mail d.OutboxMail) error {
+	tx, err := i.begin()
 	if err != nil {
 		return err
 	}
@@ -25,6 +57,11 @@ func (i InviteLinkRepo) Create(inviteLink d.InviteLink) error {
 		return err
 	}
 
+	err = i.Outbox.EnqueueTx(tx, mail)
+	if err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -36,7 +73,7 @@ func (i InviteLinkRepo) Create(inviteLink d.InviteLink) error {
 // FindByID finds an InviteLink in the DB based on id. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (i InviteLinkRepo) FindByID(id string) (d.InviteLink, error) {
-	tx, err := i.DB.Begin()
+	tx, err := i.begin()
 	if err != nil {
 		return d.InviteLink{}, err
 	}
@@ -54,15 +91,15 @@ func (i InviteLinkRepo) FindByID(id string) (d.InviteLink, error) {
 	return inviteLink, nil
 }
 
-// FindByCreator finds all inviteLinks in the DB that are created by the provided
-// representativeID.
-func (i InviteLinkRepo) FindByCreator(representativeID string) ([]d.InviteLink, error) {
-	tx, err := i.DB.Begin()
+// FindByCreator finds all inviteLinks in the DB that are created by the user (student
+// or representative) with the provided id.
+func (i InviteLinkRepo) FindByCreator(creatorID string) ([]d.InviteLink, error) {
+	tx, err := i.begin()
 	if err != nil {
 		return nil, err
 	}
 
-	invitations, err := i.FindByCreatorTx(tx, representativeID)
+	invitations, err := i.FindByCreatorTx(tx, creatorID)
 	if err != nil {
 		return []d.InviteLink{}, err
 	}
@@ -75,15 +112,110 @@ func (i InviteLinkRepo) FindByCreator(representativeID string) ([]d.InviteLink,
 	return invitations, nil
 }
 
-// Update updates an InviteLink in the DB. It should be used as a single
-// unit of work, as it has its own transaction inside.
-func (i InviteLinkRepo) Update(inviteLink d.InviteLink) error {
-	tx, err := i.DB.Begin()
+// Redeem records a use of an InviteLink in the DB, returning e.ErrorInviteNotPending if it
+// has already been used up, revoked or has expired. It should be used as a single unit of
+// work, as it has its own transaction inside.
+func (i InviteLinkRepo) Redeem(id string, at time.Time) error {
+	tx, err := i.begin()
 	if err != nil {
 		return err
 	}
 
-	err = i.UpdateTx(tx, inviteLink)
+	// the checks make sure concurrent signups can't use an invitelink more often than allowed
+	const updateQuery = `UPDATE "Invite_Link" SET uses=uses+1
+	WHERE invite_link_id=$1 AND uses < max_uses AND revoked_at IS NULL AND expiry_date > $2;`
+	result, err := tx.Exec(updateQuery, id, at)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		_ = tx.Rollback()
+		return e.ErrorInviteNotPending
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Revoke marks an InviteLink in the DB as revoked, returning e.ErrorInviteNotPending
+// if it has already been used up or revoked. It should be used as a single unit of work, as
+// it has its own transaction inside.
+func (i InviteLinkRepo) Revoke(id string, at time.Time) error {
+	tx, err := i.begin()
+	if err != nil {
+		return err
+	}
+
+	// the checks make sure an invitelink can't be revoked while it is being used
+	const updateQuery = `UPDATE "Invite_Link" SET revoked_at=$1
+	WHERE invite_link_id=$2 AND uses < max_uses AND revoked_at IS NULL;`
+	result, err := tx.Exec(updateQuery, at, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		_ = tx.Rollback()
+		return e.ErrorInviteNotPending
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenewWithMail stores the new expiry date of an InviteLink in the DB and queues the
+// mail sending it again in the same transaction, returning e.ErrorInviteNotPending
+// if it has already been used up or revoked. It should be used as a single unit of work,
+// as it has its own transaction inside.
+func (i InviteLinkRepo) RenewWithMail(inviteLink d.InviteLink, mail d.OutboxMail) error {
+	tx, err := i.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "Invite_Link" SET expiry_date=$1
+	WHERE invite_link_id=$2 AND uses < max_uses AND revoked_at IS NULL;`
+	result, err := tx.Exec(updateQuery, inviteLink.ExpiryDate, inviteLink.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		_ = tx.Rollback()
+		return e.ErrorInviteNotPending
+	}
+
+	err = i.Outbox.EnqueueTx(tx, mail)
 	if err != nil {
 		return err
 	}
@@ -100,16 +232,23 @@ func (i InviteLinkRepo) Update(inviteLink d.InviteLink) error {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (i InviteLinkRepo) CreateTx(tx *sql.Tx, inviteLink d.InviteLink) error {
-	const insertQuery = `INSERT INTO "Invite_Link"(invite_link_id, url, created_at, expiry_date, used, ref_representative)
-	VALUES($1, $2, $3, $4, $5, $6);`
+func (i InviteLinkRepo) CreateTx(tx Tx, inviteLink d.InviteLink) error {
+	const insertQuery = `INSERT INTO "Invite_Link"(invite_link_id, kind, target_id, role, url, email,
+	created_at, expiry_date, max_uses, uses, created_by, revoked_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`
 	_, err := tx.Exec(insertQuery,
 		inviteLink.ID,
+		inviteLink.Kind,
+		inviteLink.TargetID,
+		inviteLink.Role,
 		inviteLink.URL,
+		inviteLink.Email,
 		inviteLink.CreatedAt,
 		inviteLink.ExpiryDate,
-		inviteLink.Used,
+		inviteLink.MaxUses,
+		inviteLink.Uses,
 		inviteLink.CreatedBy,
+		pq.NullTime{Time: inviteLink.RevokedAt, Valid: !inviteLink.RevokedAt.IsZero()},
 	)
 
 	if err != nil {
@@ -124,39 +263,28 @@ func (i InviteLinkRepo) CreateTx(tx *sql.Tx, inviteLink d.InviteLink) error {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (i InviteLinkRepo) FindByIDTx(tx *sql.Tx, id string) (d.InviteLink, error) {
-	var iID, url, createdBy string
-	var createdAt, expiryDate time.Time
-	var used bool
-	const selectQuery = `SELECT i.invite_link_id, i.url, i.created_at, i.expiry_date, i.used, i.ref_representative
-	FROM "Invite_Link" i WHERE i.invite_link_id=$1;`
-	result := tx.QueryRow(selectQuery, id)
-
-	err := result.Scan(&iID, &url, &createdAt, &expiryDate, &used, &createdBy)
+func (i InviteLinkRepo) FindByIDTx(tx Tx, id string) (d.InviteLink, error) {
+	const selectQuery = `SELECT i.invite_link_id, i.kind, i.target_id, i.role, i.url, i.email, i.created_at,
+	i.expiry_date, i.max_uses, i.uses, i.created_by, i.revoked_at FROM "Invite_Link" i WHERE i.invite_link_id=$1;`
+	inviteLink, err := scanInviteLink(tx.QueryRow(selectQuery, id))
 	if err != nil {
 		_ = tx.Rollback()
-		return d.InviteLink{}, err
+		return d.InviteLink{}, notFound(err)
 	}
 
-	return d.InviteLink{
-		ID:         iID,
-		CreatedAt:  createdAt,
-		ExpiryDate: expiryDate,
-		URL:        url,
-		Used:       used,
-		CreatedBy:  createdBy,
-	}, nil
+	return inviteLink, nil
 }
 
-// FindByCreatorTx finds all inviteLinks in the DB that are created by the representative with the provided
-// representativeID. It should be used as PART of a unit of work, as a transaction gets passed in but will not be committed.
+// FindByCreatorTx finds all inviteLinks in the DB that are created by the user with the provided
+// creatorID. It should be used as PART of a unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong.
-func (i InviteLinkRepo) FindByCreatorTx(tx *sql.Tx, representativeID string) ([]d.InviteLink, error) {
-	const selectQuery = `SELECT i.invite_link_id, i.url, i.created_at, i.expiry_date, i.used, i.ref_representative
-	FROM "Invite_Link" i WHERE i.ref_representative=$1;`
+func (i InviteLinkRepo) FindByCreatorTx(tx Tx, creatorID string) ([]d.InviteLink, error) {
+	const selectQuery = `SELECT i.invite_link_id, i.kind, i.target_id, i.role, i.url, i.email, i.created_at,
+	i.expiry_date, i.max_uses, i.uses, i.created_by, i.revoked_at FROM "Invite_Link" i WHERE i.created_by=$1
+	ORDER BY i.created_at DESC;`
 
-	rows, err := tx.Query(selectQuery, representativeID)
+	rows, err := tx.Query(selectQuery, creatorID)
 	if err != nil {
 		_ = tx.Rollback()
 		return []d.InviteLink{}, err
@@ -165,40 +293,43 @@ func (i InviteLinkRepo) FindByCreatorTx(tx *sql.Tx, representativeID string) ([]
 
 	invitations := []d.InviteLink{}
 	for rows.Next() {
-		var iID, url, createdBy string
-		var createdAt, expiryDate time.Time
-		var used bool
-
-		if err := rows.Scan(&iID, &url, &createdAt, &expiryDate, &used, &createdBy); err != nil {
+		inviteLink, err := scanInviteLink(rows)
+		if err != nil {
 			_ = tx.Rollback()
 			return []d.InviteLink{}, err
 		}
 
-		invitations = append(invitations, d.InviteLink{
-			ID:         iID,
-			URL:        url,
-			CreatedBy:  createdBy,
-			CreatedAt:  createdAt,
-			Used:       used,
-			ExpiryDate: expiryDate,
-		})
+		invitations = append(invitations, inviteLink)
 	}
 
 	return invitations, nil
 }
 
-// UpdateTx updates an InviteLink in the DB. It should be used as PART of a
-// unit of work, as a transaction gets passed in but will not be committed.
-// This is the responsibility of the caller.
-// It will rollback and return an error if something goes wrong
-func (i InviteLinkRepo) UpdateTx(tx *sql.Tx, inviteLink d.InviteLink) error {
-	// maybe extend so the expiry date can be postponed?
-	const updateQuery = `UPDATE "Invite_Link" i SET used=$1 WHERE i.invite_link_id=$2;`
-	_, err := tx.Exec(updateQuery, inviteLink.Used, inviteLink.ID)
+// helper func to scan a single invitelink row
+func scanInviteLink(row scanner) (d.InviteLink, error) {
+	var iID, kind, targetID, role, url, email, createdBy string
+	var createdAt, expiryDate time.Time
+	var maxUses, uses int
+	var revokedAt pq.NullTime
+
+	err := row.Scan(&iID, &kind, &targetID, &role, &url, &email, &createdAt,
+		&expiryDate, &maxUses, &uses, &createdBy, &revokedAt)
 	if err != nil {
-		_ = tx.Rollback()
-		return err
+		return d.InviteLink{}, err
 	}
 
-	return nil
+	return d.InviteLink{
+		ID:         iID,
+		Kind:       d.InviteKind(kind),
+		TargetID:   targetID,
+		Role:       role,
+		URL:        url,
+		Email:      email,
+		CreatedAt:  createdAt,
+		ExpiryDate: expiryDate,
+		MaxUses:    maxUses,
+		Uses:       uses,
+		CreatedBy:  createdBy,
+		RevokedAt:  revokedAt.Time,
+	}, nil
 }
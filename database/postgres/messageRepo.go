@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// MessageRepo struct for postgres database
+type MessageRepo struct {
+	DB     *sql.DB
+	Outbox OutboxRepo
+	work   *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (m MessageRepo) begin() (Tx, error) {
+	return begin(m.DB, m.work)
+}
+
+// Create inserts a message in the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (m MessageRepo) Create(message d.Message) error {
+	tx, err := m.begin()
+	if err != nil {
+		return err
+	}
+
+	err = m.CreateTx(tx, message)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateWithMail inserts a message in the DB and queues the mail
+// about it in the same transaction. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (m MessageRepo) CreateWithMail(message d.Message, mail d.OutboxMail) error {
+	tx, err := m.begin()
+	if err != nil {
+		return err
+	}
+
+	err = m.CreateTx(tx, message)
+	if err != nil {
+		return err
+	}
+
+	err = m.Outbox.EnqueueTx(tx, mail)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByID finds a message in the DB based on id. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (m MessageRepo) FindByID(id string) (d.Message, error) {
+	tx, err := m.begin()
+	if err != nil {
+		return d.Message{}, err
+	}
+
+	message, err := m.FindByIDTx(tx, id)
+	if err != nil {
+		return d.Message{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.Message{}, err
+	}
+
+	return message, nil
+}
+
+// FindByConversation finds all messages of the conversation. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (m MessageRepo) FindByConversation(conversationID string) ([]d.Message, error) {
+	tx, err := m.begin()
+	if err != nil {
+		return []d.Message{}, err
+	}
+
+	messages, err := m.FindByConversationTx(tx, conversationID)
+	if err != nil {
+		return []d.Message{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.Message{}, err
+	}
+
+	return messages, nil
+}
+
+// CreateTx inserts a message in the DB and updates the last activity of its
+// conversation. It should be used as PART of a unit of work, as a transaction
+// gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (m MessageRepo) CreateTx(tx Tx, message d.Message) error {
+	const insertQuery = `INSERT INTO "Message"(message_id, ref_conversation, created_at, sender, receiver, body, ref_project)
+	VALUES ($1, $2, $3, $4, $5, $6, $7);`
+	_, err := tx.Exec(insertQuery,
+		message.ID,
+		message.ConversationID,
+		message.CreatedAt,
+		message.Sender,
+		message.Receiver,
+		message.Body,
+		sql.NullString{String: message.ProjectID, Valid: message.ProjectID != ""},
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	const updateConversationQuery = `UPDATE "Conversation" c SET last_activity=$1 WHERE c.conversation_id=$2;`
+	_, err = tx.Exec(updateConversationQuery, message.CreatedAt, message.ConversationID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// FindByIDTx finds a message in the DB based on id. It should be used as PART of a
+// unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (m MessageRepo) FindByIDTx(tx Tx, id string) (d.Message, error) {
+	const selectQuery = `SELECT m.message_id, m.ref_conversation, m.created_at, m.sender, m.receiver, m.body, m.ref_project
+	FROM "Message" m WHERE m.message_id=$1;`
+	result := tx.QueryRow(selectQuery, id)
+
+	message, err := scanMessage(result)
+	if err != nil {
+		_ = tx.Rollback()
+		return d.Message{}, notFound(err)
+	}
+
+	return message, nil
+}
+
+// FindByConversationTx finds all messages of the conversation, ordered from oldest
+// to newest. It should be used as PART of a unit of work, as a transaction gets passed
+// in but will not be committed. This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (m MessageRepo) FindByConversationTx(tx Tx, conversationID string) ([]d.Message, error) {
+	const selectQuery = `SELECT m.message_id, m.ref_conversation, m.created_at, m.sender, m.receiver, m.body, m.ref_project
+	FROM "Message" m
+	WHERE m.ref_conversation=$1
+	ORDER BY m.created_at ASC;`
+
+	rows, err := tx.Query(selectQuery, conversationID)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.Message{}, err
+	}
+	defer rows.Close()
+
+	messages := []d.Message{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			_ = tx.Rollback()
+			return []d.Message{}, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// helper func to scan a single message row
+func scanMessage(row scanner) (d.Message, error) {
+	var mID, cID, body string
+	var sender, receiver, projectID sql.NullString
+	var createdAt time.Time
+
+	err := row.Scan(&mID, &cID, &createdAt, &sender, &receiver, &body, &projectID)
+	if err != nil {
+		return d.Message{}, err
+	}
+
+	return d.Message{
+		ID:             mID,
+		ConversationID: cID,
+		Sender:         sender.String,
+		Receiver:       receiver.String,
+		Body:           body,
+		ProjectID:      projectID.String,
+		CreatedAt:      createdAt,
+	}, nil
+}
@@ -0,0 +1,62 @@
+//go:build ignore
+// +build ignore
+
+// This program embeds the sql files of the migrations in files.go,
+// so the binary can migrate the database without them on disk.
+// It is run by go generate.
+
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	paths, err := filepath.Glob(filepath.Join("sql", "*.sql"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go run gen.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package migrations\n\n")
+	buf.WriteString("// files holds the contents of the sql files of the migrations, keyed by file name\n")
+	buf.WriteString("var files = map[string]string{\n")
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		buf.WriteString(strconv.Quote(filepath.Base(path)) + ": " + quote(string(content)) + ",\n")
+	}
+	buf.WriteString("}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile("files.go", source, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// quote returns the content as raw string literal, so the sql stays
+// readable in files.go, unless it contains a backquote
+func quote(content string) string {
+	if strings.Contains(content, "`") {
+		return strconv.Quote(content)
+	}
+
+	return "`" + content + "`"
+}
@@ -0,0 +1,346 @@
+// Package migrations versions the schema of the postgres database. Every
+// migration is a numbered pair of sql files in the sql directory, named
+// [version]_[name].up.sql and [version]_[name].down.sql, which are embedded
+// in the binary. Run go generate after adding or changing them.
+package migrations
+
+//go:generate go run gen.go
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockID identifies the advisory lock held while migrating, or reading
+// which migrations have been applied, so only one server instance
+// migrates the database at a time
+const lockID int64 = 7301942585
+
+// Migration struct conveying a numbered change of the schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string // the sql applying the change
+	Down    string // the sql undoing the change
+}
+
+// Status struct conveying if a migration has been applied
+type Status struct {
+	Migration Migration
+	AppliedAt time.Time // zero as long as the migration hasn't been applied
+}
+
+// IsApplied checks if the migration has been applied
+func (s Status) IsApplied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+// All returns the embedded migrations ordered on version, or an error
+// if the sql files aren't named properly or a file of a pair is missing
+func All() ([]Migration, error) {
+	byVersion := map[int]*Migration{}
+	for name, content := range files {
+		parts := strings.SplitN(name, "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 || version <= 0 {
+			return []Migration{}, errors.New("migration " + name + " doesn't start with a version")
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version}
+			byVersion[version] = migration
+		}
+
+		switch {
+		case strings.HasSuffix(parts[1], ".up.sql"):
+			migration.Name = strings.TrimSuffix(parts[1], ".up.sql")
+			migration.Up = content
+		case strings.HasSuffix(parts[1], ".down.sql"):
+			migration.Down = content
+		default:
+			return []Migration{}, errors.New("migration " + name + " is neither up nor down")
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return []Migration{}, fmt.Errorf("migration %d needs both an up and a down file", migration.Version)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies and undoes migrations, keeping track of them in the
+// schema_migrations table. Each migration runs in its own transaction.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration // ordered on version
+}
+
+// NewMigrator creates a migrator of the embedded migrations
+func NewMigrator(db *sql.DB) (Migrator, error) {
+	migrations, err := All()
+	if err != nil {
+		return Migrator{}, err
+	}
+
+	return Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// Up applies all migrations that haven't been applied yet
+func (m Migrator) Up() error {
+	if len(m.Migrations) == 0 {
+		return nil
+	}
+
+	return m.To(m.Migrations[len(m.Migrations)-1].Version)
+}
+
+// Down undoes the latest applied migration
+func (m Migrator) Down() error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	if current == 0 {
+		return errors.New("no migration has been applied")
+	}
+
+	previous := 0
+	for _, migration := range m.Migrations {
+		if migration.Version < current {
+			previous = migration.Version
+		}
+	}
+
+	return m.To(previous)
+}
+
+// To applies or undoes migrations until the provided version is the latest
+// applied migration. Version 0 undoes all migrations.
+func (m Migrator) To(version int) error {
+	if version != 0 {
+		if _, ok := m.find(version); !ok {
+			return fmt.Errorf("there is no migration with version %d", version)
+		}
+	}
+
+	for {
+		done, err := m.step(version)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// Version returns the version of the latest applied migration, 0 if there is none
+func (m Migrator) Version() (int, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for _, status := range statuses {
+		if status.IsApplied() {
+			version = status.Migration.Version
+		}
+	}
+
+	return version, nil
+}
+
+// Status returns for every migration if and when it has been applied
+func (m Migrator) Status() ([]Status, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return []Status{}, err
+	}
+
+	// waits for a migration in progress, which may be creating schema_migrations
+	_, err = tx.Exec(`SELECT pg_advisory_xact_lock($1);`, lockID)
+	if err != nil {
+		_ = tx.Rollback()
+		return []Status{}, err
+	}
+
+	applied, err := m.appliedTx(tx)
+	if err != nil {
+		return []Status{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []Status{}, err
+	}
+
+	statuses := []Status{}
+	for _, migration := range m.Migrations {
+		statuses = append(statuses, Status{Migration: migration, AppliedAt: applied[migration.Version]})
+	}
+
+	return statuses, nil
+}
+
+// step applies or undoes a single migration towards the target version in its
+// own transaction. The advisory lock makes other instances wait until the
+// transaction ends, after which they see the migration has been done.
+// It returns true when the target version has been reached.
+func (m Migrator) step(target int) (bool, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`SELECT pg_advisory_xact_lock($1);`, lockID)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	applied, err := m.appliedTx(tx)
+	if err != nil {
+		return false, err
+	}
+
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+
+	switch {
+	case current < target:
+		err = m.applyTx(tx, m.next(current))
+	case current > target:
+		migration, ok := m.find(current)
+		if !ok {
+			_ = tx.Rollback()
+			return false, fmt.Errorf("applied migration %d is unknown, it can't be undone", current)
+		}
+		err = m.undoTx(tx, migration)
+	default:
+		return true, tx.Commit()
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return false, tx.Commit()
+}
+
+// applyTx applies the migration and records it. It will rollback and return an error if something goes wrong
+func (m Migrator) applyTx(tx *sql.Tx, migration Migration) error {
+	_, err := tx.Exec(migration.Up)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("applying migration %d (%s): %v", migration.Version, migration.Name, err)
+	}
+
+	const insertQuery = `INSERT INTO schema_migrations(version, name, applied_at) VALUES ($1, $2, now());`
+	_, err = tx.Exec(insertQuery, migration.Version, migration.Name)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// undoTx undoes the migration and forgets it. It will rollback and return an error if something goes wrong
+func (m Migrator) undoTx(tx *sql.Tx, migration Migration) error {
+	_, err := tx.Exec(migration.Down)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("undoing migration %d (%s): %v", migration.Version, migration.Name, err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version=$1;`, migration.Version)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// appliedTx creates the schema_migrations table if it doesn't exist yet and returns
+// when each applied migration has been applied, keyed by version.
+// It will rollback and return an error if something goes wrong
+func (m Migrator) appliedTx(tx *sql.Tx) (map[int]time.Time, error) {
+	const createQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	);`
+	_, err := tx.Exec(createQuery)
+	if err != nil {
+		_ = tx.Rollback()
+		return map[int]time.Time{}, err
+	}
+
+	rows, err := tx.Query(`SELECT version, applied_at FROM schema_migrations;`)
+	if err != nil {
+		_ = tx.Rollback()
+		return map[int]time.Time{}, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			_ = tx.Rollback()
+			return map[int]time.Time{}, err
+		}
+
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		_ = tx.Rollback()
+		return map[int]time.Time{}, err
+	}
+
+	return applied, nil
+}
+
+// helper func that finds the migration with the provided version
+func (m Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.Migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+// helper func that finds the first migration after the provided version
+func (m Migrator) next(version int) Migration {
+	for _, migration := range m.Migrations {
+		if migration.Version > version {
+			return migration
+		}
+	}
+
+	return Migration{}
+}
@@ -0,0 +1,10 @@
+-- Drops the initial schema, including all data.
+
+DROP TABLE IF EXISTS "Invite_Link";
+DROP TABLE IF EXISTS "Address";
+DROP TABLE IF EXISTS "Message";
+DROP TABLE IF EXISTS "Project";
+DROP TABLE IF EXISTS "Representative";
+DROP TABLE IF EXISTS "Student";
+DROP TABLE IF EXISTS "Company";
+DROP TABLE IF EXISTS "User";
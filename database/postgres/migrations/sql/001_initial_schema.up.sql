@@ -1,3 +1,7 @@
+-- The schema as it was before migrations were versioned, the former initdb.sql.
+-- It only creates what doesn't exist yet, so databases created with initdb.sql
+-- are adopted at this version and brought up to date by the later migrations.
+
 CREATE TABLE IF NOT EXISTS "User" (
     user_id UUID PRIMARY KEY,
     first_name TEXT NOT NULL,
@@ -67,4 +71,4 @@ CREATE TABLE IF NOT EXISTS "Invite_Link" (
     expiry_date TIMESTAMP,
     used BOOLEAN NOT NULL,
     ref_representative UUID REFERENCES "Representative" (representative_id)
-);
\ No newline at end of file
+);
@@ -0,0 +1,6 @@
+-- Ungroups the messages, which keep their sender, receiver and project.
+
+ALTER TABLE "Message" DROP COLUMN IF EXISTS ref_conversation;
+
+DROP TABLE IF EXISTS "Conversation_Participant";
+DROP TABLE IF EXISTS "Conversation";
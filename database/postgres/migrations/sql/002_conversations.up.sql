@@ -0,0 +1,47 @@
+-- Groups messages into conversations between two users, optionally about a
+-- project, with the moment each participant last read the conversation.
+-- Existing messages are grouped into the conversations they would have been
+-- part of, which count as read, as they have been shown without read markers.
+
+CREATE TABLE IF NOT EXISTS "Conversation" (
+    conversation_id UUID PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT now(),
+    last_activity TIMESTAMP DEFAULT now(),
+    started_by UUID REFERENCES "User" (user_id),
+    ref_project UUID REFERENCES "Project" (project_id)
+);
+
+CREATE TABLE IF NOT EXISTS "Conversation_Participant" (
+    ref_conversation UUID REFERENCES "Conversation" (conversation_id),
+    ref_user UUID REFERENCES "User" (user_id),
+    read_at TIMESTAMP,
+    PRIMARY KEY (ref_conversation, ref_user)
+);
+
+ALTER TABLE "Message" ADD COLUMN IF NOT EXISTS ref_conversation UUID REFERENCES "Conversation" (conversation_id);
+
+-- the id of a conversation is derived from its participants and project,
+-- so the messages can find the conversation they have been grouped into
+CREATE TEMPORARY TABLE grouped_message ON COMMIT DROP AS
+SELECT message_id, sender, receiver, created_at, ref_project, md5(
+    least(sender, receiver)::text || greatest(sender, receiver)::text || coalesce(ref_project::text, '')
+)::uuid AS ref_conversation
+FROM "Message"
+WHERE ref_conversation IS NULL AND sender IS NOT NULL AND receiver IS NOT NULL;
+
+INSERT INTO "Conversation" (conversation_id, created_at, last_activity, started_by, ref_project)
+SELECT ref_conversation, min(created_at), max(created_at), (array_agg(sender ORDER BY created_at))[1], (array_agg(ref_project))[1]
+FROM grouped_message
+GROUP BY ref_conversation
+ON CONFLICT (conversation_id) DO NOTHING;
+
+INSERT INTO "Conversation_Participant" (ref_conversation, ref_user, read_at)
+SELECT DISTINCT g.ref_conversation, p.ref_user, c.last_activity
+FROM grouped_message g
+JOIN "Conversation" c ON c.conversation_id = g.ref_conversation
+CROSS JOIN LATERAL (VALUES (g.sender), (g.receiver)) AS p (ref_user)
+ON CONFLICT (ref_conversation, ref_user) DO NOTHING;
+
+UPDATE "Message" m SET ref_conversation = g.ref_conversation
+FROM grouped_message g
+WHERE m.message_id = g.message_id;
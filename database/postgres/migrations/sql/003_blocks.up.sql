@@ -0,0 +1,8 @@
+-- Lets students block companies from messaging them.
+
+CREATE TABLE IF NOT EXISTS "Block" (
+    ref_student UUID REFERENCES "Student" (student_id),
+    ref_company UUID REFERENCES "Company" (company_id),
+    created_at TIMESTAMP DEFAULT now(),
+    PRIMARY KEY (ref_student, ref_company)
+);
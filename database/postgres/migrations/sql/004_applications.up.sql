@@ -0,0 +1,13 @@
+-- Lets students apply to projects, once per project.
+
+CREATE TABLE IF NOT EXISTS "Application" (
+    application_id UUID PRIMARY KEY,
+    ref_student UUID REFERENCES "Student" (student_id),
+    ref_project UUID REFERENCES "Project" (project_id) ON DELETE CASCADE,
+    motivation TEXT NOT NULL,
+    "resume" TEXT,
+    "status" TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now(),
+    UNIQUE (ref_student, ref_project)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "Shortlist_Entry";
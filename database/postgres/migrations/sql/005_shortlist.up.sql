@@ -0,0 +1,13 @@
+-- Adds the shortlist of students a company shares among its representatives.
+
+CREATE TABLE IF NOT EXISTS "Shortlist_Entry" (
+    shortlist_entry_id UUID PRIMARY KEY,
+    ref_company UUID REFERENCES "Company" (company_id),
+    ref_student UUID REFERENCES "Student" (student_id),
+    ref_project UUID REFERENCES "Project" (project_id) ON DELETE SET NULL,
+    notes TEXT NOT NULL,
+    tags TEXT[],
+    added_by UUID REFERENCES "Representative" (representative_id),
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
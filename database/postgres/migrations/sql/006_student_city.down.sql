@@ -0,0 +1 @@
+ALTER TABLE "Student" DROP COLUMN IF EXISTS city;
@@ -0,0 +1,3 @@
+-- Adds the city of students, which they can be filtered on.
+
+ALTER TABLE "Student" ADD COLUMN IF NOT EXISTS city TEXT;
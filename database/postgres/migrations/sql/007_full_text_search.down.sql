@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS project_search_idx;
+DROP INDEX IF EXISTS company_search_idx;
+
+DROP TRIGGER IF EXISTS project_search_vector_update ON "Project";
+DROP TRIGGER IF EXISTS company_search_vector_update ON "Company";
+
+DROP FUNCTION IF EXISTS project_search_vector();
+DROP FUNCTION IF EXISTS company_search_vector();
+
+ALTER TABLE "Project" DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE "Company" DROP COLUMN IF EXISTS search_vector;
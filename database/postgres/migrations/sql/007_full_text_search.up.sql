@@ -0,0 +1,37 @@
+-- Adds full-text search to projects and companies.
+
+ALTER TABLE "Company" ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE "Project" ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION company_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW."name", '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW."description", '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION project_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW."description", '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(array_to_string(NEW.recommendations, ' '), '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS company_search_vector_update ON "Company";
+CREATE TRIGGER company_search_vector_update BEFORE INSERT OR UPDATE ON "Company"
+    FOR EACH ROW EXECUTE PROCEDURE company_search_vector();
+
+DROP TRIGGER IF EXISTS project_search_vector_update ON "Project";
+CREATE TRIGGER project_search_vector_update BEFORE INSERT OR UPDATE ON "Project"
+    FOR EACH ROW EXECUTE PROCEDURE project_search_vector();
+
+-- fill the vectors of the rows that already exist
+UPDATE "Company" SET search_vector = NULL;
+UPDATE "Project" SET search_vector = NULL;
+
+CREATE INDEX IF NOT EXISTS company_search_idx ON "Company" USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS project_search_idx ON "Project" USING GIN (search_vector);
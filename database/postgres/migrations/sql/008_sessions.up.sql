@@ -0,0 +1,17 @@
+-- Adds the sessions holding the refresh tokens of signed in users.
+-- Tokens issued before this migration are no longer accepted, so
+-- all users have to sign in again.
+
+CREATE TABLE IF NOT EXISTS "Session" (
+    session_id UUID PRIMARY KEY,
+    family_id UUID NOT NULL,
+    ref_user UUID REFERENCES "User" (user_id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS session_family_idx ON "Session" (family_id);
+CREATE INDEX IF NOT EXISTS session_user_idx ON "Session" (ref_user);
@@ -0,0 +1,11 @@
+-- Adds the one-time tokens sent to users, such as password reset tokens.
+
+CREATE TABLE IF NOT EXISTS "User_Token" (
+    user_token_id UUID PRIMARY KEY,
+    ref_user UUID REFERENCES "User" (user_id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT now(),
+    expiry_date TIMESTAMP NOT NULL,
+    used BOOLEAN NOT NULL DEFAULT FALSE
+);
@@ -0,0 +1 @@
+ALTER TABLE "User" DROP COLUMN IF EXISTS email_verified;
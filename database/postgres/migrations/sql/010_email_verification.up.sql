@@ -0,0 +1,6 @@
+-- Adds the verification state of the email of users. Accounts that
+-- existed before are considered verified, so they aren't locked out.
+
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE "User" SET email_verified = TRUE;
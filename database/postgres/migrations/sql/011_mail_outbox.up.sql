@@ -0,0 +1,16 @@
+-- Adds the outbox of mails, which are queued in the same transaction
+-- as the change they are about and sent by a background worker.
+
+CREATE TABLE IF NOT EXISTS "Mail_Outbox" (
+    mail_id UUID PRIMARY KEY,
+    event TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT now(),
+    next_attempt_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS mail_outbox_due_idx ON "Mail_Outbox" (next_attempt_at) WHERE sent_at IS NULL;
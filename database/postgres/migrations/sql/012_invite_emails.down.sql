@@ -0,0 +1,2 @@
+ALTER TABLE "Invite_Link" DROP COLUMN IF EXISTS revoked_at;
+ALTER TABLE "Invite_Link" DROP COLUMN IF EXISTS email;
@@ -0,0 +1,5 @@
+-- Binds invitelinks to the email they are sent to and lets them be revoked.
+-- Invitelinks created before have no email, so they can't be used anymore.
+
+ALTER TABLE "Invite_Link" ADD COLUMN IF NOT EXISTS email TEXT NOT NULL DEFAULT '';
+ALTER TABLE "Invite_Link" ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP NULL;
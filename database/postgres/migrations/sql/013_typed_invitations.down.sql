@@ -0,0 +1,21 @@
+-- Turns invitations back into invitelinks of representatives, which have been
+-- used once they have been used at least once. Invitations that haven't been
+-- created by a representative lose their creator.
+
+ALTER TABLE "Invite_Link" RENAME COLUMN created_by TO ref_representative;
+
+UPDATE "Invite_Link" SET ref_representative = NULL
+WHERE ref_representative NOT IN (SELECT representative_id FROM "Representative");
+
+ALTER TABLE "Invite_Link" ADD CONSTRAINT "Invite_Link_ref_representative_fkey"
+    FOREIGN KEY (ref_representative) REFERENCES "Representative" (representative_id);
+
+ALTER TABLE "Invite_Link" ADD COLUMN used BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE "Invite_Link" SET used = uses > 0;
+ALTER TABLE "Invite_Link" ALTER COLUMN used DROP DEFAULT;
+
+ALTER TABLE "Invite_Link" DROP COLUMN uses;
+ALTER TABLE "Invite_Link" DROP COLUMN max_uses;
+ALTER TABLE "Invite_Link" DROP COLUMN "role";
+ALTER TABLE "Invite_Link" DROP COLUMN target_id;
+ALTER TABLE "Invite_Link" DROP COLUMN kind;
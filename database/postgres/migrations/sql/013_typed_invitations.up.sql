@@ -0,0 +1,16 @@
+-- Generalises invitelinks into typed invitations, which invite someone to a
+-- target (e.g. a company) with a role and can be used a limited number of times.
+-- The creator is no longer always a representative, so it isn't a reference anymore.
+
+ALTER TABLE "Invite_Link" ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'representative';
+ALTER TABLE "Invite_Link" ADD COLUMN IF NOT EXISTS target_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE "Invite_Link" ADD COLUMN IF NOT EXISTS "role" TEXT NOT NULL DEFAULT 'representative';
+ALTER TABLE "Invite_Link" ADD COLUMN IF NOT EXISTS max_uses INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "Invite_Link" ADD COLUMN IF NOT EXISTS uses INTEGER NOT NULL DEFAULT 0;
+
+-- urls have the format /signup/representatives/invite/[companyID]/[invitelinkID]
+UPDATE "Invite_Link" SET target_id = split_part(url, '/', 5), uses = CASE WHEN used THEN 1 ELSE 0 END;
+
+ALTER TABLE "Invite_Link" DROP COLUMN used;
+ALTER TABLE "Invite_Link" DROP CONSTRAINT IF EXISTS "Invite_Link_ref_representative_fkey";
+ALTER TABLE "Invite_Link" RENAME COLUMN ref_representative TO created_by;
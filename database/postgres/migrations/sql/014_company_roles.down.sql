@@ -0,0 +1 @@
+ALTER TABLE "Representative" DROP COLUMN IF EXISTS company_role;
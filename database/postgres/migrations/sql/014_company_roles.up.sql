@@ -0,0 +1,18 @@
+-- Adds the role of representatives within their company. Representatives that existed
+-- before become admins, keeping their permission to manage the company, except for the
+-- one that signed up the company, who becomes its owner. When the company was signed up
+-- isn't stored, but the others could only join by an invitation, so it is the one that
+-- created the earliest invitation, or the only representative of a company without any.
+
+ALTER TABLE "Representative" ADD COLUMN IF NOT EXISTS company_role TEXT NOT NULL DEFAULT 'recruiter';
+
+UPDATE "Representative" SET company_role = 'admin';
+
+UPDATE "Representative" r SET company_role = 'owner'
+FROM (
+    SELECT DISTINCT ON (rep.ref_company) rep.representative_id
+    FROM "Representative" rep LEFT JOIN "Invite_Link" i ON i.created_by = rep.representative_id
+    WHERE rep.ref_company IS NOT NULL
+    ORDER BY rep.ref_company, i.created_at ASC NULLS LAST, rep.representative_id
+) owners
+WHERE r.representative_id = owners.representative_id;
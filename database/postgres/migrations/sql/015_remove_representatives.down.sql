@@ -0,0 +1,2 @@
+ALTER TABLE "Project" DROP COLUMN IF EXISTS ref_representative;
+ALTER TABLE "Representative" DROP COLUMN IF EXISTS removed_at;
@@ -0,0 +1,6 @@
+-- Lets representatives be removed from their company, while keeping what they
+-- did, and gives projects a representative as contact, who can be replaced.
+-- Who created the existing projects isn't known, so they have no contact.
+
+ALTER TABLE "Representative" ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP NULL;
+ALTER TABLE "Project" ADD COLUMN IF NOT EXISTS ref_representative UUID REFERENCES "Representative" (representative_id);
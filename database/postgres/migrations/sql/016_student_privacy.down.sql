@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "Resume_Request";
+
+ALTER TABLE "Student" DROP COLUMN IF EXISTS resume_on_request;
+ALTER TABLE "Student" DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,15 @@
+-- Adds the privacy settings of students and the requests of companies for
+-- the resume of students that only share it on request. Existing students
+-- stay visible to all representatives, as they have been so far.
+
+ALTER TABLE "Student" ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'representatives';
+ALTER TABLE "Student" ADD COLUMN IF NOT EXISTS resume_on_request BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS "Resume_Request" (
+    ref_student UUID REFERENCES "Student" (student_id),
+    ref_company UUID REFERENCES "Company" (company_id),
+    requested_by UUID REFERENCES "Representative" (representative_id),
+    requested_at TIMESTAMP DEFAULT now(),
+    granted_at TIMESTAMP NULL,
+    PRIMARY KEY (ref_student, ref_company)
+);
@@ -0,0 +1 @@
+ALTER TABLE "Shortlist_Entry" DROP CONSTRAINT IF EXISTS shortlist_entry_student_project_key;
@@ -0,0 +1,14 @@
+-- Lets a student be on the shortlist of a company only once for the same project.
+-- Entries without a project aren't covered, as entries lose their project when it
+-- is deleted, which shouldn't fail because the student is on the shortlist twice.
+-- Of the duplicate entries that already exist, the most recently updated is kept.
+
+DELETE FROM "Shortlist_Entry" s
+USING "Shortlist_Entry" newer
+WHERE s.ref_company = newer.ref_company
+AND s.ref_student = newer.ref_student
+AND s.ref_project = newer.ref_project
+AND (s.updated_at, s.shortlist_entry_id) < (newer.updated_at, newer.shortlist_entry_id);
+
+ALTER TABLE "Shortlist_Entry" ADD CONSTRAINT shortlist_entry_student_project_key
+    UNIQUE (ref_company, ref_student, ref_project);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS shortlist_entry_student_key;
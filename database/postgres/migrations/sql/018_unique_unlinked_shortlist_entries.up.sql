@@ -0,0 +1,13 @@
+-- Lets a student be on the shortlist of a company only once without a project as well.
+-- Of the duplicate entries without a project that already exist, the most recently
+-- updated is kept.
+
+DELETE FROM "Shortlist_Entry" s
+USING "Shortlist_Entry" newer
+WHERE s.ref_company = newer.ref_company
+AND s.ref_student = newer.ref_student
+AND s.ref_project IS NULL AND newer.ref_project IS NULL
+AND (s.updated_at, s.shortlist_entry_id) < (newer.updated_at, newer.shortlist_entry_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS shortlist_entry_student_key
+    ON "Shortlist_Entry" (ref_company, ref_student) WHERE ref_project IS NULL;
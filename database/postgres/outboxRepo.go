@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// OutboxRepo struct for postgres database
+type OutboxRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (o OutboxRepo) begin() (Tx, error) {
+	return begin(o.DB, o.work)
+}
+
+// Enqueue inserts a mail in the outbox in the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (o OutboxRepo) Enqueue(mail d.OutboxMail) error {
+	tx, err := o.begin()
+	if err != nil {
+		return err
+	}
+
+	err = o.EnqueueTx(tx, mail)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClaimDue finds at most limit unsent mails in the DB that should be sent at the provided
+// time, and postpones them by the lease so other workers skip them meanwhile. Mails that
+// failed too often are left out. It should be used as a single unit of work, as it has
+// its own transaction inside.
+func (o OutboxRepo) ClaimDue(at time.Time, lease time.Duration, limit int) ([]d.OutboxMail, error) {
+	tx, err := o.begin()
+	if err != nil {
+		return []d.OutboxMail{}, err
+	}
+
+	const claimQuery = `UPDATE "Mail_Outbox" o SET next_attempt_at=$1
+	WHERE o.mail_id IN (
+		SELECT mail_id FROM "Mail_Outbox"
+		WHERE sent_at IS NULL AND attempts < $2 AND next_attempt_at <= $3
+		ORDER BY next_attempt_at LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING o.mail_id, o.event, o.recipient, o.data, o.attempts, o.last_error,
+	o.created_at, o.next_attempt_at, o.sent_at;`
+
+	rows, err := tx.Query(claimQuery, at.Add(lease), d.MaxMailAttempts, at, limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.OutboxMail{}, err
+	}
+	defer rows.Close()
+
+	mails := []d.OutboxMail{}
+	for rows.Next() {
+		mail, err := scanOutboxMail(rows)
+		if err != nil {
+			_ = tx.Rollback()
+			return []d.OutboxMail{}, err
+		}
+
+		mails = append(mails, mail)
+	}
+
+	if err = rows.Err(); err != nil {
+		_ = tx.Rollback()
+		return []d.OutboxMail{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.OutboxMail{}, err
+	}
+
+	return mails, nil
+}
+
+// MarkSent marks the mail in the DB as sent. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (o OutboxRepo) MarkSent(id string, at time.Time) error {
+	tx, err := o.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "Mail_Outbox" SET sent_at=$1 WHERE mail_id=$2;`
+	_, err = tx.Exec(updateQuery, at, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed stores the failed attempts of the mail in the DB, together with
+// when to try again. It should be used as a single unit of work, as it has
+// its own transaction inside.
+func (o OutboxRepo) MarkFailed(mail d.OutboxMail) error {
+	tx, err := o.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "Mail_Outbox" SET attempts=$1, last_error=$2, next_attempt_at=$3
+	WHERE mail_id=$4;`
+	_, err = tx.Exec(updateQuery, mail.Attempts, mail.LastError, mail.NextAttemptAt, mail.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EnqueueTx inserts a mail in the outbox in the DB. It should be used as PART of a
+// unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (o OutboxRepo) EnqueueTx(tx Tx, mail d.OutboxMail) error {
+	data, err := json.Marshal(mail.Data)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	const insertQuery = `INSERT INTO "Mail_Outbox"(mail_id, event, recipient, data, attempts,
+	last_error, created_at, next_attempt_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+	_, err = tx.Exec(insertQuery,
+		mail.ID,
+		mail.Event,
+		mail.To,
+		data,
+		mail.Attempts,
+		mail.LastError,
+		mail.CreatedAt,
+		mail.NextAttemptAt,
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// helper func to scan a single outbox mail row
+func scanOutboxMail(row scanner) (d.OutboxMail, error) {
+	var mID, event, recipient, lastError string
+	var data []byte
+	var attempts int
+	var createdAt, nextAttemptAt time.Time
+	var sentAt pq.NullTime
+
+	err := row.Scan(&mID, &event, &recipient, &data, &attempts, &lastError, &createdAt, &nextAttemptAt, &sentAt)
+	if err != nil {
+		return d.OutboxMail{}, err
+	}
+
+	values := map[string]string{}
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return d.OutboxMail{}, err
+	}
+
+	return d.OutboxMail{
+		ID:            mID,
+		Event:         d.MailEvent(event),
+		To:            recipient,
+		Data:          values,
+		Attempts:      attempts,
+		LastError:     lastError,
+		CreatedAt:     createdAt,
+		NextAttemptAt: nextAttemptAt,
+		SentAt:        sentAt.Time,
+	}, nil
+}
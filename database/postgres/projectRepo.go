@@ -9,13 +9,19 @@ import (
 
 // ProjectRepo struct for postgres database
 type ProjectRepo struct {
-	DB *sql.DB
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (p ProjectRepo) begin() (Tx, error) {
+	return begin(p.DB, p.work)
 }
 
 // FindByID finds a project in the DB based on id. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (p ProjectRepo) FindByID(id string) (domain.Project, error) {
-	tx, err := p.DB.Begin()
+	tx, err := p.begin()
 	if err != nil {
 		return domain.Project{}, err
 	}
@@ -36,7 +42,7 @@ func (p ProjectRepo) FindByID(id string) (domain.Project, error) {
 // Delete deletes a project in the DB based on id. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (p ProjectRepo) Delete(id string) error {
-	tx, err := p.DB.Begin()
+	tx, err := p.begin()
 	if err != nil {
 		return err
 	}
@@ -58,21 +64,22 @@ func (p ProjectRepo) Delete(id string) error {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (p ProjectRepo) FindByIDTx(tx *sql.Tx, id string) (domain.Project, error) {
+func (p ProjectRepo) FindByIDTx(tx Tx, id string) (domain.Project, error) {
 	var (
-		pID, descr, comp, dur, cID string
-		recomms                    []string
+		pID, descr, comp, dur, cID, contactID string
+		recomms                               []string
 	)
 
 	const selectQuery = `
-	SELECT project_id, description, duration, compensation, recommendations, ref_company 
+	SELECT project_id, description, duration, compensation, recommendations, ref_company,
+	COALESCE(ref_representative::text, '')
 	FROM "Project" WHERE project_id=$1;
 	`
 	result := tx.QueryRow(selectQuery, id)
-	err := result.Scan(&pID, &descr, &dur, &comp, pq.Array(&recomms), &cID)
+	err := result.Scan(&pID, &descr, &dur, &comp, pq.Array(&recomms), &cID, &contactID)
 	if err != nil {
 		_ = tx.Rollback()
-		return domain.Project{}, err
+		return domain.Project{}, notFound(err)
 	}
 
 	return domain.Project{
@@ -82,16 +89,27 @@ func (p ProjectRepo) FindByIDTx(tx *sql.Tx, id string) (domain.Project, error) {
 		Duration:        dur,
 		Recommendations: recomms,
 		CompanyID:       cID,
+		ContactID:       contactID,
 	}, nil
 }
 
-// DeleteTx deletes a project in the DB based on id. It should be used as PART of a
-// unit of work, as a transaction gets passed in but will not be committed.
-// This is the responsibility of the caller.
+// DeleteTx deletes a project in the DB based on id. Shortlist entries lose their project,
+// unless the student is already on the shortlist without a project, then they are deleted.
+// It should be used as PART of a unit of work, as a transaction gets passed in but will
+// not be committed. This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (p ProjectRepo) DeleteTx(tx *sql.Tx, id string) error {
+func (p ProjectRepo) DeleteTx(tx Tx, id string) error {
+	const deleteEntriesQuery = `DELETE FROM "Shortlist_Entry" s WHERE s.ref_project=$1 AND EXISTS (
+		SELECT 1 FROM "Shortlist_Entry" o
+		WHERE o.ref_company = s.ref_company AND o.ref_student = s.ref_student AND o.ref_project IS NULL);`
+	_, err := tx.Exec(deleteEntriesQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
 	const deleteQuery = `DELETE FROM "Project" WHERE project_id=$1;`
-	_, err := tx.Exec(deleteQuery, id)
+	_, err = tx.Exec(deleteQuery, id)
 	if err != nil {
 		_ = tx.Rollback()
 		return err
@@ -100,34 +118,38 @@ func (p ProjectRepo) DeleteTx(tx *sql.Tx, id string) error {
 	return nil
 }
 
-// FindAll finds all the projects in the database
-func (p ProjectRepo) FindAll() ([]domain.Project, error) {
-	tx, err := p.DB.Begin()
+// FindAll finds a page of all the projects in the DB, ordered on id. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (p ProjectRepo) FindAll(page domain.Page) ([]domain.Project, string, error) {
+	tx, err := p.begin()
 	if err != nil {
-		return []domain.Project{}, err
+		return []domain.Project{}, "", err
 	}
 
+	// one project more than the limit is fetched to know if there is a next page
 	const selectQuery = `
-	SELECT project_id, description, duration, compensation, recommendations, ref_company 
-	FROM "Project" ORDER BY RANDOM();
+	SELECT project_id, description, duration, compensation, recommendations, ref_company,
+	COALESCE(ref_representative::text, '')
+	FROM "Project" WHERE $1 = '' OR project_id::text > $1 ORDER BY project_id::text LIMIT $2;
 	`
 
-	rows, err := tx.Query(selectQuery)
+	rows, err := tx.Query(selectQuery, page.After, page.Limit+1)
 	if err != nil {
 		_ = tx.Rollback()
-		return []domain.Project{}, err
+		return []domain.Project{}, "", err
 	}
+	defer rows.Close()
 
 	projects := []domain.Project{}
 	for rows.Next() {
 		var (
-			pID, descr, comp, dur, cID string
-			recomms                    []string
+			pID, descr, comp, dur, cID, contactID string
+			recomms                               []string
 		)
 
-		if err := rows.Scan(&pID, &descr, &dur, &comp, pq.Array(&recomms), &cID); err != nil {
+		if err := rows.Scan(&pID, &descr, &dur, &comp, pq.Array(&recomms), &cID, &contactID); err != nil {
 			_ = tx.Rollback()
-			return []domain.Project{}, err
+			return []domain.Project{}, "", err
 		}
 
 		projects = append(projects, domain.Project{
@@ -137,13 +159,20 @@ func (p ProjectRepo) FindAll() ([]domain.Project, error) {
 			Duration:        dur,
 			Recommendations: recomms,
 			CompanyID:       cID,
+			ContactID:       contactID,
 		})
 	}
 
+	next := ""
+	if len(projects) > page.Limit {
+		projects = projects[:page.Limit]
+		next = projects[page.Limit-1].ID
+	}
+
 	err = tx.Commit()
 	if err != nil {
-		return []domain.Project{}, err
+		return []domain.Project{}, "", err
 	}
 
-	return projects, nil
+	return projects, next, nil
 }
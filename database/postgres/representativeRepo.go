@@ -2,20 +2,31 @@ package postgres
 
 import (
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 
 	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // RepresentativeRepo struct for postgres database
 type RepresentativeRepo struct {
-	DB       *sql.DB
-	UserRepo UserRepo
+	DB          *sql.DB
+	UserRepo    UserRepo
+	SessionRepo SessionRepo
+	work        *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (r RepresentativeRepo) begin() (Tx, error) {
+	return begin(r.DB, r.work)
 }
 
 // Create inserts a representative in the DB. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (r RepresentativeRepo) Create(repr d.Representative) error {
-	tx, err := r.DB.Begin()
+	tx, err := r.begin()
 	if err != nil {
 		return err
 	}
@@ -25,13 +36,14 @@ func (r RepresentativeRepo) Create(repr d.Representative) error {
 		return err
 	}
 
-	const insertQuery = `INSERT INTO "Representative"(representative_id, job_title, ref_user, ref_company)
-	VALUES ($1, $2, $3, $4);`
+	const insertQuery = `INSERT INTO "Representative"(representative_id, job_title, ref_user, ref_company, company_role)
+	VALUES ($1, $2, $3, $4, $5);`
 	_, err = tx.Exec(insertQuery,
 		repr.ID,
 		repr.JobTitle,
 		repr.User.ID,
 		repr.CompanyID,
+		repr.CompanyRole,
 	)
 
 	if err != nil {
@@ -50,7 +62,7 @@ func (r RepresentativeRepo) Create(repr d.Representative) error {
 // FindByID finds a representative in the DB based on id. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (r RepresentativeRepo) FindByID(id string) (d.Representative, error) {
-	tx, err := r.DB.Begin()
+	tx, err := r.begin()
 	if err != nil {
 		return d.Representative{}, err
 	}
@@ -71,7 +83,7 @@ func (r RepresentativeRepo) FindByID(id string) (d.Representative, error) {
 // Update updates a representative in the DB. It should be used as a single unit of work,
 // as it has its own transaction inside.
 func (r RepresentativeRepo) Update(representative d.Representative) error {
-	tx, err := r.DB.Begin()
+	tx, err := r.begin()
 	if err != nil {
 		return err
 	}
@@ -89,23 +101,148 @@ func (r RepresentativeRepo) Update(representative d.Representative) error {
 	return nil
 }
 
+// UpdateCompanyRole updates the role of the representative within their company
+func (r RepresentativeRepo) UpdateCompanyRole(representativeID string, role d.CompanyRole) error {
+	const updateQuery = `UPDATE "Representative" SET company_role=$1 WHERE representative_id=$2;`
+	tx, err := r.begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(updateQuery, role, representativeID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		_ = tx.Rollback()
+		return e.ErrorEntityNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Remove removes a representative from their company in the DB, handing their projects
+// and conversations over to the successor, revoking their pending invitations and
+// sessions. It should be used as a single unit of work, as it has its own transaction inside.
+func (r RepresentativeRepo) Remove(representativeID, successorID string, at time.Time) error {
+	tx, err := r.begin()
+	if err != nil {
+		return err
+	}
+
+	const removeQuery = `UPDATE "Representative" SET removed_at=$1
+	WHERE representative_id=$2 AND removed_at IS NULL RETURNING ref_user;`
+	var userID string
+	err = tx.QueryRow(removeQuery, at, representativeID).Scan(&userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return notFound(err)
+	}
+
+	const projectsQuery = `UPDATE "Project" SET ref_representative=$1 WHERE ref_representative=$2;`
+	_, err = tx.Exec(projectsQuery, successorID, representativeID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// the successor takes the place of the representative in their conversations,
+	// unless the successor already takes part in it. Sent messages keep their sender.
+	const conversationsQuery = `UPDATE "Conversation_Participant" p
+	SET ref_user=(SELECT ref_user FROM "Representative" WHERE representative_id=$1), read_at=NULL
+	WHERE p.ref_user=$2 AND NOT EXISTS (
+		SELECT 1 FROM "Conversation_Participant" o
+		JOIN "Representative" r ON o.ref_user = r.ref_user
+		WHERE o.ref_conversation = p.ref_conversation AND r.representative_id=$1
+	);`
+	_, err = tx.Exec(conversationsQuery, successorID, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	const invitationsQuery = `UPDATE "Invite_Link" SET revoked_at=$1
+	WHERE created_by=$2 AND uses < max_uses AND revoked_at IS NULL;`
+	_, err = tx.Exec(invitationsQuery, at, representativeID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = r.SessionRepo.RevokeByUserTx(tx, userID, at)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TransferOwnership makes the new owner the owner of their company in the DB, while the
+// current owner becomes an admin. It should be used as a single unit of work, as it
+// has its own transaction inside.
+func (r RepresentativeRepo) TransferOwnership(ownerID, newOwnerID string) error {
+	tx, err := r.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "Representative" SET company_role=$1 WHERE representative_id=$2;`
+	_, err = tx.Exec(updateQuery, d.CompanyAdmin, ownerID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(updateQuery, d.CompanyOwner, newOwnerID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateTx inserts a representative in the DB. It should be used as PART of a
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (r RepresentativeRepo) CreateTx(tx *sql.Tx, repr d.Representative) error {
+func (r RepresentativeRepo) CreateTx(tx Tx, repr d.Representative) error {
 	err := r.UserRepo.CreateTx(tx, repr.User)
 	if err != nil {
 		return err
 	}
 
-	const insertQuery = `INSERT INTO "Representative"(representative_id, job_title, ref_user, ref_company)
-	VALUES ($1, $2, $3, $4);`
+	const insertQuery = `INSERT INTO "Representative"(representative_id, job_title, ref_user, ref_company, company_role)
+	VALUES ($1, $2, $3, $4, $5);`
 	_, err = tx.Exec(insertQuery,
 		repr.ID,
 		repr.JobTitle,
 		repr.User.ID,
 		repr.CompanyID,
+		repr.CompanyRole,
 	)
 
 	if err != nil {
@@ -120,7 +257,7 @@ func (r RepresentativeRepo) CreateTx(tx *sql.Tx, repr d.Representative) error {
 // a unit of work, as a transaction gets passed in but will not be commited.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong.
-func (r RepresentativeRepo) UpdateTx(tx *sql.Tx, repr d.Representative) error {
+func (r RepresentativeRepo) UpdateTx(tx Tx, repr d.Representative) error {
 	const updateRepresentativeQuery = `UPDATE "Representative" r
 	SET job_title=$1 WHERE r.representative_id=$2;`
 
@@ -134,8 +271,10 @@ func (r RepresentativeRepo) UpdateTx(tx *sql.Tx, repr d.Representative) error {
 		return err
 	}
 
-	const updateUserQuery = `UPDATE "User" u SET first_name=$1, last_name=$2, email=$3
-	WHERE u.user_id=(SELECT ref_user FROM "Student" WHERE student_id=$4);`
+	// a changed email has to be verified again
+	const updateUserQuery = `UPDATE "User" u SET first_name=$1, last_name=$2, email=$3,
+	email_verified=(u.email=$3 AND u.email_verified)
+	WHERE u.user_id=(SELECT ref_user FROM "Representative" WHERE representative_id=$4);`
 	_, err = tx.Exec(updateUserQuery,
 		repr.User.FirstName,
 		repr.User.LastName,
@@ -155,23 +294,26 @@ func (r RepresentativeRepo) UpdateTx(tx *sql.Tx, repr d.Representative) error {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (r RepresentativeRepo) FindByIDTx(tx *sql.Tx, id string) (d.Representative, error) {
-	var rID, title, cID, uID, fname, lname, email, hash, role string
-	const selectQuery = `SELECT r.representative_id, r.job_title, r.ref_company,
+func (r RepresentativeRepo) FindByIDTx(tx Tx, id string) (d.Representative, error) {
+	var rID, title, cID, companyRole, uID, fname, lname, email, hash, role string
+	var removedAt pq.NullTime
+	const selectQuery = `SELECT r.representative_id, r.job_title, r.ref_company, r.company_role, r.removed_at,
 	u.user_id, u.first_name, u.last_name, u.email, u.hashed_password, u.role
 	FROM "Representative" r JOIN "User" u ON r.ref_user = u.user_id 
 	WHERE r.representative_id = $1;`
 	result := tx.QueryRow(selectQuery, id)
-	err := result.Scan(&rID, &title, &cID, &uID, &fname, &lname, &email, &hash, &role)
+	err := result.Scan(&rID, &title, &cID, &companyRole, &removedAt, &uID, &fname, &lname, &email, &hash, &role)
 	if err != nil {
 		_ = tx.Rollback()
-		return d.Representative{}, err
+		return d.Representative{}, notFound(err)
 	}
 
 	return d.Representative{
-		ID:        rID,
-		JobTitle:  title,
-		CompanyID: cID,
+		ID:          rID,
+		JobTitle:    title,
+		CompanyID:   cID,
+		CompanyRole: d.CompanyRole(companyRole),
+		RemovedAt:   removedAt.Time,
 		User: d.User{
 			ID:             uID,
 			FirstName:      fname,
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ResumeRequestRepo struct for postgres database
+type ResumeRequestRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (r ResumeRequestRepo) begin() (Tx, error) {
+	return begin(r.DB, r.work)
+}
+
+// Create inserts a resume request in the DB. Asking for a resume again
+// is a no-op. It should be used as a single unit of work, as it has its
+// own transaction inside.
+func (r ResumeRequestRepo) Create(request d.ResumeRequest) error {
+	tx, err := r.begin()
+	if err != nil {
+		return err
+	}
+
+	const insertQuery = `INSERT INTO "Resume_Request"(ref_student, ref_company, requested_by, requested_at)
+	VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING;`
+	_, err = tx.Exec(insertQuery, request.StudentID, request.CompanyID, request.RequestedBy, request.RequestedAt)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByStudent finds all resume requests for the student, newest first. It
+// should be used as a single unit of work, as it has its own transaction inside.
+func (r ResumeRequestRepo) FindByStudent(studentID string) ([]d.ResumeRequest, error) {
+	tx, err := r.begin()
+	if err != nil {
+		return []d.ResumeRequest{}, err
+	}
+
+	const selectQuery = `SELECT ref_student, ref_company, requested_by, requested_at, granted_at
+	FROM "Resume_Request" WHERE ref_student=$1 ORDER BY requested_at DESC;`
+	rows, err := tx.Query(selectQuery, studentID)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.ResumeRequest{}, err
+	}
+	defer rows.Close()
+
+	requests := []d.ResumeRequest{}
+	for rows.Next() {
+		var sID, cID, requestedBy string
+		var requestedAt time.Time
+		var grantedAt pq.NullTime
+
+		if err := rows.Scan(&sID, &cID, &requestedBy, &requestedAt, &grantedAt); err != nil {
+			_ = tx.Rollback()
+			return []d.ResumeRequest{}, err
+		}
+
+		requests = append(requests, d.ResumeRequest{
+			StudentID:   sID,
+			CompanyID:   cID,
+			RequestedBy: requestedBy,
+			RequestedAt: requestedAt,
+			GrantedAt:   grantedAt.Time,
+		})
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.ResumeRequest{}, err
+	}
+
+	return requests, nil
+}
+
+// Grant marks the request of the company for the resume of the student as granted.
+// It returns e.ErrorEntityNotFound if there is no such request. It should be used as a
+// single unit of work, as it has its own transaction inside.
+func (r ResumeRequestRepo) Grant(studentID, companyID string, at time.Time) error {
+	tx, err := r.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "Resume_Request" SET granted_at=COALESCE(granted_at, $1)
+	WHERE ref_student=$2 AND ref_company=$3;`
+	result, err := tx.Exec(updateQuery, at, studentID, companyID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		_ = tx.Rollback()
+		return e.ErrorEntityNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes the request of the company for the resume of the student from
+// the DB. It should be used as a single unit of work, as it has its own transaction inside.
+func (r ResumeRequestRepo) Delete(studentID, companyID string) error {
+	tx, err := r.begin()
+	if err != nil {
+		return err
+	}
+
+	const deleteQuery = `DELETE FROM "Resume_Request" WHERE ref_student=$1 AND ref_company=$2;`
+	_, err = tx.Exec(deleteQuery, studentID, companyID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
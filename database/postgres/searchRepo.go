@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"database/sql"
+	"html"
+	"strings"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// startSel and stopSel mark the matches in the headlines of postgres, which
+// are replaced by html tags once the rest of the headline has been escaped
+const (
+	startSel = "\x02"
+	stopSel  = "\x03"
+)
+
+// SearchRepo struct for postgres database. It searches
+// the search_vector columns of projects and companies.
+type SearchRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (s SearchRepo) begin() (Tx, error) {
+	return begin(s.DB, s.work)
+}
+
+// Search finds the projects and companies matching the keywords of the query, most
+// relevant first. Projects also match on the name and description of their company.
+// It should be used as a single unit of work, as it has its own transaction inside.
+func (s SearchRepo) Search(query d.SearchQuery) ([]d.SearchHit, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return []d.SearchHit{}, err
+	}
+
+	const selectQuery = `WITH q AS (SELECT plainto_tsquery('english', $1) AS query)
+	SELECT 'project', p.project_id, c.company_id, c.name,
+	ts_headline('english', p.description || ' ' || coalesce(array_to_string(p.recommendations, ' '), ''),
+	q.query, $3),
+	ts_rank(p.search_vector || c.search_vector, q.query) AS rank
+	FROM "Project" p JOIN "Company" c ON p.ref_company = c.company_id, q
+	WHERE (p.search_vector || c.search_vector) @@ q.query
+	UNION ALL
+	SELECT 'company', c.company_id, c.company_id, c.name,
+	ts_headline('english', c.description, q.query, $3),
+	ts_rank(c.search_vector, q.query) AS rank
+	FROM "Company" c, q
+	WHERE c.search_vector @@ q.query
+	ORDER BY rank DESC LIMIT $2;`
+	options := "StartSel=" + startSel + ", StopSel=" + stopSel + ", MaxFragments=2"
+	rows, err := tx.Query(selectQuery, query.Text, query.Limit, options)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.SearchHit{}, err
+	}
+	defer rows.Close()
+
+	hits := []d.SearchHit{}
+	for rows.Next() {
+		var hit d.SearchHit
+		err := rows.Scan(&hit.Kind, &hit.ID, &hit.CompanyID, &hit.Title, &hit.Snippet, &hit.Rank)
+		if err != nil {
+			_ = tx.Rollback()
+			return []d.SearchHit{}, err
+		}
+
+		hit.Snippet = highlight(hit.Snippet)
+		hits = append(hits, hit)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.SearchHit{}, err
+	}
+
+	return hits, nil
+}
+
+// helper func that escapes the headline of postgres, as it holds the text of
+// companies and projects, and only then turns the marked matches into bold text
+func highlight(headline string) string {
+	headline = html.EscapeString(headline)
+	return strings.NewReplacer(startSel, "<b>", stopSel, "</b>").Replace(headline)
+}
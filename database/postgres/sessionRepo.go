@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// SessionRepo struct for postgres database
+type SessionRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (s SessionRepo) begin() (Tx, error) {
+	return begin(s.DB, s.work)
+}
+
+// Create inserts a session in the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (s SessionRepo) Create(session d.Session) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	err = s.CreateTx(tx, session)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByTokenHash finds the session holding the refresh token with the provided hash.
+// It should be used as a single unit of work, as it has its own transaction inside.
+func (s SessionRepo) FindByTokenHash(tokenHash string) (d.Session, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return d.Session{}, err
+	}
+
+	const selectQuery = `SELECT s.session_id, s.family_id, s.ref_user, s.token_hash, s.created_at,
+	s.expires_at, s.used_at, s.revoked_at FROM "Session" s WHERE s.token_hash=$1;`
+	session, err := scanSession(tx.QueryRow(selectQuery, tokenHash))
+	if err != nil {
+		_ = tx.Rollback()
+		return d.Session{}, notFound(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.Session{}, err
+	}
+
+	return session, nil
+}
+
+// Rotate marks the old session as used and inserts its replacement, in one transaction.
+// If the old session has been used in the meantime, e.ErrorTokenReused is returned and
+// nothing is changed. It should be used as a single unit of work, as it has its own
+// transaction inside.
+func (s SessionRepo) Rotate(old, replacement d.Session) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	// the used_at check makes sure a token can't be rotated twice by concurrent requests
+	const updateQuery = `UPDATE "Session" SET used_at=$1 WHERE session_id=$2 AND used_at IS NULL;`
+	result, err := tx.Exec(updateQuery, replacement.CreatedAt, old.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if rows == 0 {
+		_ = tx.Rollback()
+		return e.ErrorTokenReused
+	}
+
+	err = s.CreateTx(tx, replacement)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes all sessions of the family that haven't been revoked yet. It
+// should be used as a single unit of work, as it has its own transaction inside.
+func (s SessionRepo) RevokeFamily(familyID string, at time.Time) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "Session" SET revoked_at=$1 WHERE family_id=$2 AND revoked_at IS NULL;`
+	_, err = tx.Exec(updateQuery, at, familyID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeByUser revokes all sessions of the user that haven't been revoked yet. It
+// should be used as a single unit of work, as it has its own transaction inside.
+func (s SessionRepo) RevokeByUser(userID string, at time.Time) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	err = s.RevokeByUserTx(tx, userID, at)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IsFamilyActive checks if the family still contains a session that hasn't been revoked
+// or expired. It should be used as a single unit of work, as it has its own transaction inside.
+func (s SessionRepo) IsFamilyActive(familyID string) (bool, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return false, err
+	}
+
+	const selectQuery = `SELECT EXISTS (SELECT 1 FROM "Session" s WHERE s.family_id=$1
+	AND s.revoked_at IS NULL AND s.expires_at > now());`
+	var active bool
+	err = tx.QueryRow(selectQuery, familyID).Scan(&active)
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return active, nil
+}
+
+// CreateTx inserts a session in the DB. It should be used as PART of a
+// unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (s SessionRepo) CreateTx(tx Tx, session d.Session) error {
+	const insertQuery = `INSERT INTO "Session"(session_id, family_id, ref_user, token_hash, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6);`
+	_, err := tx.Exec(insertQuery,
+		session.ID,
+		session.FamilyID,
+		session.UserID,
+		session.TokenHash,
+		session.CreatedAt,
+		session.ExpiresAt,
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RevokeByUserTx revokes all sessions of the user that haven't been revoked yet. It
+// should be used as PART of a unit of work, as a transaction gets passed in but will
+// not be committed. This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (s SessionRepo) RevokeByUserTx(tx Tx, userID string, at time.Time) error {
+	const updateQuery = `UPDATE "Session" SET revoked_at=$1 WHERE ref_user=$2 AND revoked_at IS NULL;`
+	_, err := tx.Exec(updateQuery, at, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// helper func to scan a single session row
+func scanSession(row scanner) (d.Session, error) {
+	var sID, familyID, userID, tokenHash string
+	var createdAt, expiresAt time.Time
+	var usedAt, revokedAt pq.NullTime
+
+	err := row.Scan(&sID, &familyID, &userID, &tokenHash, &createdAt, &expiresAt, &usedAt, &revokedAt)
+	if err != nil {
+		return d.Session{}, err
+	}
+
+	return d.Session{
+		ID:        sID,
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
+		UsedAt:    usedAt.Time,
+		RevokedAt: revokedAt.Time,
+	}, nil
+}
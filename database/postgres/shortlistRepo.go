@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ShortlistRepo struct for postgres database
+type ShortlistRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (s ShortlistRepo) begin() (Tx, error) {
+	return begin(s.DB, s.work)
+}
+
+// Create inserts a shortlist entry in the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (s ShortlistRepo) Create(entry d.ShortlistEntry) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	const insertQuery = `INSERT INTO "Shortlist_Entry"(shortlist_entry_id, ref_company, ref_student,
+	ref_project, notes, tags, added_by, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
+	_, err = tx.Exec(insertQuery,
+		entry.ID,
+		entry.CompanyID,
+		entry.StudentID,
+		sql.NullString{String: entry.ProjectID, Valid: entry.ProjectID != ""},
+		entry.Notes,
+		pq.Array(entry.Tags),
+		entry.AddedBy,
+		entry.CreatedAt,
+		entry.UpdatedAt,
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return conflict(err, e.ErrorAlreadyShortlisted)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByID finds a shortlist entry in the DB based on id. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (s ShortlistRepo) FindByID(id string) (d.ShortlistEntry, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return d.ShortlistEntry{}, err
+	}
+
+	const selectQuery = `SELECT s.shortlist_entry_id, s.ref_company, s.ref_student, s.ref_project,
+	s.notes, s.tags, s.added_by, s.created_at, s.updated_at
+	FROM "Shortlist_Entry" s WHERE s.shortlist_entry_id=$1;`
+	entry, err := scanShortlistEntry(tx.QueryRow(selectQuery, id))
+	if err != nil {
+		_ = tx.Rollback()
+		return d.ShortlistEntry{}, notFound(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.ShortlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// FindByCompany finds the whole shortlist of the company, most recently updated first.
+// It should be used as a single unit of work, as it has its own transaction inside.
+func (s ShortlistRepo) FindByCompany(companyID string) ([]d.ShortlistEntry, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return []d.ShortlistEntry{}, err
+	}
+
+	const selectQuery = `SELECT s.shortlist_entry_id, s.ref_company, s.ref_student, s.ref_project,
+	s.notes, s.tags, s.added_by, s.created_at, s.updated_at
+	FROM "Shortlist_Entry" s WHERE s.ref_company=$1 ORDER BY s.updated_at DESC;`
+	rows, err := tx.Query(selectQuery, companyID)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.ShortlistEntry{}, err
+	}
+	defer rows.Close()
+
+	entries := []d.ShortlistEntry{}
+	for rows.Next() {
+		entry, err := scanShortlistEntry(rows)
+		if err != nil {
+			_ = tx.Rollback()
+			return []d.ShortlistEntry{}, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return []d.ShortlistEntry{}, err
+	}
+
+	return entries, nil
+}
+
+// Update updates the notes, tags and project of a shortlist entry in the DB. It should
+// be used as a single unit of work, as it has its own transaction inside.
+func (s ShortlistRepo) Update(entry d.ShortlistEntry) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "Shortlist_Entry" s SET ref_project=$1, notes=$2, tags=$3, updated_at=$4
+	WHERE s.shortlist_entry_id=$5;`
+	_, err = tx.Exec(updateQuery,
+		sql.NullString{String: entry.ProjectID, Valid: entry.ProjectID != ""},
+		entry.Notes,
+		pq.Array(entry.Tags),
+		entry.UpdatedAt,
+		entry.ID,
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return conflict(err, e.ErrorAlreadyShortlisted)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes a shortlist entry from the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (s ShortlistRepo) Delete(id string) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	const deleteQuery = `DELETE FROM "Shortlist_Entry" WHERE shortlist_entry_id=$1;`
+	_, err = tx.Exec(deleteQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// helper func to scan a single shortlist entry row
+func scanShortlistEntry(row scanner) (d.ShortlistEntry, error) {
+	var sID, cID, studentID, notes, addedBy string
+	var projectID sql.NullString
+	var tags []string
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(&sID, &cID, &studentID, &projectID, &notes, pq.Array(&tags), &addedBy, &createdAt, &updatedAt)
+	if err != nil {
+		return d.ShortlistEntry{}, err
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return d.ShortlistEntry{
+		ID:        sID,
+		CompanyID: cID,
+		StudentID: studentID,
+		ProjectID: projectID.String,
+		Notes:     notes,
+		Tags:      tags,
+		AddedBy:   addedBy,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}, nil
+}
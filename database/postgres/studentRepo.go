@@ -2,22 +2,31 @@ package postgres
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
 
 	"github.com/lib/pq"
 
 	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // StudentRepo struct for postgres database
 type StudentRepo struct {
 	DB       *sql.DB
 	UserRepo UserRepo
+	work     *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (s StudentRepo) begin() (Tx, error) {
+	return begin(s.DB, s.work)
 }
 
 // Create inserts a student in the DB. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (s StudentRepo) Create(student d.Student) error {
-	tx, err := s.DB.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -38,7 +47,7 @@ func (s StudentRepo) Create(student d.Student) error {
 // Update updates a student in the DB. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (s StudentRepo) Update(student d.Student) error {
-	tx, err := s.DB.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return err
 	}
@@ -56,10 +65,65 @@ func (s StudentRepo) Update(student d.Student) error {
 	return nil
 }
 
+// Delete deletes a student and everything tied to them from the DB, anonymising the
+// messages they sent. It should be used as a single unit of work, as it has its own
+// transaction inside.
+func (s StudentRepo) Delete(student d.Student) error {
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	err = s.DeleteTx(tx, student)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePrivacy updates the privacy settings of a student in the DB
+func (s StudentRepo) UpdatePrivacy(studentID string, visibility d.Visibility, resumeOnRequest bool) error {
+	const updateQuery = `UPDATE "Student" SET visibility=$1, resume_on_request=$2 WHERE student_id=$3;`
+	tx, err := s.begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(updateQuery, visibility, resumeOnRequest, studentID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		_ = tx.Rollback()
+		return e.ErrorEntityNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // FindByID finds a student in the DB based on id. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (s StudentRepo) FindByID(id string) (d.Student, error) {
-	tx, err := s.DB.Begin()
+	tx, err := s.begin()
 	if err != nil {
 		return d.Student{}, err
 	}
@@ -77,82 +141,48 @@ func (s StudentRepo) FindByID(id string) (d.Student, error) {
 	return student, nil
 }
 
-// FindAll finds all the students in the DB based. It should be used as a single
-// unit of work, as it has its own transaction inside.
-func (s StudentRepo) FindAll() ([]d.Student, error) {
-	tx, err := s.DB.Begin()
-	if err != nil {
-		return []d.Student{}, err
-	}
-
-	const selectQuery = `SELECT s.student_id, s.university, s.skills, s.experiences, s.short_experiences, 
-	s.wishes, s.status, s.resume, u.user_id, u.first_name, u.last_name, u.email, u.role 
-	FROM "Student" s JOIN "User" u ON s.ref_user = u.user_id ORDER BY RANDOM();`
+// FindAll finds a page of all the students in the DB, ordered on id. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (s StudentRepo) FindAll(page d.Page) ([]d.Student, string, error) {
+	return s.FindByQuery(d.StudentQuery{}, page)
+}
 
-	rows, err := tx.Query(selectQuery)
+// FindByQuery finds a page of the students in the DB that match the provided query, ordered
+// on id. It should be used as a single unit of work, as it has its own transaction inside.
+func (s StudentRepo) FindByQuery(query d.StudentQuery, page d.Page) ([]d.Student, string, error) {
+	tx, err := s.begin()
 	if err != nil {
-		_ = tx.Rollback()
-		return []d.Student{}, err
+		return []d.Student{}, "", err
 	}
-	defer rows.Close()
 
-	students := []d.Student{}
-	for rows.Next() {
-		var (
-			sID, uni, resume, wishes              string
-			uID, fname, lname, email, role        string
-			skills, experiences, shortExperiences []string
-			status                                d.Status
-		)
-
-		if err := rows.Scan(&sID, &uni, pq.Array(&skills),
-			pq.Array(&experiences), pq.Array(&shortExperiences), &wishes,
-			&status, &resume, &uID, &fname, &lname, &email, &role); err != nil {
-			_ = tx.Rollback()
-			return []d.Student{}, err
-		}
-
-		students = append(students, d.Student{
-			ID:               sID,
-			University:       uni,
-			Skills:           skills,
-			Experiences:      experiences,
-			ShortExperiences: shortExperiences,
-			Wishes:           wishes,
-			Status:           status,
-			Resume:           resume,
-			User: d.User{
-				ID:        uID,
-				Email:     email,
-				FirstName: fname,
-				LastName:  lname,
-				Role:      role,
-			},
-		})
+	students, next, err := s.FindByQueryTx(tx, query, page)
+	if err != nil {
+		return []d.Student{}, "", err
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return []d.Student{}, err
+		return []d.Student{}, "", err
 	}
 
-	return students, nil
+	return students, next, nil
 }
 
 // CreateTx inserts a student in the DB. It should be used as PART of a
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (s StudentRepo) CreateTx(tx *sql.Tx, student d.Student) error {
+func (s StudentRepo) CreateTx(tx Tx, student d.Student) error {
 	err := s.UserRepo.CreateTx(tx, student.User)
 	if err != nil {
 		return err
 	}
 
-	const insertQuery = `INSERT INTO "Student"(student_id, university, skills, experiences, short_experiences, wishes, status, resume, ref_user) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
+	const insertQuery = `INSERT INTO "Student"(student_id, university, city, skills, experiences, short_experiences, wishes, status, resume, ref_user, visibility, resume_on_request) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`
 	_, err = tx.Exec(insertQuery,
 		student.ID,
 		student.University,
+		student.City,
 		pq.Array(student.Skills),
 		pq.Array(student.Experiences),
 		pq.Array(student.ShortExperiences),
@@ -160,6 +190,8 @@ func (s StudentRepo) CreateTx(tx *sql.Tx, student d.Student) error {
 		student.Status,
 		student.Resume,
 		student.User.ID,
+		student.Visibility,
+		student.ResumeOnRequest,
 	)
 
 	if err != nil {
@@ -174,11 +206,12 @@ func (s StudentRepo) CreateTx(tx *sql.Tx, student d.Student) error {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (s StudentRepo) UpdateTx(tx *sql.Tx, student d.Student) error {
+func (s StudentRepo) UpdateTx(tx Tx, student d.Student) error {
 	const updateStudentQuery = `UPDATE "Student" s 
-	SET university=$1, skills=$2, experiences=$3, short_experiences=$4, wishes=$5, status=$6, resume=$7 WHERE s.student_id=$8;`
+	SET university=$1, city=$2, skills=$3, experiences=$4, short_experiences=$5, wishes=$6, status=$7, resume=$8 WHERE s.student_id=$9;`
 	_, err := tx.Exec(updateStudentQuery,
 		student.University,
+		student.City,
 		pq.Array(student.Skills),
 		pq.Array(student.Experiences),
 		pq.Array(student.ShortExperiences),
@@ -194,7 +227,9 @@ func (s StudentRepo) UpdateTx(tx *sql.Tx, student d.Student) error {
 	}
 
 	// todo: move this code to UserRepo and call it Update()
-	const updateUserQuery = `UPDATE "User" u SET first_name=$1, last_name=$2, email=$3
+	// a changed email has to be verified again
+	const updateUserQuery = `UPDATE "User" u SET first_name=$1, last_name=$2, email=$3,
+	email_verified=(u.email=$3 AND u.email_verified)
 	WHERE u.user_id=(SELECT ref_user FROM "Student" WHERE student_id=$4);`
 	_, err = tx.Exec(updateUserQuery,
 		student.User.FirstName,
@@ -211,36 +246,187 @@ func (s StudentRepo) UpdateTx(tx *sql.Tx, student d.Student) error {
 	return nil
 }
 
+// DeleteTx deletes a student and everything tied to them from the DB, anonymising the
+// messages they sent. Sessions and tokens of their user, and the mails sent to
+// them, are deleted along with it.
+// It should be used as PART of a unit of work, as a transaction gets passed in but will
+// not be committed. This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (s StudentRepo) DeleteTx(tx Tx, student d.Student) error {
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`UPDATE "Message" SET sender=NULL, body=$1 WHERE sender=$2;`, []interface{}{d.DeletedMessageBody, student.User.ID}},
+		{`UPDATE "Message" SET receiver=NULL WHERE receiver=$1;`, []interface{}{student.User.ID}},
+		{`UPDATE "Conversation" SET started_by=NULL WHERE started_by=$1;`, []interface{}{student.User.ID}},
+		{`DELETE FROM "Conversation_Participant" WHERE ref_user=$1;`, []interface{}{student.User.ID}},
+		{`DELETE FROM "Application" WHERE ref_student=$1;`, []interface{}{student.ID}},
+		{`DELETE FROM "Shortlist_Entry" WHERE ref_student=$1;`, []interface{}{student.ID}},
+		{`DELETE FROM "Block" WHERE ref_student=$1;`, []interface{}{student.ID}},
+		{`DELETE FROM "Resume_Request" WHERE ref_student=$1;`, []interface{}{student.ID}},
+		{`DELETE FROM "Mail_Outbox" WHERE recipient=$1;`, []interface{}{student.User.Email}},
+		{`DELETE FROM "Student" WHERE student_id=$1;`, []interface{}{student.ID}},
+		{`DELETE FROM "User" WHERE user_id=$1;`, []interface{}{student.User.ID}},
+	}
+
+	for _, statement := range statements {
+		_, err := tx.Exec(statement.query, statement.args...)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return nil
+}
+
 // FindByIDTx finds a student in the DB based on id. It should be used as PART of a
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (s StudentRepo) FindByIDTx(tx *sql.Tx, id string) (d.Student, error) {
-	var uID, fname, lname, email, role, resume string
-	var sID, uni, wishes string
-	var skills, exp, shortExp []string
-	var status d.Status
-
-	const selectQuery = `SELECT student_id, s.university, s.skills, s.experiences, s.short_experiences, s.wishes, s.status, s.resume,
-	user_id, u.first_name, u.last_name, u.email, u.role FROM "Student" s JOIN "User" u ON s.ref_user = u.user_id
+func (s StudentRepo) FindByIDTx(tx Tx, id string) (d.Student, error) {
+	const selectQuery = `SELECT student_id, s.university, s.city, s.skills, s.experiences, s.short_experiences, s.wishes, s.status, s.resume,
+	s.visibility, s.resume_on_request, user_id, u.first_name, u.last_name, u.email, u.role FROM "Student" s JOIN "User" u ON s.ref_user = u.user_id
 	WHERE student_id=$1;`
 	result := tx.QueryRow(selectQuery, id)
 
-	err := result.Scan(&sID, &uni, pq.Array(&skills), pq.Array(&exp), pq.Array(&shortExp), &wishes, &status, &resume, &uID, &fname, &lname, &email, &role)
+	student, err := scanStudent(result)
 	if err != nil {
 		_ = tx.Rollback()
+		return d.Student{}, notFound(err)
+	}
+
+	return student, nil
+}
+
+// FindByQueryTx finds a page of the students in the DB that match the provided query, ordered
+// on id. Skills are compared case insensitively using array operators, the other fields are
+// matched on substrings. One student more than the limit is fetched to know if there is a
+// next page. It should be used as PART of a unit of work, as a transaction gets passed in
+// but will not be committed. This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (s StudentRepo) FindByQueryTx(tx Tx, query d.StudentQuery, page d.Page) ([]d.Student, string, error) {
+	selectQuery := `SELECT s.student_id, s.university, s.city, s.skills, s.experiences, s.short_experiences, 
+	s.wishes, s.status, s.resume, s.visibility, s.resume_on_request, u.user_id, u.first_name, u.last_name, u.email, u.role 
+	FROM "Student" s JOIN "User" u ON s.ref_user = u.user_id WHERE TRUE`
+
+	args := []interface{}{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(query.Skills) > 0 {
+		skills := []string{}
+		for _, skill := range query.Skills {
+			skills = append(skills, strings.ToLower(skill))
+		}
+
+		operator := "&&"
+		if query.AllSkills {
+			operator = "@>"
+		}
+
+		selectQuery += ` AND ARRAY(SELECT lower(skill) FROM unnest(s.skills) skill) ` + operator + ` ` + arg(pq.Array(skills))
+	}
+
+	if query.University != "" {
+		selectQuery += ` AND s.university ILIKE ` + arg(containsPattern(query.University))
+	}
+
+	if query.City != "" {
+		selectQuery += ` AND s.city ILIKE ` + arg(containsPattern(query.City))
+	}
+
+	if query.Status != nil {
+		selectQuery += ` AND s.status = ` + arg(*query.Status)
+	}
+
+	if query.Text != "" {
+		selectQuery += ` AND concat_ws(' ', array_to_string(s.experiences, ' '),
+		array_to_string(s.short_experiences, ' '), s.wishes) ILIKE ` + arg(containsPattern(query.Text))
+	}
+
+	if query.VisibleTo != "" {
+		selectQuery += ` AND (s.visibility = ` + arg(d.VisibleToRepresentatives) + `
+		OR (s.visibility = ` + arg(d.VisibleToAppliedCompanies) + ` AND EXISTS (
+			SELECT 1 FROM "Application" a JOIN "Project" p ON a.ref_project = p.project_id
+			WHERE a.ref_student = s.student_id AND p.ref_company = ` + arg(query.VisibleTo) + `)))`
+	}
+
+	if page.After != "" {
+		selectQuery += ` AND s.student_id::text > ` + arg(page.After)
+	}
+
+	selectQuery += ` ORDER BY s.student_id::text LIMIT ` + arg(page.Limit+1) + `;`
+	students, err := s.findManyTx(tx, selectQuery, args...)
+	if err != nil {
+		return []d.Student{}, "", err
+	}
+
+	next := ""
+	if len(students) > page.Limit {
+		students = students[:page.Limit]
+		next = students[page.Limit-1].ID
+	}
+
+	return students, next, nil
+}
+
+// helper func that runs the provided query, which selects a list of students
+func (s StudentRepo) findManyTx(tx Tx, query string, args ...interface{}) ([]d.Student, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		return []d.Student{}, err
+	}
+	defer rows.Close()
+
+	students := []d.Student{}
+	for rows.Next() {
+		student, err := scanStudent(rows)
+		if err != nil {
+			_ = tx.Rollback()
+			return []d.Student{}, err
+		}
+
+		students = append(students, student)
+	}
+
+	return students, nil
+}
+
+// helper func to scan a single student row, joined with its user
+func scanStudent(row scanner) (d.Student, error) {
+	var (
+		sID, uni, city, resume, wishes        string
+		uID, fname, lname, email, role        string
+		skills, experiences, shortExperiences []string
+		status                                d.Status
+		visibility                            d.Visibility
+		resumeOnRequest                       bool
+	)
+
+	err := row.Scan(&sID, &uni, &city, pq.Array(&skills),
+		pq.Array(&experiences), pq.Array(&shortExperiences), &wishes,
+		&status, &resume, &visibility, &resumeOnRequest, &uID, &fname, &lname, &email, &role)
+	if err != nil {
 		return d.Student{}, err
 	}
 
 	return d.Student{
 		ID:               sID,
 		University:       uni,
+		City:             city,
 		Skills:           skills,
-		Experiences:      exp,
-		ShortExperiences: shortExp,
+		Experiences:      experiences,
+		ShortExperiences: shortExperiences,
 		Wishes:           wishes,
 		Status:           status,
 		Resume:           resume,
+		Visibility:       visibility,
+		ResumeOnRequest:  resumeOnRequest,
 		User: d.User{
 			ID:        uID,
 			Email:     email,
@@ -250,3 +436,12 @@ func (s StudentRepo) FindByIDTx(tx *sql.Tx, id string) (d.Student, error) {
 		},
 	}, nil
 }
+
+// helper func that creates an ILIKE pattern matching any text
+// containing the provided value, escaping the wildcards in it
+func containsPattern(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "%", `\%`, -1)
+	value = strings.Replace(value, "_", `\_`, -1)
+	return "%" + value + "%"
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"database/sql"
+
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// Tx is the transaction the queries of a repo run in. It is either a
+// transaction of its own, or the one of the unit of work it takes part in.
+type Tx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Commit() error
+	Rollback() error
+}
+
+// joinedTx is the transaction of a unit of work, as seen by the repos taking
+// part in it. Only the unit of work ends it, so committing and rolling back
+// are left to it. Postgres aborts a transaction when one of its queries
+// fails, so a unit of work can't commit the changes made before a failure.
+type joinedTx struct {
+	*sql.Tx
+}
+
+// Commit leaves committing to the unit of work
+func (j joinedTx) Commit() error {
+	return nil
+}
+
+// Rollback leaves rolling back to the unit of work
+func (j joinedTx) Rollback() error {
+	return nil
+}
+
+// helper func that begins a transaction on the db, or joins the
+// transaction of the unit of work when the repo takes part in one
+func begin(db *sql.DB, work *sql.Tx) (Tx, error) {
+	if work != nil {
+		return joinedTx{work}, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// UnitOfWork struct for postgres database,
+// running use cases in a single transaction
+type UnitOfWork struct {
+	DB *sql.DB
+}
+
+// Do calls fn with repositories that all run their queries in the same
+// transaction, which is committed if fn returns nil and rolled back otherwise
+func (u UnitOfWork) Do(fn func(repos d.Repositories) error) error {
+	tx, err := u.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn(repositories(u.DB, tx))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewRepositories creates all repositories on top of the db
+func NewRepositories(db *sql.DB) d.Repositories {
+	return repositories(db, nil)
+}
+
+// helper func that creates all repositories, which take part in
+// the transaction of a unit of work when work isn't nil
+func repositories(db *sql.DB, work *sql.Tx) d.Repositories {
+	userRepo := UserRepo{DB: db, work: work}
+	outboxRepo := OutboxRepo{DB: db, work: work}
+	sessionRepo := SessionRepo{DB: db, work: work}
+	reprRepo := RepresentativeRepo{DB: db, UserRepo: userRepo, SessionRepo: sessionRepo, work: work}
+
+	return d.Repositories{
+		Users:           userRepo,
+		Students:        StudentRepo{DB: db, UserRepo: userRepo, work: work},
+		Representatives: reprRepo,
+		Companies:       CompanyRepo{DB: db, ReprRepo: reprRepo, work: work},
+		Projects:        ProjectRepo{DB: db, work: work},
+		InviteLinks:     InviteLinkRepo{DB: db, Outbox: outboxRepo, work: work},
+		Conversations:   ConversationRepo{DB: db, work: work},
+		Messages:        MessageRepo{DB: db, Outbox: outboxRepo, work: work},
+		Blocks:          BlockRepo{DB: db, work: work},
+		Applications:    ApplicationRepo{DB: db, Outbox: outboxRepo, work: work},
+		Shortlist:       ShortlistRepo{DB: db, work: work},
+		ResumeRequests:  ResumeRequestRepo{DB: db, work: work},
+		Search:          SearchRepo{DB: db, work: work},
+		Sessions:        sessionRepo,
+		UserTokens:      UserTokenRepo{DB: db, work: work},
+		Outbox:          outboxRepo,
+	}
+}
@@ -10,13 +10,19 @@ import (
 
 // UserRepo struct for postgres db
 type UserRepo struct {
-	DB *sql.DB
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (u UserRepo) begin() (Tx, error) {
+	return begin(u.DB, u.work)
 }
 
 // Create inserts a user in the DB. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (u UserRepo) Create(user d.User) error {
-	tx, err := u.DB.Begin()
+	tx, err := u.begin()
 	if err != nil {
 		return err
 	}
@@ -40,7 +46,7 @@ func (u UserRepo) Create(user d.User) error {
 // account. It should be used as a single unit of work,
 // as it has its own transaction inside
 func (u UserRepo) FindRoleID(user d.User) (string, error) {
-	tx, err := u.DB.Begin()
+	tx, err := u.begin()
 	if err != nil {
 		return "", err
 	}
@@ -53,15 +59,16 @@ func (u UserRepo) FindRoleID(user d.User) (string, error) {
 		err = result.Scan(&roleID)
 		if err != nil {
 			_ = tx.Rollback()
-			return "", err
+			return "", notFound(err)
 		}
 	} else if user.Role == d.RepresentativeRole {
-		const query = `SELECT representative_id FROM "Representative" WHERE ref_user=$1;`
+		// representatives that have been removed from their company no longer have a role
+		const query = `SELECT representative_id FROM "Representative" WHERE ref_user=$1 AND removed_at IS NULL;`
 		result := tx.QueryRow(query, user.ID)
 		err = result.Scan(&roleID)
 		if err != nil {
 			_ = tx.Rollback()
-			return "", err
+			return "", notFound(err)
 		}
 	}
 
@@ -76,7 +83,7 @@ func (u UserRepo) FindRoleID(user d.User) (string, error) {
 // FindByID finds a user in the DB based on id. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (u UserRepo) FindByID(id string) (d.User, error) {
-	tx, err := u.DB.Begin()
+	tx, err := u.begin()
 	if err != nil {
 		return d.User{}, err
 	}
@@ -97,7 +104,7 @@ func (u UserRepo) FindByID(id string) (d.User, error) {
 // FindByEmail finds a user in the DB based on email. It should be used as a single
 // unit of work, as it has its own transaction inside.
 func (u UserRepo) FindByEmail(email string) (d.User, error) {
-	tx, err := u.DB.Begin()
+	tx, err := u.begin()
 	if err != nil {
 		return d.User{}, err
 	}
@@ -115,13 +122,55 @@ func (u UserRepo) FindByEmail(email string) (d.User, error) {
 	return user, nil
 }
 
+// UpdatePassword replaces the hashed password of the user in the DB. It should be
+// used as a single unit of work, as it has its own transaction inside.
+func (u UserRepo) UpdatePassword(userID, hashedPassword string) error {
+	tx, err := u.begin()
+	if err != nil {
+		return err
+	}
+
+	err = u.UpdatePasswordTx(tx, userID, hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkEmailVerified marks the email of the user in the DB as verified. It should be
+// used as a single unit of work, as it has its own transaction inside.
+func (u UserRepo) MarkEmailVerified(userID string) error {
+	tx, err := u.begin()
+	if err != nil {
+		return err
+	}
+
+	err = u.MarkEmailVerifiedTx(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateTx inserts a user in the DB. It should be used as PART of a
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (u UserRepo) CreateTx(tx *sql.Tx, user d.User) error {
+func (u UserRepo) CreateTx(tx Tx, user d.User) error {
 	const insertQuery = `INSERT INTO "User"(user_id, first_name, last_name, email, 
-		hashed_password, role) VALUES($1, $2, $3, $4, $5, $6);`
+		hashed_password, role, email_verified) VALUES($1, $2, $3, $4, $5, $6, $7);`
 	_, err := tx.Exec(insertQuery,
 		user.ID,
 		user.FirstName,
@@ -129,6 +178,7 @@ func (u UserRepo) CreateTx(tx *sql.Tx, user d.User) error {
 		user.Email,
 		user.HashedPassword,
 		user.Role,
+		user.EmailVerified,
 	)
 
 	if err != nil {
@@ -147,15 +197,17 @@ func (u UserRepo) CreateTx(tx *sql.Tx, user d.User) error {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (u UserRepo) FindByIDTx(tx *sql.Tx, id string) (d.User, error) {
+func (u UserRepo) FindByIDTx(tx Tx, id string) (d.User, error) {
 	var uID, fname, lname, email, hash, role string
-	const selectQuery = `SELECT user_id, first_name, last_name, email, role FROM "User" WHERE user_id = $1;`
+	var verified bool
+	const selectQuery = `SELECT user_id, first_name, last_name, email, hashed_password, role, 
+	email_verified FROM "User" WHERE user_id = $1;`
 	result := tx.QueryRow(selectQuery, id)
 
-	err := result.Scan(&uID, &fname, &lname, &email, &hash, &role)
+	err := result.Scan(&uID, &fname, &lname, &email, &hash, &role, &verified)
 	if err != nil {
 		_ = tx.Rollback()
-		return d.User{}, err
+		return d.User{}, notFound(err)
 	}
 
 	return d.User{
@@ -165,6 +217,7 @@ func (u UserRepo) FindByIDTx(tx *sql.Tx, id string) (d.User, error) {
 		FirstName:      fname,
 		LastName:       lname,
 		Role:           role,
+		EmailVerified:  verified,
 	}, nil
 }
 
@@ -172,15 +225,17 @@ func (u UserRepo) FindByIDTx(tx *sql.Tx, id string) (d.User, error) {
 // unit of work, as a transaction gets passed in but will not be committed.
 // This is the responsibility of the caller.
 // It will rollback and return an error if something goes wrong
-func (u UserRepo) FindByEmailTx(tx *sql.Tx, email string) (d.User, error) {
+func (u UserRepo) FindByEmailTx(tx Tx, email string) (d.User, error) {
 	var uID, fname, lname, uEmail, hash, role string
-	const selectQuery = `SELECT user_id, first_name, last_name, email, hashed_password, role FROM "User" WHERE email=$1;`
+	var verified bool
+	const selectQuery = `SELECT user_id, first_name, last_name, email, hashed_password, role, 
+	email_verified FROM "User" WHERE email=$1;`
 	result := tx.QueryRow(selectQuery, email)
 
-	err := result.Scan(&uID, &fname, &lname, &uEmail, &hash, &role)
+	err := result.Scan(&uID, &fname, &lname, &uEmail, &hash, &role, &verified)
 	if err != nil {
 		_ = tx.Rollback()
-		return d.User{}, err
+		return d.User{}, notFound(err)
 	}
 
 	return d.User{
@@ -190,5 +245,36 @@ func (u UserRepo) FindByEmailTx(tx *sql.Tx, email string) (d.User, error) {
 		FirstName:      fname,
 		LastName:       lname,
 		Role:           role,
+		EmailVerified:  verified,
 	}, nil
 }
+
+// UpdatePasswordTx replaces the hashed password of the user in the DB. It should be used
+// as PART of a unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (u UserRepo) UpdatePasswordTx(tx Tx, userID, hashedPassword string) error {
+	const updateQuery = `UPDATE "User" SET hashed_password=$1 WHERE user_id=$2;`
+	_, err := tx.Exec(updateQuery, hashedPassword, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// MarkEmailVerifiedTx marks the email of the user in the DB as verified. It should be used
+// as PART of a unit of work, as a transaction gets passed in but will not be committed.
+// This is the responsibility of the caller.
+// It will rollback and return an error if something goes wrong
+func (u UserRepo) MarkEmailVerifiedTx(tx Tx, userID string) error {
+	const updateQuery = `UPDATE "User" SET email_verified=TRUE WHERE user_id=$1;`
+	_, err := tx.Exec(updateQuery, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// UserTokenRepo struct for postgres database
+type UserTokenRepo struct {
+	DB   *sql.DB
+	work *sql.Tx // the transaction of the unit of work the repo takes part in, if any
+}
+
+// begin begins the transaction the queries of the repo run in
+func (u UserTokenRepo) begin() (Tx, error) {
+	return begin(u.DB, u.work)
+}
+
+// Create inserts a user token in the DB. It should be used as a single
+// unit of work, as it has its own transaction inside.
+func (u UserTokenRepo) Create(token d.UserToken) error {
+	tx, err := u.begin()
+	if err != nil {
+		return err
+	}
+
+	const insertQuery = `INSERT INTO "User_Token"(user_token_id, ref_user, purpose, token_hash,
+	created_at, expiry_date, used) VALUES ($1, $2, $3, $4, $5, $6, $7);`
+	_, err = tx.Exec(insertQuery,
+		token.ID,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.CreatedAt,
+		token.ExpiryDate,
+		token.Used,
+	)
+
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByHash finds the user token with the provided hash. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (u UserTokenRepo) FindByHash(tokenHash string) (d.UserToken, error) {
+	tx, err := u.begin()
+	if err != nil {
+		return d.UserToken{}, err
+	}
+
+	var tID, userID, hash string
+	var purpose d.TokenPurpose
+	var createdAt, expiryDate time.Time
+	var used bool
+
+	const selectQuery = `SELECT t.user_token_id, t.ref_user, t.purpose, t.token_hash, t.created_at,
+	t.expiry_date, t.used FROM "User_Token" t WHERE t.token_hash=$1;`
+	err = tx.QueryRow(selectQuery, tokenHash).Scan(&tID, &userID, &purpose, &hash, &createdAt, &expiryDate, &used)
+	if err != nil {
+		_ = tx.Rollback()
+		return d.UserToken{}, notFound(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return d.UserToken{}, err
+	}
+
+	return d.UserToken{
+		ID:         tID,
+		UserID:     userID,
+		Purpose:    purpose,
+		TokenHash:  hash,
+		CreatedAt:  createdAt,
+		ExpiryDate: expiryDate,
+		Used:       used,
+	}, nil
+}
+
+// MarkUsed marks the user token as used. If it has already been used, e.ErrorInvalidToken
+// is returned, so a token can't be used twice by concurrent requests. It should be used
+// as a single unit of work, as it has its own transaction inside.
+func (u UserTokenRepo) MarkUsed(id string) error {
+	tx, err := u.begin()
+	if err != nil {
+		return err
+	}
+
+	const updateQuery = `UPDATE "User_Token" SET used=TRUE WHERE user_token_id=$1 AND used=FALSE;`
+	result, err := tx.Exec(updateQuery, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if rows == 0 {
+		_ = tx.Rollback()
+		return e.ErrorInvalidToken
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
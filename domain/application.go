@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ApplicationStatus type for conveying the
+// status an application of a student can have
+type ApplicationStatus string
+
+const (
+	// ApplicationSubmitted indicates the student has applied
+	ApplicationSubmitted ApplicationStatus = "submitted"
+
+	// ApplicationUnderReview indicates the company is looking into the application
+	ApplicationUnderReview ApplicationStatus = "under review"
+
+	// ApplicationInterview indicates the student has been invited for an interview
+	ApplicationInterview ApplicationStatus = "interview"
+
+	// ApplicationOffered indicates the company has offered the student the project
+	ApplicationOffered ApplicationStatus = "offered"
+
+	// ApplicationAccepted indicates the student has accepted the offer
+	ApplicationAccepted ApplicationStatus = "accepted"
+
+	// ApplicationRejected indicates the company has rejected the student
+	ApplicationRejected ApplicationStatus = "rejected"
+
+	// ApplicationWithdrawn indicates the student has withdrawn the application
+	ApplicationWithdrawn ApplicationStatus = "withdrawn"
+)
+
+// transitions contains the statuses an application
+// can move to, from the status used as key
+var transitions = map[ApplicationStatus][]ApplicationStatus{
+	ApplicationSubmitted:   {ApplicationUnderReview, ApplicationRejected, ApplicationWithdrawn},
+	ApplicationUnderReview: {ApplicationInterview, ApplicationOffered, ApplicationRejected, ApplicationWithdrawn},
+	ApplicationInterview:   {ApplicationOffered, ApplicationRejected, ApplicationWithdrawn},
+	ApplicationOffered:     {ApplicationAccepted, ApplicationRejected, ApplicationWithdrawn},
+}
+
+// ApplicationRepository interface
+type ApplicationRepository interface {
+	Create(application Application) error
+	FindByID(id string) (Application, error)
+	FindByStudent(studentID string) ([]Application, error)
+	FindByProject(projectID string) ([]Application, error)
+	FindByStudentAndProject(studentID, projectID string) (Application, error)
+	Update(application Application) error
+
+	// UpdateWithMail updates the application and queues the mail
+	// notifying the student, which only happens if both succeed
+	UpdateWithMail(application Application, mail OutboxMail) error
+}
+
+// Application struct conveying the application
+// of a student to a project of a company
+type Application struct {
+	ID         string
+	StudentID  string
+	ProjectID  string
+	Motivation string
+	Resume     string // path to a resume used instead of the student's resume (optional)
+	Status     ApplicationStatus
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NewApplication creates a new, submitted application based on
+// the provided input if all is valid, returning an error otherwise.
+// The resume may be left empty to use the resume of the student.
+func NewApplication(id, studentID, projectID, motivation, resume string) (Application, error) {
+	if len(strings.TrimSpace(studentID)) == 0 {
+		return Application{}, e.Invalid("student", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(projectID)) == 0 {
+		return Application{}, e.Invalid("project", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(motivation)) == 0 {
+		return Application{}, e.Invalid("motivation", "can't be empty")
+	}
+
+	now := time.Now()
+	return Application{
+		ID:         id,
+		StudentID:  studentID,
+		ProjectID:  projectID,
+		Motivation: motivation,
+		Resume:     resume,
+		Status:     ApplicationSubmitted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// CanMoveTo checks if the application is allowed
+// to move from its current status to the provided status
+func (a Application) CanMoveTo(status ApplicationStatus) bool {
+	for _, s := range transitions[a.Status] {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MoveTo moves the application to the provided status,
+// returning an error if this transition is not allowed
+func (a *Application) MoveTo(status ApplicationStatus) error {
+	if !a.CanMoveTo(status) {
+		return e.New(e.Conflict, "application can't move from "+string(a.Status)+" to "+string(status))
+	}
+
+	a.Status = status
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsClosed checks if the application has reached a final status
+func (a Application) IsClosed() bool {
+	return len(transitions[a.Status]) == 0
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Block struct conveying a student that blocked
+// a company, so the representatives of this company
+// can't start new conversations with the student
+type Block struct {
+	StudentID string
+	CompanyID string
+	CreatedAt time.Time
+}
+
+// BlockRepository interface
+type BlockRepository interface {
+	Create(block Block) error
+	Delete(studentID, companyID string) error
+	Exists(studentID, companyID string) (bool, error)
+	FindByStudent(studentID string) ([]Block, error)
+}
+
+// NewBlock creates a new block based on the provided
+// input if all is valid, returning an error otherwise
+func NewBlock(studentID, companyID string) (Block, error) {
+	if len(strings.TrimSpace(studentID)) == 0 {
+		return Block{}, e.Invalid("student", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(companyID)) == 0 {
+		return Block{}, e.Invalid("company", "can't be empty")
+	}
+
+	return Block{
+		StudentID: studentID,
+		CompanyID: companyID,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -1,9 +1,10 @@
 package domain
 
 import (
-	"errors"
 	"regexp"
 	"strings"
+
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // todo: look into which functions i want the different structs to have
@@ -17,7 +18,7 @@ import (
 // CompanyRepository interface
 type CompanyRepository interface {
 	Create(company Company) error
-	FindAll() ([]Company, error)
+	FindAll(page Page) ([]Company, string, error)
 	FindByID(id string) (Company, error)
 	FindByName(name string) (Company, error)
 	AddProject(p Project) error
@@ -41,15 +42,15 @@ type Company struct {
 // an error otherwise
 func NewCompany(id, name, info, descr string) (Company, error) {
 	if len(strings.TrimSpace(name)) == 0 {
-		return Company{}, errors.New("provided name can't be empty")
+		return Company{}, e.Invalid("name", "can't be empty")
 	}
 
 	if len(strings.TrimSpace(info)) == 0 {
-		return Company{}, errors.New("provided information can't be empty")
+		return Company{}, e.Invalid("information", "can't be empty")
 	}
 
 	if len(strings.TrimSpace(descr)) == 0 {
-		return Company{}, errors.New("provided description can't be empty")
+		return Company{}, e.Invalid("description", "can't be empty")
 	}
 
 	return Company{
@@ -78,20 +79,20 @@ type Address struct {
 // an error otherwise
 func NewAddress(id, street, zipcode, city, number string) (Address, error) {
 	if len(strings.TrimSpace(street)) == 0 {
-		return Address{}, errors.New("provided street can't be empty")
+		return Address{}, e.Invalid("street", "can't be empty")
 	}
 
 	r := regexp.MustCompile(`^\d{4}\s[A-Z]{2}$`)
 	if !r.MatchString(zipcode) {
-		return Address{}, errors.New("provided zipcode is invalid, should be of format 0000 XX, where 0 can be any number and X can be any letter")
+		return Address{}, e.Invalid("zipcode", "is invalid, should be of format 0000 XX, where 0 can be any number and X can be any letter")
 	}
 
 	if len(strings.TrimSpace(city)) == 0 {
-		return Address{}, errors.New("provided city can't be empty")
+		return Address{}, e.Invalid("city", "can't be empty")
 	}
 
 	if len(strings.TrimSpace(number)) == 0 {
-		return Address{}, errors.New("provided number can't be empty")
+		return Address{}, e.Invalid("number", "can't be empty")
 	}
 
 	return Address{
@@ -120,13 +121,14 @@ type Project struct {
 	Duration        string
 	Recommendations []string
 	CompanyID       string
+	ContactID       string // id of the representative managing the project, if known
 }
 
 // ProjectRepository interface
 type ProjectRepository interface {
 	FindByID(id string) (Project, error)
 	Delete(id string) error
-	FindAll() ([]Project, error)
+	FindAll(page Page) ([]Project, string, error)
 }
 
 // NewProject creates a new Project based on the
@@ -134,15 +136,15 @@ type ProjectRepository interface {
 // an error otherwise
 func NewProject(projectID, desc, comp, dur, companyID string, recs []string) (Project, error) {
 	if len(strings.TrimSpace(desc)) == 0 {
-		return Project{}, errors.New("provided description can't be empty")
+		return Project{}, e.Invalid("description", "can't be empty")
 	}
 
 	if len(strings.TrimSpace(comp)) == 0 {
-		return Project{}, errors.New("provided compensation can't be empty")
+		return Project{}, e.Invalid("compensation", "can't be empty")
 	}
 
 	if len(strings.TrimSpace(dur)) == 0 {
-		return Project{}, errors.New("provided duration can't be empty")
+		return Project{}, e.Invalid("duration", "can't be empty")
 	}
 
 	return Project{
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Conversation struct conveying a conversation
+// between two users, optionally about a certain project
+type Conversation struct {
+	ID           string
+	Participants []string // the ids of the users taking part in the conversation
+	ProjectID    string   // the project, the possible subject of the conversation (optional)
+	StartedBy    string   // id of the user that sent the first message, empty if their account has been deleted
+	CreatedAt    time.Time
+	LastActivity time.Time // moment the latest message was sent
+
+	// ReadAt keeps track of the moment each participant
+	// last read the conversation, keyed by user id
+	ReadAt map[string]time.Time
+
+	// Unread is the number of messages not yet read by the user
+	// the conversation was fetched for. It is only filled when
+	// conversations are fetched per participant.
+	Unread int
+}
+
+// ConversationRepository interface
+type ConversationRepository interface {
+	Create(conversation Conversation) error
+	FindByID(id string) (Conversation, error)
+
+	// FindBetween finds the conversation between the two provided users,
+	// about the provided project. projectID can be left empty to find
+	// the conversation that is not about a specific project.
+	FindBetween(userID, otherUserID, projectID string) (Conversation, error)
+
+	// FindByParticipant finds all conversations the user is part of,
+	// ordered from most to least recent activity, with the unread
+	// count filled in for this user
+	FindByParticipant(userID string) ([]Conversation, error)
+
+	MarkRead(conversationID, userID string, at time.Time) error
+
+	// CountStartedBy counts the conversations that have been started
+	// by any of the provided users since the provided moment
+	CountStartedBy(userIDs []string, since time.Time) (int, error)
+}
+
+// NewConversation creates a new conversation between the user starting
+// it and the other user, based on the provided input if all is valid,
+// returning an error otherwise. The projectID may be left empty.
+func NewConversation(id, startedBy, otherUserID, projectID string) (Conversation, error) {
+	if len(strings.TrimSpace(startedBy)) == 0 {
+		return Conversation{}, e.Invalid("starter", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(otherUserID)) == 0 {
+		return Conversation{}, e.Invalid("otherUser", "can't be empty")
+	}
+
+	if startedBy == otherUserID {
+		return Conversation{}, e.Invalid("otherUser", "can't be the starter")
+	}
+
+	now := time.Now()
+	return Conversation{
+		ID:           id,
+		Participants: []string{startedBy, otherUserID},
+		ProjectID:    projectID,
+		StartedBy:    startedBy,
+		CreatedAt:    now,
+		LastActivity: now,
+		ReadAt:       map[string]time.Time{},
+	}, nil
+}
+
+// HasParticipant checks if the user takes part in the conversation
+func (c Conversation) HasParticipant(userID string) bool {
+	for _, p := range c.Participants {
+		if p == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Partner returns the id of the user on the other side
+// of the conversation, seen from the provided user
+func (c Conversation) Partner(userID string) string {
+	for _, p := range c.Participants {
+		if p != userID {
+			return p
+		}
+	}
+
+	return ""
+}
@@ -1,44 +1,126 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
 
-// InviteLink struct conveying an invitelink
-// that gets sent to bind a new user to a company
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// InviteKind type for conveying what an
+// invitelink invites someone to become
+type InviteKind string
+
+const (
+	// RepresentativeInvite invites someone to become a representative of a company
+	RepresentativeInvite InviteKind = "representative"
+)
+
+// InviteStatus type for conveying what
+// has happened to an invitelink so far
+type InviteStatus string
+
+const (
+	// InvitePending means the invitelink can still be used
+	InvitePending InviteStatus = "pending"
+
+	// InviteAccepted means the invitelink has been used as often as it may
+	InviteAccepted InviteStatus = "accepted"
+
+	// InviteExpired means the invitelink hasn't been used in time
+	InviteExpired InviteStatus = "expired"
+
+	// InviteRevoked means the creator has withdrawn the invitelink
+	InviteRevoked InviteStatus = "revoked"
+)
+
+// InviteLinkValidFor is how long an invitelink can be used after
+// it has been created or renewed
+const InviteLinkValidFor = 24 * time.Hour
+
+// InviteLink struct conveying an invitation that gets sent to onboard new
+// users. What they are invited to is described by the kind and target,
+// e.g. a representative invite targets the company to become part of.
 type InviteLink struct {
 	ID         string
+	Kind       InviteKind
+	TargetID   string // id of what the invitee is invited to, e.g. a company
+	Role       string // the role the invitee gets when signing up
 	URL        string
+	Email      string // the email of the invitee, empty if anyone with the link may use it
 	CreatedAt  time.Time
 	ExpiryDate time.Time
-	CreatedBy  string // id of representative that created the invitation
+	CreatedBy  string    // id of the user (student or representative) that created the invitation
+	RevokedAt  time.Time // zero as long as the invitation hasn't been revoked
 
-	// keeps track if the link has been used or not
-	// This can be used to make sure a link can only gets used once
-	Used bool
+	// keeps track of how often the link has been used, so it can't be
+	// used more often than allowed
+	MaxUses int
+	Uses    int
 }
 
 // InviteLinkRepository interface
 type InviteLinkRepository interface {
 	Create(inviteLink InviteLink) error
+
+	// CreateWithMail inserts the invitelink and queues the
+	// mail sending it, which only happens if both succeed
+	CreateWithMail(inviteLink InviteLink, mail OutboxMail) error
 	FindByID(id string) (InviteLink, error)
-	Update(inviteLink InviteLink) error
-	FindByCreator(representativeID string) ([]InviteLink, error)
+	FindByCreator(creatorID string) ([]InviteLink, error)
+
+	// Redeem records a use of the invitelink, unless it
+	// has already been used up, revoked or has expired
+	Redeem(id string, at time.Time) error
+
+	// Revoke marks the invitelink as revoked, unless it has already been used up
+	Revoke(id string, at time.Time) error
+
+	// RenewWithMail stores the new expiry date of the invitelink and
+	// queues the mail sending it again, which only happens if both succeed
+	RenewWithMail(inviteLink InviteLink, mail OutboxMail) error
 }
 
-// NewInviteLink creates a new InviteLink to be sent
-// to a non-user to create a account and be bound to
-// the provided entity. InviteLinks are valid for the
-// amount of time specified by the validFor parameter
-// e.g. 24 hours -> time.Hour * 24
-func NewInviteLink(id, url, createdBy string, used bool, validFor time.Duration) InviteLink {
-	// todo: add check to see if link is valid?
+// NewInviteLink creates a new invitelink of the provided kind, inviting
+// someone to the target with the provided role, if all input is valid.
+// An invitelink with an email can only be used once, by the invitee with
+// that email. Without an email anyone with the link can use it, at most
+// maxUses times. InviteLinks are valid for the amount of time specified
+// by the validFor parameter e.g. 24 hours -> time.Hour * 24
+func NewInviteLink(id string, kind InviteKind, targetID, role, url, email, createdBy string, maxUses int, validFor time.Duration) (InviteLink, error) {
+	if len(strings.TrimSpace(string(kind))) == 0 {
+		return InviteLink{}, e.Invalid("kind", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(targetID)) == 0 {
+		return InviteLink{}, e.Invalid("target", "can't be empty")
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email != "" {
+		if !IsValidEmail(email) {
+			return InviteLink{}, e.Invalid("email", "is invalid")
+		}
+
+		maxUses = 1
+	}
+
+	if maxUses < 1 {
+		return InviteLink{}, e.Invalid("maxUses", "has to be at least 1")
+	}
+
 	return InviteLink{
 		ID:         id,
+		Kind:       kind,
+		TargetID:   targetID,
+		Role:       role,
 		URL:        url,
+		Email:      email,
 		CreatedAt:  time.Now(),
 		ExpiryDate: time.Now().Add(validFor),
-		Used:       used,
 		CreatedBy:  createdBy,
-	}
+		MaxUses:    maxUses,
+	}, nil
 }
 
 // HasExpired checks if the expiry date of
@@ -51,8 +133,44 @@ func (i InviteLink) HasExpired() bool {
 	return false
 }
 
-// HasBeenUsed checks if the invitelink
-// has been used or not
+// HasBeenUsed checks if the invitelink has
+// been used as often as it may be used
 func (i InviteLink) HasBeenUsed() bool {
-	return i.Used
+	return i.Uses >= i.MaxUses
+}
+
+// IsRevoked checks if the invitelink has been revoked
+func (i InviteLink) IsRevoked() bool {
+	return !i.RevokedAt.IsZero()
+}
+
+// Status returns what has happened to the invitelink so far
+func (i InviteLink) Status() InviteStatus {
+	switch {
+	case i.HasBeenUsed():
+		return InviteAccepted
+	case i.IsRevoked():
+		return InviteRevoked
+	case i.HasExpired():
+		return InviteExpired
+	default:
+		return InvitePending
+	}
+}
+
+// IsFor checks if the user with the provided email may use the
+// invitelink, which is anyone if it hasn't been sent to an email
+func (i InviteLink) IsFor(email string) bool {
+	return i.Email == "" || strings.EqualFold(i.Email, strings.TrimSpace(email))
+}
+
+// Renew makes an invitelink that hasn't been used up valid again for the
+// provided amount of time, returning an error if it has been used or revoked
+func (i *InviteLink) Renew(validFor time.Duration) error {
+	if i.HasBeenUsed() || i.IsRevoked() {
+		return e.New(e.Conflict, "only pending or expired invitelinks can be renewed")
+	}
+
+	i.ExpiryDate = time.Now().Add(validFor)
+	return nil
 }
@@ -0,0 +1,14 @@
+package domain
+
+// Mailer interface for sending mails to users
+type Mailer interface {
+	Send(mail Mail) error
+}
+
+// Mail struct conveying a mail sent to a user
+type Mail struct {
+	To      string
+	Subject string
+	Body    string // plain text version of the mail
+	HTML    string // html version of the mail, optional
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// weights of the parts a match score consists of, adding up to 1
+const (
+	recommendationsWeight = 0.6  // recommended skills of the project the student has
+	descriptionWeight     = 0.2  // skills of the student mentioned in the project description
+	wishesWeight          = 0.15 // wishes of the student mentioned anywhere in the project
+	universityWeight      = 0.05 // university of the student mentioned anywhere in the project
+)
+
+// synonyms maps alternative spellings of skills to the
+// spelling that is used when comparing skills
+var synonyms = map[string]string{
+	"golang":     "go",
+	"js":         "javascript",
+	"ecmascript": "javascript",
+	"ts":         "typescript",
+	"py":         "python",
+	"python3":    "python",
+	"postgres":   "postgresql",
+	"psql":       "postgresql",
+	"k8s":        "kubernetes",
+	"reactjs":    "react",
+	"react.js":   "react",
+	"vuejs":      "vue",
+	"vue.js":     "vue",
+	"node":       "nodejs",
+	"node.js":    "nodejs",
+	"c#":         "csharp",
+	"c++":        "cpp",
+	"ml":         "machine learning",
+	"ai":         "artificial intelligence",
+}
+
+// stopwords are ignored when comparing the wishes of a student
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "to": true, "of": true,
+	"in": true, "on": true, "at": true, "for": true, "with": true, "or": true,
+	"i": true, "me": true, "my": true, "want": true, "would": true, "like": true,
+	"be": true, "is": true, "am": true, "are": true, "that": true, "this": true,
+	"it": true, "as": true, "work": true, "working": true,
+}
+
+// Match struct conveying how well a student fits a project.
+// The score lies between 0 (no fit) and 1 (perfect fit).
+type Match struct {
+	StudentID     string
+	ProjectID     string
+	Score         float64
+	MatchedSkills []string // recommended skills of the project the student has
+}
+
+// NormalizeSkill returns the spelling of the skill
+// that is used when comparing skills
+func NormalizeSkill(skill string) string {
+	skill = strings.Join(strings.Fields(strings.ToLower(skill)), " ")
+	if synonym, ok := synonyms[skill]; ok {
+		return synonym
+	}
+
+	return skill
+}
+
+// Score scores how well the student fits the project
+func Score(student Student, project Project) Match {
+	recommendations := normalizeSkills(project.Recommendations)
+	skills := normalizeSkills(student.Skills)
+
+	projectText := " " + strings.Join(tokenize(project.Description+" "+strings.Join(project.Recommendations, " ")), " ") + " "
+	descriptionText := " " + strings.Join(tokenize(project.Description), " ") + " "
+
+	matched := []string{}
+	for _, recommendation := range recommendations {
+		if contains(skills, recommendation) {
+			matched = append(matched, recommendation)
+		}
+	}
+
+	mentioned := 0
+	for _, skill := range skills {
+		if strings.Contains(descriptionText, " "+skill+" ") {
+			mentioned++
+		}
+	}
+
+	wishes := []string{}
+	for _, token := range tokenize(student.Wishes) {
+		if !stopwords[token] && !contains(wishes, token) {
+			wishes = append(wishes, token)
+		}
+	}
+
+	wished := 0
+	for _, wish := range wishes {
+		if strings.Contains(projectText, " "+wish+" ") {
+			wished++
+		}
+	}
+
+	score := recommendationsWeight*fraction(len(matched), len(recommendations)) +
+		descriptionWeight*fraction(mentioned, len(skills)) +
+		wishesWeight*fraction(wished, len(wishes))
+
+	university := strings.Join(tokenize(student.University), " ")
+	if university != "" && strings.Contains(projectText, " "+university+" ") {
+		score += universityWeight
+	}
+
+	return Match{
+		StudentID:     student.ID,
+		ProjectID:     project.ID,
+		Score:         score,
+		MatchedSkills: matched,
+	}
+}
+
+// helper func that normalizes the skills, leaving out empty skills and duplicates
+func normalizeSkills(skills []string) []string {
+	normalized := []string{}
+	for _, skill := range skills {
+		skill = NormalizeSkill(skill)
+		if skill != "" && !contains(normalized, skill) {
+			normalized = append(normalized, skill)
+		}
+	}
+
+	return normalized
+}
+
+// helper func that splits the text into normalized words. Characters
+// used in names of skills, like the + in c++, are kept.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.", r)
+	})
+
+	tokens := []string{}
+	for _, word := range words {
+		// dots are only part of a skill when they're inside a word, like in node.js
+		word = strings.Trim(word, ".")
+		if word != "" {
+			tokens = append(tokens, NormalizeSkill(word))
+		}
+	}
+
+	return tokens
+}
+
+// helper func that checks if the value is present in the values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// helper func that divides part by total, which is 0 if total is 0
+func fraction(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total)
+}
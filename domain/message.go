@@ -1,47 +1,76 @@
 package domain
 
 import (
-	"errors"
 	"strings"
 	"time"
+
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // Message struct conveying messages
 // users can send to each other,
 // optionally linked to a certain project
 type Message struct {
-	Sender    string // the sender of the message (id of user)
-	Receiver  string // the receiver of the message (id of user)
-	Body      string // the message body
-	ProjectID string // the project, the possible subject of the message (optional)
-	CreatedAt time.Time
+	ID             string
+	ConversationID string // the conversation this message is part of
+	Sender         string // the sender of the message (id of user), empty if their account has been deleted
+	Receiver       string // the receiver of the message (id of user), empty if their account has been deleted
+	Body           string // the message body
+	ProjectID      string // the project, the possible subject of the message (optional)
+	CreatedAt      time.Time
+}
+
+// DeletedMessageBody replaces the body of messages
+// sent by users that have deleted their account
+const DeletedMessageBody = "[this message has been deleted]"
+
+// MessageRepository interface
+type MessageRepository interface {
+	// Create inserts the message and updates the
+	// last activity of the conversation it is part of
+	Create(message Message) error
+
+	// CreateWithMail inserts the message like Create and queues the
+	// mail notifying its receiver, which only happens if both succeed
+	CreateWithMail(message Message, mail OutboxMail) error
+	FindByID(id string) (Message, error)
+
+	// FindByConversation finds all messages of the conversation,
+	// ordered from oldest to newest
+	FindByConversation(conversationID string) ([]Message, error)
 }
 
 // NewMessage creates a new message based on
 // the provided input if all is valid, returning
-// an error otherwise
-func NewMessage(sender, receiver, body string) (Message, error) {
+// an error otherwise. The projectID may be left
+// empty if the message is not about a specific project.
+func NewMessage(id, sender, receiver, body, projectID string) (Message, error) {
 	// what checks does this need?
 	// i'm thinking about a check to see if the provided
 	// sender and receiver emails exist in the system
 	// but that would result in a dependency on a repo, hmmm
 	if len(strings.TrimSpace(sender)) == 0 {
-		return Message{}, errors.New("provided sender can't be empty")
+		return Message{}, e.Invalid("sender", "can't be empty")
 	}
 
 	if len(strings.TrimSpace(receiver)) == 0 {
-		return Message{}, errors.New("provided receiver can't be empty")
+		return Message{}, e.Invalid("receiver", "can't be empty")
+	}
+
+	if sender == receiver {
+		return Message{}, e.Invalid("receiver", "can't be the sender")
 	}
 
 	if len(strings.TrimSpace(body)) == 0 {
-		return Message{}, errors.New("provided body can't be empty")
+		return Message{}, e.Invalid("body", "can't be empty")
 	}
 
 	return Message{
+		ID:        id,
 		Sender:    sender,
 		Receiver:  receiver,
 		Body:      body,
-		ProjectID: "",
+		ProjectID: projectID,
 		CreatedAt: time.Now(),
 	}, nil
 }
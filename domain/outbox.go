@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// MailEvent type for conveying which event a
+// mail is about, which decides its template
+type MailEvent string
+
+const (
+	// InviteMail invites someone to join something, e.g. a company as representative
+	InviteMail MailEvent = "invite"
+
+	// NewMessageMail notifies a user of a message they received
+	NewMessageMail MailEvent = "new message"
+
+	// ApplicationStatusMail notifies a student their application moved to another status
+	ApplicationStatusMail MailEvent = "application status"
+)
+
+// MaxMailAttempts is how often sending a mail is tried before giving up on it
+const MaxMailAttempts = 8
+
+// first and longest wait before sending a failed mail again
+const (
+	minMailBackoff = 30 * time.Second
+	maxMailBackoff = 6 * time.Hour
+)
+
+// OutboxRepository interface
+type OutboxRepository interface {
+	Enqueue(mail OutboxMail) error
+
+	// ClaimDue finds at most limit mails that should be sent at the provided
+	// time, postponing them by the lease so no one else claims them meanwhile
+	ClaimDue(at time.Time, lease time.Duration, limit int) ([]OutboxMail, error)
+	MarkSent(id string, at time.Time) error
+	MarkFailed(mail OutboxMail) error
+}
+
+// OutboxMail struct conveying a mail that has to be sent. It is stored in
+// the same transaction as the change it is about, and sent afterwards, so
+// no mail gets lost or is sent for a change that didn't happen.
+type OutboxMail struct {
+	ID            string
+	Event         MailEvent
+	To            string
+	Data          map[string]string // the values filled in in the template of the event
+	Attempts      int
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	SentAt        time.Time // zero as long as the mail hasn't been sent
+}
+
+// NewOutboxMail creates a new mail about the event that
+// can be sent right away, if all input is valid.
+// It returns an error otherwise.
+func NewOutboxMail(id string, event MailEvent, to string, data map[string]string) (OutboxMail, error) {
+	if len(strings.TrimSpace(string(event))) == 0 {
+		return OutboxMail{}, e.Invalid("event", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(to)) == 0 {
+		return OutboxMail{}, e.Invalid("receiver", "can't be empty")
+	}
+
+	if data == nil {
+		data = map[string]string{}
+	}
+
+	now := time.Now()
+	return OutboxMail{
+		ID:            id,
+		Event:         event,
+		To:            to,
+		Data:          data,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// Fail records a failed attempt to send the mail, scheduling the next
+// attempt further away the more often sending has failed
+func (o *OutboxMail) Fail(err error, at time.Time) {
+	o.Attempts++
+	o.LastError = err.Error()
+
+	backoff := minMailBackoff
+	for i := 1; i < o.Attempts && backoff < maxMailBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxMailBackoff {
+		backoff = maxMailBackoff
+	}
+
+	o.NextAttemptAt = at.Add(backoff)
+}
+
+// IsDead checks if sending the mail has failed too often to try again
+func (o OutboxMail) IsDead() bool {
+	return o.Attempts >= MaxMailAttempts
+}
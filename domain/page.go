@@ -0,0 +1,34 @@
+package domain
+
+const (
+	// DefaultPageLimit is the amount of items a page contains
+	// when no limit is provided
+	DefaultPageLimit = 20
+
+	// MaxPageLimit is the maximum amount of items a page can contain
+	MaxPageLimit = 100
+)
+
+// Page struct conveying which part of a list should be fetched.
+// Lists are ordered on id, so a page contains at most Limit items
+// whose id comes after the After id. Repositories return the id to
+// pass as After for the next page, which is empty on the last page.
+type Page struct {
+	Limit int
+	After string // id of the last item of the previous page, empty for the first page
+}
+
+// NewPage creates a new page based on the provided input. A limit
+// that is not positive results in the default limit, a limit that
+// is too big is capped to the max limit.
+func NewPage(limit int, after string) Page {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	return Page{Limit: limit, After: after}
+}
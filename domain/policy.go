@@ -0,0 +1,190 @@
+package domain
+
+// Action type for conveying what a
+// subject wants to do with a resource
+type Action string
+
+const (
+	// ReadStudentProfile is reading the profile of a student
+	ReadStudentProfile Action = "read student profile"
+
+	// ReadStudentResume is reading the resume of a student
+	ReadStudentResume Action = "read student resume"
+
+	// EditStudentProfile is editing the profile of a student
+	EditStudentProfile Action = "edit student profile"
+
+	// ExportStudentData is downloading all personal data of a student
+	ExportStudentData Action = "export student data"
+
+	// DeleteStudentAccount is deleting the account of a student
+	DeleteStudentAccount Action = "delete student account"
+
+	// ReadRecommendedProjects is reading the projects recommended to a student
+	ReadRecommendedProjects Action = "read recommended projects"
+
+	// EditRepresentativeProfile is editing the profile of a representative
+	EditRepresentativeProfile Action = "edit representative profile"
+
+	// EditCompany is editing the data of a company
+	EditCompany Action = "edit company"
+
+	// DeleteProject is deleting a project of a company
+	DeleteProject Action = "delete project"
+
+	// ReadApplications is reading the applications to a project
+	ReadApplications Action = "read applications"
+
+	// ManageApplications is moving the applications to a project
+	ManageApplications Action = "manage applications"
+
+	// ReadProjectMatches is reading the students matching a project
+	ReadProjectMatches Action = "read project matches"
+
+	// ManageShortlist is changing the shortlist of a company
+	ManageShortlist Action = "manage shortlist"
+
+	// InviteRepresentative is inviting someone to become a representative of a company
+	InviteRepresentative Action = "invite representative"
+
+	// RemoveRepresentative is removing a representative from a company
+	RemoveRepresentative Action = "remove representative"
+
+	// ChangeCompanyRole is changing the company role of a representative
+	ChangeCompanyRole Action = "change company role"
+
+	// TransferOwnership is making another representative the owner of a company
+	TransferOwnership Action = "transfer ownership"
+)
+
+// Rule type for conveying a single condition
+// under which a subject may act on a resource
+type Rule func(subject Subject, resource Resource) bool
+
+// policies contains the rules of each action, a subject is
+// allowed to perform the action if any of the rules holds
+var policies = map[Action][]Rule{
+	ReadStudentProfile:        {OwnerOfProfile, VisibleToRepresentative},
+	ReadStudentResume:         {OwnerOfProfile, SharedWithCompany},
+	EditStudentProfile:        {OwnerOfProfile},
+	ExportStudentData:         {OwnerOfProfile},
+	DeleteStudentAccount:      {OwnerOfProfile},
+	ReadRecommendedProjects:   {OwnerOfProfile},
+	EditRepresentativeProfile: {OwnerOfProfile},
+	EditCompany:               {AdminOfOwningCompany},
+	DeleteProject:             {AdminOfOwningCompany},
+	ReadApplications:          {RepresentativeOfOwningCompany},
+	ManageApplications:        {RecruiterOfOwningCompany},
+	ReadProjectMatches:        {RepresentativeOfOwningCompany},
+	ManageShortlist:           {RecruiterOfOwningCompany},
+	InviteRepresentative:      {AdminOfOwningCompany},
+	RemoveRepresentative:      {AdminOfOwningCompany},
+	ChangeCompanyRole:         {AdminOfOwningCompany},
+	TransferOwnership:         {OwnerOfOwningCompany},
+}
+
+// Subject struct conveying the user performing an action, as known
+// from their token. The company and the role within it are only
+// known for representatives.
+type Subject struct {
+	UserID      string
+	ID          string // id of the student or representative
+	Role        string
+	CompanyID   string
+	CompanyRole CompanyRole
+}
+
+// Resource struct conveying what an action is performed on.
+// Fields that don't apply to the resource are left empty.
+type Resource struct {
+	OwnerID    string     // id of the student or representative owning the profile
+	CompanyID  string     // id of the company owning the resource
+	Visibility Visibility // which representatives may view the profile of a student
+	SharedWith []string   // ids of the companies the resource is shared with
+}
+
+// OwnerOfProfile holds if the profile belongs to the subject
+func OwnerOfProfile(subject Subject, resource Resource) bool {
+	return subject.ID != "" && subject.ID == resource.OwnerID
+}
+
+// RepresentativeOfOwningCompany holds if the subject is a
+// representative of the company owning the resource
+func RepresentativeOfOwningCompany(subject Subject, resource Resource) bool {
+	return subject.Role == RepresentativeRole &&
+		subject.CompanyID != "" &&
+		subject.CompanyID == resource.CompanyID
+}
+
+// RecruiterOfOwningCompany holds if the subject is a representative
+// of the company owning the resource, who may do more than viewing
+func RecruiterOfOwningCompany(subject Subject, resource Resource) bool {
+	return RepresentativeOfOwningCompany(subject, resource) &&
+		subject.CompanyRole != "" &&
+		subject.CompanyRole != CompanyViewer
+}
+
+// AdminOfOwningCompany holds if the subject is a representative
+// of the company owning the resource, who may manage the company
+func AdminOfOwningCompany(subject Subject, resource Resource) bool {
+	return RepresentativeOfOwningCompany(subject, resource) &&
+		(subject.CompanyRole == CompanyOwner || subject.CompanyRole == CompanyAdmin)
+}
+
+// OwnerOfOwningCompany holds if the subject is the
+// owner of the company owning the resource
+func OwnerOfOwningCompany(subject Subject, resource Resource) bool {
+	return RepresentativeOfOwningCompany(subject, resource) &&
+		subject.CompanyRole == CompanyOwner
+}
+
+// VisibleToRepresentative holds if the subject is a representative
+// allowed to view the profile, based on its visibility
+func VisibleToRepresentative(subject Subject, resource Resource) bool {
+	if subject.Role != RepresentativeRole {
+		return false
+	}
+
+	switch resource.Visibility {
+	case VisibleToRepresentatives, "":
+		return true
+	case VisibleToAppliedCompanies:
+		return SharedWithCompany(subject, resource)
+	default:
+		return false
+	}
+}
+
+// SharedWithCompany holds if the subject is a representative
+// of a company the resource is shared with
+func SharedWithCompany(subject Subject, resource Resource) bool {
+	if subject.Role != RepresentativeRole || subject.CompanyID == "" {
+		return false
+	}
+
+	for _, companyID := range resource.SharedWith {
+		if companyID == subject.CompanyID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AnyRepresentative holds if the subject is a representative
+func AnyRepresentative(subject Subject, resource Resource) bool {
+	return subject.Role == RepresentativeRole
+}
+
+// IsAllowed checks if the subject may perform
+// the action on the resource. Actions without
+// rules are not allowed for anyone.
+func IsAllowed(subject Subject, action Action, resource Resource) bool {
+	for _, rule := range policies[action] {
+		if rule(subject, resource) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,17 +1,58 @@
 package domain
 
 import (
-	"errors"
-	"regexp"
 	"strings"
 	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// CompanyRole type for conveying what a
+// representative may do within their company
+type CompanyRole string
+
+const (
+	// CompanyOwner is the main representative, who signed up the company.
+	// Owners can do everything admins can, and can't be removed.
+	CompanyOwner CompanyRole = "owner"
+
+	// CompanyAdmin can manage the company, its projects and its representatives
+	CompanyAdmin CompanyRole = "admin"
+
+	// CompanyRecruiter can manage the applications and shortlist of the company
+	CompanyRecruiter CompanyRole = "recruiter"
+
+	// CompanyViewer can only read the data of the company
+	CompanyViewer CompanyRole = "viewer"
 )
 
+// companyRoles contains all valid company roles
+var companyRoles = map[CompanyRole]bool{
+	CompanyOwner:     true,
+	CompanyAdmin:     true,
+	CompanyRecruiter: true,
+	CompanyViewer:    true,
+}
+
 // RepresentativeRepository interface
 type RepresentativeRepository interface {
 	Create(representative Representative) error
 	FindByID(id string) (Representative, error)
+
+	// Update updates the profile of the representative,
+	// their company role can only be changed by UpdateCompanyRole
 	Update(representative Representative) error
+	UpdateCompanyRole(representativeID string, role CompanyRole) error
+
+	// Remove removes the representative from their company at the provided
+	// moment. Their projects and conversations are handed over to the
+	// successor, their pending invitations are revoked and they are signed
+	// out everywhere, all at once.
+	Remove(representativeID, successorID string, at time.Time) error
+
+	// TransferOwnership makes the new owner the owner of the
+	// company, while the current owner becomes an admin
+	TransferOwnership(ownerID, newOwnerID string) error
 }
 
 // Representative struct conveying a
@@ -19,50 +60,59 @@ type RepresentativeRepository interface {
 // that is looking in name of the company
 // for students
 type Representative struct {
-	ID        string
-	JobTitle  string
-	User      User
-	CompanyID string
+	ID          string
+	JobTitle    string
+	User        User
+	CompanyID   string
+	CompanyRole CompanyRole
+	RemovedAt   time.Time // zero as long as the representative works for the company
 }
 
-// NewRepresentative creates a new representative based on the provided input
+// NewRepresentative creates a new representative based on the provided input.
+// New representatives are recruiters, other roles are given by an admin.
 func NewRepresentative(id, jobTitle, companyID string, user User) (Representative, error) {
 	if len(strings.TrimSpace(jobTitle)) == 0 {
-		return Representative{}, errors.New("provided jobTitle can't be empty")
+		return Representative{}, e.Invalid("jobTitle", "can't be empty")
 	}
 
 	return Representative{
-		ID:        id,
-		JobTitle:  strings.ToLower(jobTitle),
-		User:      user,
-		CompanyID: companyID,
+		ID:          id,
+		JobTitle:    strings.ToLower(jobTitle),
+		User:        user,
+		CompanyID:   companyID,
+		CompanyRole: CompanyRecruiter,
 	}, nil
 }
 
-// GenerateInviteLink generates an invite-link to to sent to a non-user
-// in order to become a representative of this representatives company
-// urlTemplate should contain <[companyID]> and <[inviteID]>.
-// e.g.: /signup/representatives/invite/<[companyID]>/<[inviteID]>
-func (r Representative) GenerateInviteLink(inviteLinkID, urlTemplate string) (InviteLink, error) {
-	if len(strings.TrimSpace(urlTemplate)) == 0 {
-		return InviteLink{}, errors.New("provided url can't be empty")
-	}
+// IsValidCompanyRole checks if the company role exists
+func IsValidCompanyRole(role CompanyRole) bool {
+	return companyRoles[role]
+}
+
+// IsAdmin checks if the representative can manage their company
+func (r Representative) IsAdmin() bool {
+	return r.CompanyRole == CompanyOwner || r.CompanyRole == CompanyAdmin
+}
 
-	// todo: this also needs to be updated as the inviteLink-id also needs to be there
-	url := regexp.MustCompile(`<\[companyID\]>`).ReplaceAllString(urlTemplate, r.CompanyID)
-	url = regexp.MustCompile(`<\[inviteID\]>`).ReplaceAllString(url, inviteLinkID)
-
-	return NewInviteLink(
-		inviteLinkID,
-		url,
-		r.ID,
-		false,
-		time.Hour*24,
-	), nil
+// IsRemoved checks if the representative has been removed from their company
+func (r Representative) IsRemoved() bool {
+	return !r.RemovedAt.IsZero()
 }
 
 // CreateProject creates a new project for the company of
-// the representative
+// the representative, who becomes the contact of the project
 func (r Representative) CreateProject(projectID, desc, comp, dur string, recs []string) (Project, error) {
-	return NewProject(projectID, desc, comp, dur, r.CompanyID, recs)
+	project, err := NewProject(projectID, desc, comp, dur, r.CompanyID, recs)
+	if err != nil {
+		return Project{}, err
+	}
+
+	project.ContactID = r.ID
+	return project, nil
+}
+
+// Shortlist puts a student on the shortlist of the company
+// of the representative, optionally for a specific project
+func (r Representative) Shortlist(entryID, studentID, projectID, notes string, tags []string) (ShortlistEntry, error) {
+	return NewShortlistEntry(entryID, r.CompanyID, studentID, projectID, notes, r.ID, tags)
 }
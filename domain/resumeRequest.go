@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ResumeRequest struct conveying a company asking for the resume
+// of a student that only shares it on request. The resume is
+// shared with the company once the student grants the request.
+type ResumeRequest struct {
+	StudentID   string
+	CompanyID   string
+	RequestedBy string // id of the representative that asked for the resume
+	RequestedAt time.Time
+	GrantedAt   time.Time // zero as long as the request hasn't been granted
+}
+
+// ResumeRequestRepository interface
+type ResumeRequestRepository interface {
+	// Create stores the request, unless the company
+	// already asked for the resume of the student
+	Create(request ResumeRequest) error
+	FindByStudent(studentID string) ([]ResumeRequest, error)
+	Grant(studentID, companyID string, at time.Time) error
+	Delete(studentID, companyID string) error
+}
+
+// NewResumeRequest creates a new resume request based on the
+// provided input if all is valid, returning an error otherwise
+func NewResumeRequest(studentID, companyID, requestedBy string) (ResumeRequest, error) {
+	if len(strings.TrimSpace(studentID)) == 0 {
+		return ResumeRequest{}, e.Invalid("student", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(companyID)) == 0 {
+		return ResumeRequest{}, e.Invalid("company", "can't be empty")
+	}
+
+	return ResumeRequest{
+		StudentID:   studentID,
+		CompanyID:   companyID,
+		RequestedBy: requestedBy,
+		RequestedAt: time.Now(),
+	}, nil
+}
+
+// IsGranted checks if the student shares their resume with the company
+func (r ResumeRequest) IsGranted() bool {
+	return !r.GrantedAt.IsZero()
+}
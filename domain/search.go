@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// HitKind type for conveying the kind
+// of entity a search hit refers to
+type HitKind string
+
+const (
+	// ProjectHit indicates the hit is a project
+	ProjectHit HitKind = "project"
+
+	// CompanyHit indicates the hit is a company
+	CompanyHit HitKind = "company"
+)
+
+// SearchRepository interface
+type SearchRepository interface {
+	Search(query SearchQuery) ([]SearchHit, error)
+}
+
+// SearchQuery struct conveying the keywords to search
+// projects and companies on, limited to Limit hits
+type SearchQuery struct {
+	Text  string
+	Limit int
+}
+
+// SearchHit struct conveying a project or company that matched a
+// search. Hits with a higher rank are more relevant. The snippet is
+// the part of the text that matched, with the keywords highlighted.
+type SearchHit struct {
+	Kind      HitKind
+	ID        string
+	CompanyID string // the company itself for company hits, the owner for project hits
+	Title     string
+	Snippet   string
+	Rank      float64
+}
+
+// NewSearchQuery creates a new search query based on the provided input
+// if all is valid, returning an error otherwise. The limit is handled
+// the same way as the limit of a page.
+func NewSearchQuery(text string, limit int) (SearchQuery, error) {
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return SearchQuery{}, e.Invalid("text", "can't be empty")
+	}
+
+	return SearchQuery{Text: text, Limit: NewPage(limit, "").Limit}, nil
+}
+
+// Terms returns the lowercased keywords of the query
+func (q SearchQuery) Terms() []string {
+	return strings.Fields(strings.ToLower(q.Text))
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// SessionRepository interface
+type SessionRepository interface {
+	Create(session Session) error
+	FindByTokenHash(tokenHash string) (Session, error)
+	Rotate(old, replacement Session) error
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeByUser(userID string, at time.Time) error
+	IsFamilyActive(familyID string) (bool, error)
+}
+
+// Session struct conveying a signed in device of a user. Each session
+// holds one refresh token. Refreshing rotates the session: the old one
+// is marked as used and a new one is created in the same family, so all
+// sessions stemming from the same signin share a family.
+type Session struct {
+	ID        string
+	FamilyID  string
+	UserID    string
+	TokenHash string // hash of the refresh token, the token itself isn't stored
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    time.Time // zero as long as the session hasn't been rotated
+	RevokedAt time.Time // zero as long as the session hasn't been revoked
+}
+
+// NewSession creates a new session holding the provided refresh
+// token, which expires after the provided duration, if all input
+// is valid. It returns an error otherwise.
+func NewSession(id, familyID, userID, token string, ttl time.Duration) (Session, error) {
+	if len(strings.TrimSpace(userID)) == 0 {
+		return Session{}, e.Invalid("user", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(token)) == 0 {
+		return Session{}, e.Invalid("token", "can't be empty")
+	}
+
+	now := time.Now()
+	return Session{
+		ID:        id,
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// Rotate creates the session that replaces this one,
+// holding the provided refresh token
+func (s Session) Rotate(id, token string, ttl time.Duration) (Session, error) {
+	return NewSession(id, s.FamilyID, s.UserID, token, ttl)
+}
+
+// IsUsed checks if the session has already been rotated
+func (s Session) IsUsed() bool {
+	return !s.UsedAt.IsZero()
+}
+
+// IsActive checks if the refresh token of the session can still be used
+func (s Session) IsActive() bool {
+	return !s.IsUsed() && s.RevokedAt.IsZero() && time.Now().Before(s.ExpiresAt)
+}
+
+// HashToken hashes a token, so it can be stored and looked up
+// without storing the token itself
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ShortlistEntry struct conveying a student a company
+// is interested in. The shortlist of a company is shared
+// among all representatives of this company.
+type ShortlistEntry struct {
+	ID        string
+	CompanyID string
+	StudentID string
+	ProjectID string // the project the student is interesting for (optional)
+	Notes     string
+	Tags      []string
+	AddedBy   string // id of the representative that added the student
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ShortlistRepository interface
+type ShortlistRepository interface {
+	Create(entry ShortlistEntry) error
+	FindByID(id string) (ShortlistEntry, error)
+	FindByCompany(companyID string) ([]ShortlistEntry, error)
+	Update(entry ShortlistEntry) error
+	Delete(id string) error
+}
+
+// NewShortlistEntry creates a new shortlist entry based on the
+// provided input if all is valid, returning an error otherwise
+func NewShortlistEntry(id, companyID, studentID, projectID, notes, addedBy string, tags []string) (ShortlistEntry, error) {
+	if len(strings.TrimSpace(companyID)) == 0 {
+		return ShortlistEntry{}, e.Invalid("company", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(studentID)) == 0 {
+		return ShortlistEntry{}, e.Invalid("student", "can't be empty")
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	now := time.Now()
+	return ShortlistEntry{
+		ID:        id,
+		CompanyID: companyID,
+		StudentID: studentID,
+		ProjectID: projectID,
+		Notes:     notes,
+		Tags:      normalizeTags(tags),
+		AddedBy:   addedBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Edit replaces the notes and tags of the entry
+func (s *ShortlistEntry) Edit(notes string, tags []string) {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	s.Notes = notes
+	s.Tags = normalizeTags(tags)
+	s.UpdatedAt = time.Now()
+}
+
+// helper func that lowercases and trims all tags,
+// dropping empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+
+	return normalized
+}
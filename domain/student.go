@@ -1,14 +1,40 @@
 package domain
 
 import (
-	"errors"
 	"strings"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Visibility type for conveying which
+// representatives may view the profile of a student
+type Visibility string
+
+const (
+	// VisibleToRepresentatives lets all representatives view the
+	// profile, which is also the case when no visibility is set
+	VisibleToRepresentatives Visibility = "representatives"
+
+	// VisibleToAppliedCompanies only lets representatives of the
+	// companies the student applied to view the profile
+	VisibleToAppliedCompanies Visibility = "applied"
+
+	// Hidden lets no one but the student view the profile
+	Hidden Visibility = "hidden"
 )
 
+// visibilities contains all valid visibilities
+var visibilities = map[Visibility]bool{
+	VisibleToRepresentatives:  true,
+	VisibleToAppliedCompanies: true,
+	Hidden:                    true,
+}
+
 // Student struct
 type Student struct {
 	ID               string
 	University       string
+	City             string // city of residence
 	Skills           []string
 	Experiences      []string
 	ShortExperiences []string
@@ -16,18 +42,58 @@ type Student struct {
 	Status           Status
 	User             User
 	Resume           string // path to the resume of the student
+	Visibility       Visibility
+
+	// ResumeOnRequest tells if the resume is only shared with
+	// companies the student granted their request for it
+	ResumeOnRequest bool
 }
 
 // StudentRepository interface
 type StudentRepository interface {
 	Create(student Student) error
+
+	// Update updates the profile of the student, their privacy
+	// settings can only be changed by UpdatePrivacy
 	Update(student Student) error
+	UpdatePrivacy(studentID string, visibility Visibility, resumeOnRequest bool) error
 	FindByID(id string) (Student, error)
-	FindAll() ([]Student, error) // todo: think of a way to return all students 1 by 1, not all in one go
+	FindAll(page Page) ([]Student, string, error)
+	FindByQuery(query StudentQuery, page Page) ([]Student, string, error)
+
+	// Delete deletes the student together with their user account, their
+	// applications, blocks, sessions and mails, and the shortlist entries
+	// about them. The messages they sent are kept for the other participant,
+	// but their body is replaced by DeletedMessageBody.
+	Delete(student Student) error
+}
+
+// StudentExport struct conveying all personal data
+// stored about a student, as handed to them on request
+type StudentExport struct {
+	Student       Student
+	Conversations []Conversation
+	Messages      []Message
+	Applications  []Application
+}
+
+// StudentQuery struct conveying the filters students can
+// be searched on. Fields that are left empty are not filtered on.
+type StudentQuery struct {
+	Skills     []string
+	AllSkills  bool // if true students need all Skills, otherwise any of them is enough
+	University string
+	City       string
+	Status     *Status
+	Text       string // free text searched for in the experiences and wishes of students
+
+	// VisibleTo is the id of the company the students are searched for,
+	// only students whose profile it may view are found
+	VisibleTo string
 }
 
 // NewStudent creates a new student based on the provided input args
-func NewStudent(id, uni string,
+func NewStudent(id, uni, city string,
 	skills []string,
 	experiences []string,
 	shortExperiences []string,
@@ -37,12 +103,13 @@ func NewStudent(id, uni string,
 	resume string) (Student, error) {
 
 	if len(strings.TrimSpace(uni)) == 0 {
-		return Student{}, errors.New("provided univeristy can't empty")
+		return Student{}, e.Invalid("university", "can't be empty")
 	}
 
 	return Student{
 		ID:               id,
 		University:       strings.ToLower(uni),
+		City:             strings.ToLower(city),
 		Skills:           skills,
 		Experiences:      experiences,
 		ShortExperiences: shortExperiences,
@@ -50,6 +117,70 @@ func NewStudent(id, uni string,
 		User:             user,
 		Status:           status,
 		Resume:           resume,
+		Visibility:       VisibleToRepresentatives,
 	}, nil
 
 }
+
+// IsValidVisibility checks if the visibility exists
+func IsValidVisibility(visibility Visibility) bool {
+	return visibilities[visibility]
+}
+
+// WithoutResume returns the student without their resume if they
+// only share it on request, as shown in lists to representatives
+func (s Student) WithoutResume() Student {
+	if s.ResumeOnRequest {
+		s.Resume = ""
+	}
+
+	return s
+}
+
+// Matches checks if the student matches all filters of the query, except
+// VisibleTo, as the companies the student applied to aren't known here.
+// Repositories check the visibility themselves.
+func (s Student) Matches(q StudentQuery) bool {
+	if q.University != "" && !containsFold(s.University, q.University) {
+		return false
+	}
+
+	if q.City != "" && !containsFold(s.City, q.City) {
+		return false
+	}
+
+	if q.Status != nil && s.Status != *q.Status {
+		return false
+	}
+
+	if len(q.Skills) > 0 {
+		matched := 0
+		for _, wanted := range q.Skills {
+			for _, skill := range s.Skills {
+				if strings.EqualFold(skill, wanted) {
+					matched++
+					break
+				}
+			}
+		}
+
+		if matched == 0 || (q.AllSkills && matched < len(q.Skills)) {
+			return false
+		}
+	}
+
+	if q.Text != "" {
+		text := strings.Join(s.Experiences, " ") + " " +
+			strings.Join(s.ShortExperiences, " ") + " " + s.Wishes
+		if !containsFold(text, q.Text) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// helper func that checks if substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
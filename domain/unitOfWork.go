@@ -0,0 +1,34 @@
+package domain
+
+// Repositories struct giving access to all repositories
+type Repositories struct {
+	Users           UserRepository
+	Students        StudentRepository
+	Representatives RepresentativeRepository
+	Companies       CompanyRepository
+	Projects        ProjectRepository
+	InviteLinks     InviteLinkRepository
+	Conversations   ConversationRepository
+	Messages        MessageRepository
+	Blocks          BlockRepository
+	Applications    ApplicationRepository
+	Shortlist       ShortlistRepository
+	ResumeRequests  ResumeRequestRepository
+	Search          SearchRepository
+	Sessions        SessionRepository
+	UserTokens      UserTokenRepository
+	Outbox          OutboxRepository
+}
+
+// UnitOfWork interface, for use cases that change
+// several repositories and have to do so atomically.
+// Only changes made through the repositories take part,
+// files stored on disk (e.g. resumes) have to be removed
+// by the use case itself when the unit of work fails.
+type UnitOfWork interface {
+	// Do calls fn with repositories whose changes are all kept when fn
+	// returns nil, and all discarded when it returns an error, which Do
+	// returns as well. fn should only use the provided repositories, as
+	// other ones don't take part and may have to wait until Do is done.
+	Do(fn func(repos Repositories) error) error
+}
@@ -1,10 +1,11 @@
 package domain
 
 import (
-	"errors"
+	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
+	e "github.com/janabe/cscoupler/errors"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -16,31 +17,43 @@ type User struct {
 	FirstName      string
 	LastName       string
 	Role           string
+	EmailVerified  bool
 }
 
+// emailFormat is the format a valid email has to match
+var emailFormat = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
 // UserRepository interface
 type UserRepository interface {
 	Create(user User) error
 	FindByID(id string) (User, error)
 	FindByEmail(email string) (User, error)
 	FindRoleID(user User) (string, error)
+	UpdatePassword(userID, hashedPassword string) error
+	MarkEmailVerified(userID string) error
 }
 
-// NewUser creates a new user or returns an error when the hashing of the password fails
+// NewUser creates a new user, whose email still has to be verified, or
+// returns an error when the input is invalid or hashing the password fails
 func NewUser(email, password, fname, lname, role string) (User, error) {
 	id := uuid.New().String()
 
+	email = strings.TrimSpace(email)
+	if !IsValidEmail(email) {
+		return User{}, e.Invalid("email", "is invalid")
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return User{}, errors.New("Error hashing password")
+		return User{}, e.Wrap(err, e.Unexpected, "hashing password failed")
 	}
 
 	if len(strings.TrimSpace(fname)) == 0 {
-		return User{}, errors.New("provided firstname can't be empty")
+		return User{}, e.Invalid("firstname", "can't be empty")
 	}
 
 	if len(strings.TrimSpace(lname)) == 0 {
-		return User{}, errors.New("provided lastname can't be empty")
+		return User{}, e.Invalid("lastname", "can't be empty")
 	}
 
 	return User{
@@ -52,3 +65,22 @@ func NewUser(email, password, fname, lname, role string) (User, error) {
 		Role:           role,
 	}, nil
 }
+
+// HashPassword hashes the password so it can be stored as the password of a user
+func HashPassword(password string) (string, error) {
+	if len(password) == 0 {
+		return "", e.Invalid("password", "can't be empty")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", e.Wrap(err, e.Unexpected, "hashing password failed")
+	}
+
+	return string(hash), nil
+}
+
+// IsValidEmail checks if the email has a valid format
+func IsValidEmail(email string) bool {
+	return emailFormat.MatchString(strings.TrimSpace(email))
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// TokenPurpose type for conveying what
+// a one-time user token can be used for
+type TokenPurpose string
+
+const (
+	// PasswordReset indicates the token can be used to set a new password
+	PasswordReset TokenPurpose = "password reset"
+
+	// EmailVerification indicates the token can be used to verify an email
+	EmailVerification TokenPurpose = "email verification"
+)
+
+// UserTokenRepository interface
+type UserTokenRepository interface {
+	Create(token UserToken) error
+	FindByHash(tokenHash string) (UserToken, error)
+	MarkUsed(id string) error
+}
+
+// UserToken struct conveying a one-time token that is sent to a user,
+// e.g. to reset their password. Only the hash of the token is stored.
+type UserToken struct {
+	ID         string
+	UserID     string
+	Purpose    TokenPurpose
+	TokenHash  string
+	CreatedAt  time.Time
+	ExpiryDate time.Time
+
+	// keeps track if the token has been used or not
+	// This is used to make sure a token can only be used once
+	Used bool
+}
+
+// NewUserToken creates a new one-time token for the user, holding the
+// hash of the provided token. Tokens are valid for the amount of time
+// specified by the validFor parameter e.g. 1 hour -> time.Hour
+func NewUserToken(id, userID string, purpose TokenPurpose, token string, validFor time.Duration) (UserToken, error) {
+	if len(strings.TrimSpace(userID)) == 0 {
+		return UserToken{}, e.Invalid("user", "can't be empty")
+	}
+
+	if len(strings.TrimSpace(token)) == 0 {
+		return UserToken{}, e.Invalid("token", "can't be empty")
+	}
+
+	now := time.Now()
+	return UserToken{
+		ID:         id,
+		UserID:     userID,
+		Purpose:    purpose,
+		TokenHash:  HashToken(token),
+		CreatedAt:  now,
+		ExpiryDate: now.Add(validFor),
+		Used:       false,
+	}, nil
+}
+
+// HasExpired checks if the expiry date
+// of the token has been reached
+func (t UserToken) HasExpired() bool {
+	return time.Now().After(t.ExpiryDate)
+}
+
+// HasBeenUsed checks if the token
+// has been used or not
+func (t UserToken) HasBeenUsed() bool {
+	return t.Used
+}
+
+// IsValidFor checks if the token can still be used for the provided purpose
+func (t UserToken) IsValidFor(purpose TokenPurpose) bool {
+	return t.Purpose == purpose && !t.HasExpired() && !t.HasBeenUsed()
+}
@@ -1,14 +1,186 @@
+// Package errors contains the errors used throughout the application. Errors
+// of a known Kind tell clients what went wrong, e.g. that an entity doesn't
+// exist or that a field is invalid. All other errors are unexpected.
 package errors
 
-import "errors"
+import "strings"
 
-// package containing custom errors, used throughout the application
+// Kind of an error, deciding how it is reported to clients
+type Kind int
+
+const (
+	// Unexpected errors are failures of the app itself, such as a lost
+	// connection to the database. Their details aren't shared with clients.
+	Unexpected Kind = iota
+	// NotFound errors report that the requested entity doesn't exist
+	NotFound
+	// Conflict errors report that the request clashes with the current
+	// state, e.g. an email that is already in use
+	Conflict
+	// Validation errors report invalid input, along with the invalid fields
+	Validation
+	// Unauthorized errors report missing or invalid credentials
+	Unauthorized
+	// Forbidden errors report that the user may not perform the action
+	Forbidden
+	// Expired errors report that something can't be used anymore, as it has expired
+	Expired
+	// RateLimited errors report that the user has to wait before trying again
+	RateLimited
+)
+
+// Error struct conveying an error of a known kind
+type Error struct {
+	Kind    Kind
+	Message string       // what went wrong, which can be shown to clients
+	Fields  []FieldError // the invalid fields of validation errors
+	Err     error        // the error that caused this one, if any
+}
+
+// FieldError struct conveying why the value of a field is invalid
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+// Error returns the message of the error, followed by its cause
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error that caused this one
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error of the provided kind
+func New(kind Kind, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap creates an error of the provided kind, caused by err
+func Wrap(err error, kind Kind, message string) error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// Invalid creates a validation error reporting why the field is invalid
+func Invalid(field, reason string) error {
+	return &Error{
+		Kind:    Validation,
+		Message: "provided " + field + " " + reason,
+		Fields:  []FieldError{{Field: field, Reason: reason}},
+	}
+}
+
+// InvalidFields creates a validation error reporting all invalid fields at once
+func InvalidFields(fields ...FieldError) error {
+	reasons := []string{}
+	for _, field := range fields {
+		reasons = append(reasons, "provided "+field.Field+" "+field.Reason)
+	}
+
+	return &Error{
+		Kind:    Validation,
+		Message: strings.Join(reasons, ", "),
+		Fields:  fields,
+	}
+}
+
+// As returns the first error of a known kind in the chain of err
+func As(err error) (*Error, bool) {
+	for err != nil {
+		if known, ok := err.(*Error); ok {
+			return known, true
+		}
+
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil, false
+		}
+		err = wrapper.Unwrap()
+	}
+
+	return nil, false
+}
+
+// KindOf returns the kind of err, Unexpected if it isn't of a known kind
+func KindOf(err error) Kind {
+	known, ok := As(err)
+	if !ok {
+		return Unexpected
+	}
+
+	return known.Kind
+}
+
+// Is checks if err is the target or has been caused by it
+func Is(err, target error) bool {
+	for err != nil {
+		if err == target {
+			return true
+		}
+
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = wrapper.Unwrap()
+	}
+
+	return false
+}
 
 // ErrorEmailAlreadyUsed ...
-var ErrorEmailAlreadyUsed = errors.New("email is already in use and bound to an account")
+var ErrorEmailAlreadyUsed = New(Conflict, "email is already in use and bound to an account")
 
 // ErrorCompanyNameAlreadyUsed ...
-var ErrorCompanyNameAlreadyUsed = errors.New("company name is already in use")
+var ErrorCompanyNameAlreadyUsed = New(Conflict, "company name is already in use")
 
 // ErrorEntityNotFound ...
-var ErrorEntityNotFound = errors.New("entity does not exist with the provided id")
+var ErrorEntityNotFound = New(NotFound, "entity does not exist with the provided id")
+
+// ErrorNotParticipant ...
+var ErrorNotParticipant = New(Forbidden, "user is not a participant of the conversation")
+
+// ErrorRateLimited ...
+var ErrorRateLimited = New(RateLimited, "too many new conversations have been started, try again later")
+
+// ErrorBlocked ...
+var ErrorBlocked = New(Forbidden, "the receiver has blocked the company of the sender")
+
+// ErrorForbidden ...
+var ErrorForbidden = New(Forbidden, "user is not allowed to perform this action")
+
+// ErrorAlreadyApplied ...
+var ErrorAlreadyApplied = New(Conflict, "student has already applied to this project")
+
+// ErrorInvalidTransition ...
+var ErrorInvalidTransition = New(Conflict, "application can't move to the provided status")
+
+// ErrorAlreadyShortlisted ...
+var ErrorAlreadyShortlisted = New(Conflict, "student is already on the shortlist for this project")
+
+// ErrorInvalidToken ...
+var ErrorInvalidToken = New(Unauthorized, "token is invalid, expired or revoked")
+
+// ErrorTokenReused ...
+var ErrorTokenReused = New(Unauthorized, "refresh token has already been used, all sessions of this signin are revoked")
+
+// ErrorTokenExpired ...
+var ErrorTokenExpired = New(Expired, "token has expired, request a new one")
+
+// ErrorInvalidCredentials ...
+var ErrorInvalidCredentials = New(Unauthorized, "email or password is incorrect")
+
+// ErrorEmailNotVerified ...
+var ErrorEmailNotVerified = New(Forbidden, "email has to be verified before performing this action")
+
+// ErrorInviteNotPending ...
+var ErrorInviteNotPending = New(Conflict, "invitation has already been used or revoked")
+
+// ErrorInviteExpired ...
+var ErrorInviteExpired = New(Expired, "invitation has expired")
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+// ApplicationHandler struct containing all
+// application related handler funcs
+type ApplicationHandler struct {
+	ApplicationService services.ApplicationService
+	AuthHandler        AuthHandler
+	Path               string
+}
+
+// ApplicationData is a struct that corresponds to incoming application data
+type ApplicationData struct {
+	ID         string    `json:"id"`
+	StudentID  string    `json:"studentID"`
+	ProjectID  string    `json:"projectID"`
+	Motivation string    `json:"motivation"`
+	Resume     string    `json:"resume"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Apply lets the logged in student apply to a project. The request is a
+// multipart form containing the applicationData and optionally a resume
+// that is sent along instead of the resume of the student.
+// path = /applications/apply/... where the dots are a project ID
+func (a ApplicationHandler) Apply() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := a.AuthHandler.GetToken(cookie)
+		studentID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		// the resume is optional, so only fail if one was sent but couldn't be stored
+		resumePath, err := processResume(r)
+		if err != nil && !e.Is(err, http.ErrMissingFile) {
+			writeError(w, err)
+			return
+		}
+
+		// the stored resume isn't needed if the application isn't submitted
+		submitted := false
+		defer func() {
+			if resumePath != "" && !submitted {
+				removeResume(resumePath)
+			}
+		}()
+
+		var data ApplicationData
+
+		// check if json is invalid
+		err = json.Unmarshal([]byte(r.FormValue("applicationData")), &data)
+		if err != nil {
+			err = e.Wrap(err, e.Validation, "provided applicationData isn't valid json")
+			writeError(w, err)
+			return
+		}
+
+		projectID := strings.TrimPrefix(r.URL.Path, a.Path+"apply/")
+		application, err := domain.NewApplication(
+			uuid.New().String(),
+			studentID,
+			projectID,
+			data.Motivation,
+			resumePath,
+		)
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = a.ApplicationService.Apply(application)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		submitted = true
+		json.NewEncoder(w).Encode(application.ID)
+	})
+}
+
+// Withdraw lets the logged in student withdraw their application.
+// path = /applications/withdraw/... where the dots are an application ID
+func (a ApplicationHandler) Withdraw() http.Handler {
+	return a.moveByStudent("withdraw/", domain.ApplicationWithdrawn)
+}
+
+// Accept lets the logged in student accept the offer made on their application.
+// path = /applications/accept/... where the dots are an application ID
+func (a ApplicationHandler) Accept() http.Handler {
+	return a.moveByStudent("accept/", domain.ApplicationAccepted)
+}
+
+// ChangeStatus lets the logged in representative move an application to
+// a project of their company to the status present in the application data.
+// path = /applications/status/... where the dots are an application ID
+func (a ApplicationHandler) ChangeStatus() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := a.AuthHandler.GetToken(cookie)
+		representativeID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		var data ApplicationData
+
+		// check if json is invalid
+		err := decodeJSON(r, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		applicationID := strings.TrimPrefix(r.URL.Path, a.Path+"status/")
+		application, err := a.ApplicationService.MoveByRepresentative(
+			applicationID,
+			representativeID,
+			domain.ApplicationStatus(data.Status),
+		)
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(ToApplicationData(application))
+	})
+}
+
+// FetchOwnApplications fetches all applications of the logged in student
+func (a ApplicationHandler) FetchOwnApplications() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := a.AuthHandler.GetToken(cookie)
+		studentID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		applications, err := a.ApplicationService.FindByStudent(studentID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		applicationsData := []ApplicationData{}
+		for _, application := range applications {
+			applicationsData = append(applicationsData, ToApplicationData(application))
+		}
+
+		json.NewEncoder(w).Encode(applicationsData)
+	})
+}
+
+// FetchProjectApplications fetches all applications to a project
+// of the company the logged in representative works for.
+// path = /applications/project/... where the dots are a project ID
+func (a ApplicationHandler) FetchProjectApplications() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := a.AuthHandler.GetToken(cookie)
+		representativeID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		projectID := strings.TrimPrefix(r.URL.Path, a.Path+"project/")
+		applications, err := a.ApplicationService.FindByProject(projectID, representativeID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		applicationsData := []ApplicationData{}
+		for _, application := range applications {
+			applicationsData = append(applicationsData, ToApplicationData(application))
+		}
+
+		json.NewEncoder(w).Encode(applicationsData)
+	})
+}
+
+// Register registers all application related handlers
+func (a ApplicationHandler) Register() {
+	http.Handle(a.Path+"apply/", LoggingHandler(os.Stdout, a.AuthHandler.Validate(domain.StudentRole, a.AuthHandler.Verified(a.Apply()))))
+	http.Handle(a.Path+"withdraw/", LoggingHandler(os.Stdout, a.AuthHandler.Validate(domain.StudentRole, a.Withdraw())))
+	http.Handle(a.Path+"accept/", LoggingHandler(os.Stdout, a.AuthHandler.Validate(domain.StudentRole, a.Accept())))
+	http.Handle(a.Path+"mine/", LoggingHandler(os.Stdout, a.AuthHandler.Validate(domain.StudentRole, a.FetchOwnApplications())))
+	http.Handle(a.Path+"status/", LoggingHandler(os.Stdout, a.AuthHandler.Validate(domain.RepresentativeRole, a.ChangeStatus())))
+	http.Handle(a.Path+"project/", LoggingHandler(os.Stdout, a.AuthHandler.Validate(domain.RepresentativeRole, a.FetchProjectApplications())))
+}
+
+// helper func that returns a handler moving the application,
+// whose id follows the provided route, to the provided status
+// in name of the logged in student
+func (a ApplicationHandler) moveByStudent(route string, status domain.ApplicationStatus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := a.AuthHandler.GetToken(cookie)
+		studentID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		applicationID := strings.TrimPrefix(r.URL.Path, a.Path+route)
+		application, err := a.ApplicationService.MoveByStudent(applicationID, studentID, status)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(ToApplicationData(application))
+	})
+}
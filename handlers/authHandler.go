@@ -5,23 +5,34 @@ package handlers
 // such as ValidateRequests and Signin.
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 	"github.com/janabe/cscoupler/services"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
+// accessTokenTTL is how long an access token stays valid. Clients
+// get a new one from /refresh, using their refresh token.
+const accessTokenTTL = 15 * time.Minute
+
+// errSignedOut is reported to requests to secured
+// endpoints without a valid token of an active session
+var errSignedOut = e.New(e.Unauthorized, "user is not signed in or the session has ended")
+
 // AuthHandler struct containing all authorization
 // related handler/middleware funcs
 type AuthHandler struct {
-	JWTKey      []byte
-	UserService services.UserService
+	JWTKey         []byte
+	UserService    services.UserService
+	PolicyService  services.PolicyService
+	SessionService services.SessionService
 }
 
 // UserData is a struct that corresponds to incoming user data
@@ -32,18 +43,31 @@ type UserData struct {
 	Lastname  string `json:"lastname"`
 }
 
+// PasswordResetData is a struct that corresponds to incoming password reset data
+type PasswordResetData struct {
+	Email    string `json:"email,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// EmailVerificationData is a struct that corresponds to incoming email verification data
+type EmailVerificationData struct {
+	Token string `json:"token"`
+}
+
 // Claims is a struct to convey the second part of the JWT (sometimes called payload)
 type Claims struct {
-	ID     string
-	Email  string
-	UserID string
-	Role   string
+	ID      string
+	Email   string
+	UserID  string
+	Role    string
+	Session string // family of the session the token belongs to
 	jwt.StandardClaims
 }
 
-// Signin returns a handler for signin requests, creating
-// a JWT for the user if all credentials are correct
-// and storing this token in a cookie
+// Signin returns a handler for signin requests, starting a new
+// session for the user if all credentials are correct. A short
+// lived JWT and a refresh token are stored in cookies.
 func (a AuthHandler) Signin() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -52,116 +76,289 @@ func (a AuthHandler) Signin() http.Handler {
 
 		var data UserData
 
-		err := json.NewDecoder(r.Body).Decode(&data)
+		err := decodeJSON(r, &data)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		// Check if account with email exists
 		user, err := a.UserService.FindByEmail(strings.ToLower(data.Email))
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusUnauthorized)
+			writeError(w, credentialsError(err))
 			return
 		}
 
 		isValid := a.UserService.ValidatePassword(user.HashedPassword, data.Password)
 		if !isValid {
-			w.WriteHeader(http.StatusUnauthorized)
+			writeError(w, e.ErrorInvalidCredentials)
+			return
+		}
+
+		// representatives that have been removed from their company can't sign in anymore
+		_, err = a.UserService.FindRoleID(user)
+		if err != nil {
+			writeError(w, credentialsError(err))
+			return
+		}
+
+		session, refreshToken, err := a.SessionService.Start(user.ID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = a.setTokens(w, user, session, refreshToken)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	})
+}
+
+// Refresh returns a handler for refresh requests, rotating the refresh
+// token in the cookie and issuing a new JWT. Reusing a refresh token
+// signs out the device it was issued to.
+func (a AuthHandler) Refresh() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		cookie, err := r.Cookie("refresh_token")
+		if err != nil {
+			writeError(w, e.ErrorInvalidToken)
+			return
+		}
+
+		session, refreshToken, err := a.SessionService.Refresh(cookie.Value)
+		if e.KindOf(err) == e.Unauthorized {
+			clearTokens(w)
+		}
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		user, err := a.UserService.FindByID(session.UserID)
+		if err != nil {
+			writeError(w, credentialsError(err))
+			return
+		}
+
+		err = a.setTokens(w, user, session, refreshToken)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	})
+}
+
+// Signout returns a handler that signs the user out of the current
+// device, revoking the session the JWT belongs to
+func (a AuthHandler) Signout() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := a.GetToken(cookie)
+		family := token.Claims.(jwt.MapClaims)["Session"].(string)
+
+		err := a.SessionService.SignOut(family)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		clearTokens(w)
+	})
+}
+
+// SignoutAll returns a handler that signs the user
+// out of all devices, revoking all their sessions
+func (a AuthHandler) SignoutAll() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		subject, err := a.Subject(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = a.SessionService.SignOutAll(subject.UserID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		clearTokens(w)
+	})
+}
+
+// ForgotPassword returns a handler that mails a password reset link to the
+// provided email. It responds the same whether or not the email is in use.
+func (a AuthHandler) ForgotPassword() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		var data PasswordResetData
+
+		err := decodeJSON(r, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = a.UserService.RequestPasswordReset(data.Email)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	})
+}
+
+// ResetPassword returns a handler that sets a new password for the user
+// the provided reset token was sent to, signing them out of all devices
+func (a AuthHandler) ResetPassword() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		var data PasswordResetData
+
+		err := decodeJSON(r, &data)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
-		roleID, err := a.UserService.FindRoleID(user)
+		err = a.UserService.ResetPassword(data.Token, data.Password)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
+			return
+		}
+
+		clearTokens(w)
+	})
+}
+
+// VerifyEmail returns a handler that marks the email the
+// provided verification token was sent to as verified
+func (a AuthHandler) VerifyEmail() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
 			return
 		}
 
-		// Build the claims part of the JWT and
-		// set the expiration time of the JWT (todo: find out what a good time is)
-		expirationTime := time.Now().Add(6 * time.Hour)
-		claims := &Claims{
-			ID:     roleID,
-			Email:  user.Email,
-			UserID: user.ID,
-			Role:   user.Role,
-			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: expirationTime.Unix(),
-			},
+		var data EmailVerificationData
+
+		err := decodeJSON(r, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = a.UserService.VerifyEmail(data.Token)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	})
+}
+
+// ResendVerification returns a handler that mails a new
+// verification link to the logged in user
+func (a AuthHandler) ResendVerification() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		subject, err := a.Subject(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = a.UserService.ResendVerification(subject.UserID)
+		if err != nil {
+			writeError(w, err)
+			return
 		}
+	})
+}
 
-		// Create new token
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString(a.JWTKey)
+// Verified returns a handler that only lets users who verified their
+// email call h. It should only wrap handlers that are wrapped in Validate.
+func (a AuthHandler) Verified(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject, err := a.Subject(r)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 
-		http.SetCookie(w, &http.Cookie{
-			Name:    "token",
-			Value:   tokenString,
-			Expires: expirationTime,
-			// todo: fix -> doesn't work with httponly and secure flags enabled
-		})
+		if !a.UserService.IsEmailVerified(subject.UserID) {
+			writeError(w, e.ErrorEmailNotVerified)
+			return
+		}
+
+		h.ServeHTTP(w, r)
 	})
 }
 
 // Validate returns a handler used to secure endpoints.
 // It validates incoming requests by checking if the user has a valid
-// token and the correct role, and is thus allowed to call this endpoint or not.
+// token, of a session that hasn't been revoked, and the correct role,
+// and is thus allowed to call this endpoint or not.
 // If the token is valid, h.serveHTTP() gets called which means the page is shown.
 // If the role param is left empty (""), all roles are allowed to call this endpoint.
 func (a AuthHandler) Validate(role string, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		cookie, err := r.Cookie("token")
 		if err != nil {
-			if err == http.ErrNoCookie {
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
-
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, errSignedOut)
 			return
 		}
 
 		token, err := a.GetToken(cookie)
-		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
-
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		if !token.Valid {
-			w.WriteHeader(http.StatusUnauthorized)
+		if err != nil || !token.Valid {
+			writeError(w, e.Wrap(err, e.Unauthorized, "token is invalid"))
 			return
 		}
 
 		claims := token.Claims.(jwt.MapClaims)
 		userEmail, ok := claims["Email"].(string)
 		if !ok {
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, e.New(e.Unauthorized, "token is invalid"))
+			return
+		}
+
+		// tokens of revoked sessions are no longer accepted, even if they haven't expired
+		family, ok := claims["Session"].(string)
+		if !ok || !a.SessionService.IsActive(family) {
+			writeError(w, errSignedOut)
 			return
 		}
 
 		user, err := a.UserService.FindByEmail(userEmail)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, credentialsError(err))
 			return
 		}
 
 		if role != "" {
 			if user.Role != role {
-				w.WriteHeader(http.StatusUnauthorized)
+				writeError(w, e.Wrap(e.ErrorForbidden, e.Forbidden, "endpoint is only available to the "+role+" role"))
 				return
 			}
 		}
@@ -174,6 +371,46 @@ func (a AuthHandler) Validate(role string, h http.Handler) http.Handler {
 // Register registers all authentication related handlers
 func (a AuthHandler) Register() {
 	http.Handle("/signin", LoggingHandler(os.Stdout, a.Signin()))
+	http.Handle("/refresh", LoggingHandler(os.Stdout, a.Refresh()))
+	http.Handle("/signout", LoggingHandler(os.Stdout, a.Validate("", a.Signout())))
+	http.Handle("/signout/all", LoggingHandler(os.Stdout, a.Validate("", a.SignoutAll())))
+	http.Handle("/password/forgot", LoggingHandler(os.Stdout, a.ForgotPassword()))
+	http.Handle("/password/reset", LoggingHandler(os.Stdout, a.ResetPassword()))
+	http.Handle("/verify-email", LoggingHandler(os.Stdout, a.VerifyEmail()))
+	http.Handle("/verify-email/resend", LoggingHandler(os.Stdout, a.Validate("", a.ResendVerification())))
+}
+
+// Subject returns the logged in user of the request, as known from the
+// claims of their token. It should only be called by handlers wrapped
+// in Validate, as the token isn't validated here.
+func (a AuthHandler) Subject(r *http.Request) (domain.Subject, error) {
+	cookie, err := r.Cookie("token")
+	if err != nil {
+		return domain.Subject{}, e.Wrap(err, e.Unauthorized, "token is missing")
+	}
+
+	token, err := a.GetToken(cookie)
+	if err != nil {
+		return domain.Subject{}, e.Wrap(err, e.Unauthorized, "token is invalid")
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	id, _ := claims["ID"].(string)
+	userID, _ := claims["UserID"].(string)
+	role, _ := claims["Role"].(string)
+
+	return domain.Subject{ID: id, UserID: userID, Role: role}, nil
+}
+
+// Authorize checks if the logged in user of the request may perform
+// the action on the resource, returning e.ErrorForbidden if not
+func (a AuthHandler) Authorize(r *http.Request, action domain.Action, resource domain.Resource) error {
+	subject, err := a.Subject(r)
+	if err != nil {
+		return e.ErrorForbidden
+	}
+
+	return a.PolicyService.Authorize(subject, action, resource)
 }
 
 // GetToken gets the token from the cookie
@@ -189,3 +426,67 @@ func (a AuthHandler) GetToken(cookie *http.Cookie) (*jwt.Token, error) {
 
 	return token, err
 }
+
+// helper func that signs a JWT for the user, belonging to the session,
+// and stores it in a cookie together with the refresh token of the session
+func (a AuthHandler) setTokens(w http.ResponseWriter, user domain.User, session domain.Session, refreshToken string) error {
+	roleID, err := a.UserService.FindRoleID(user)
+	if err != nil {
+		return err
+	}
+
+	// Build the claims part of the JWT and set the expiration time of the JWT
+	expirationTime := time.Now().Add(accessTokenTTL)
+	claims := &Claims{
+		ID:      roleID,
+		Email:   user.Email,
+		UserID:  user.ID,
+		Role:    user.Role,
+		Session: session.FamilyID,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expirationTime.Unix(),
+		},
+	}
+
+	// Create new token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(a.JWTKey)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:    "token",
+		Value:   tokenString,
+		Expires: expirationTime,
+		// todo: fix -> doesn't work with httponly and secure flags enabled
+	})
+
+	// the refresh token is only sent along to /refresh
+	// and never has to be read by the client itself
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Path:     "/refresh",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+	})
+
+	return nil
+}
+
+// helper func that reports users that don't exist (anymore) as invalid
+// credentials, so it isn't revealed which emails are in use
+func credentialsError(err error) error {
+	if e.Is(err, e.ErrorEntityNotFound) {
+		return e.ErrorInvalidCredentials
+	}
+
+	return err
+}
+
+// helper func that removes the cookies containing the tokens
+func clearTokens(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: "token", Value: "", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{Name: "refresh_token", Value: "", Path: "/refresh", MaxAge: -1})
+}
@@ -2,12 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"os"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 	"github.com/janabe/cscoupler/domain"
 	e "github.com/janabe/cscoupler/errors"
@@ -54,25 +52,22 @@ func (c CompanyHandler) SignupCompany() http.Handler {
 		var data CompanyData
 
 		// check if json is invalid
-		err := json.NewDecoder(r.Body).Decode(&data)
+		err := decodeJSON(r, &data)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		company, err := domain.NewCompany(uuid.New().String(), data.Name, data.Information, data.Description)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		for _, l := range data.Locations {
 			location, err := domain.NewAddress(uuid.New().String(), l.Street, l.Zipcode, l.City, l.Number)
 			if err != nil {
-				fmt.Println(err)
-				w.WriteHeader(http.StatusBadRequest)
+				writeError(w, err)
 				return
 			}
 
@@ -85,7 +80,7 @@ func (c CompanyHandler) SignupCompany() http.Handler {
 		// There should be 1 representative sent
 		// when creating a company, the main representative.
 		if len(data.Representatives) != 1 {
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, e.Invalid("representatives", "should contain the main representative only"))
 			return
 		}
 
@@ -99,8 +94,7 @@ func (c CompanyHandler) SignupCompany() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -112,24 +106,22 @@ func (c CompanyHandler) SignupCompany() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		company.Representatives = append(company.Representatives, representative)
 
 		err = c.CompanyService.Register(company)
-		if err == e.ErrorEmailAlreadyUsed || err == e.ErrorCompanyNameAlreadyUsed {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusConflict)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
+		// the account exists either way, the mail can be sent again later
+		err = c.AuthHandler.UserService.SendVerification(representative.User)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
+			logError(err)
 		}
 
 		json.NewEncoder(w).Encode(company.ID)
@@ -143,53 +135,36 @@ func (c CompanyHandler) EditCompany() http.Handler {
 			return
 		}
 
-		cookie, _ := r.Cookie("token")
-		token, _ := c.AuthHandler.GetToken(cookie)
-		reprID := token.Claims.(jwt.MapClaims)["ID"].(string)
-
 		companyID := strings.TrimPrefix(r.URL.Path, c.Path+"edit/")
 		company, err := c.CompanyService.FindByID(companyID)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
-		// Validate that the representative editing the company data
-		// is an employee of this specific company
-		var worksForCompany bool
-		for _, r := range company.Representatives {
-			if r.ID == reprID {
-				worksForCompany = true
-				break
-			}
-		}
-
-		if !worksForCompany {
-			w.WriteHeader(http.StatusUnauthorized)
+		err = c.AuthHandler.Authorize(r, domain.EditCompany, domain.Resource{CompanyID: company.ID})
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
 		var updatedCompanyData CompanyData
-		err = json.NewDecoder(r.Body).Decode(&updatedCompanyData)
+		err = decodeJSON(r, &updatedCompanyData)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		updatedCompany, err := domain.NewCompany(companyID, updatedCompanyData.Name, updatedCompanyData.Information, updatedCompanyData.Description)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		for _, l := range updatedCompanyData.Locations {
 			location, err := domain.NewAddress(l.ID, l.Street, l.Zipcode, l.City, l.Number)
 			if err != nil {
-				fmt.Println(err)
-				w.WriteHeader(http.StatusBadRequest)
+				writeError(w, err)
 				return
 			}
 
@@ -202,8 +177,7 @@ func (c CompanyHandler) EditCompany() http.Handler {
 		for _, p := range updatedCompanyData.Projects {
 			project, err := domain.NewProject(p.ID, p.Description, p.Compensation, p.Duration, p.CompanyID, p.Recommendations)
 			if err != nil {
-				fmt.Println(err)
-				w.WriteHeader(http.StatusBadRequest)
+				writeError(w, err)
 				return
 			}
 
@@ -212,8 +186,7 @@ func (c CompanyHandler) EditCompany() http.Handler {
 
 		err = c.CompanyService.Edit(updatedCompany)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -232,8 +205,7 @@ func (c CompanyHandler) FetchCompanyByID() http.Handler {
 		id := strings.TrimPrefix(r.URL.Path, c.Path)
 		company, err := c.CompanyService.FindByID(id)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
@@ -254,8 +226,7 @@ func (c CompanyHandler) FetchCompanyNameByID() http.Handler {
 		id := strings.TrimPrefix(r.URL.Path, c.Path+"name/")
 		company, err := c.CompanyService.FindByID(id)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
@@ -265,26 +236,32 @@ func (c CompanyHandler) FetchCompanyNameByID() http.Handler {
 	})
 }
 
-// FetchAllCompanies fetches all the companies
+// FetchAllCompanies fetches a page of all the companies,
+// selected with the limit and cursor query params
 func (c CompanyHandler) FetchAllCompanies() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" && r.Method != "OPTIONS" {
 			return
 		}
 
-		companies, err := c.CompanyService.FindAll()
+		page, err := toPage(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		companies, next, err := c.CompanyService.FindAll(page)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Println(err)
+			writeError(w, err)
 			return
 		}
 
-		var companiesData []CompanyData
+		companiesData := []CompanyData{}
 		for _, c := range companies {
 			companiesData = append(companiesData, ToCompanyData(c))
 		}
 
-		json.NewEncoder(w).Encode(companiesData)
+		json.NewEncoder(w).Encode(ToPageData(companiesData, next))
 	})
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/janabe/cscoupler/util"
 
 	d "github.com/janabe/cscoupler/domain"
+	"github.com/janabe/cscoupler/services"
 )
 
 // mappers contains functions that map *Data struct to domain structs
@@ -70,12 +71,15 @@ func ToStudentData(s d.Student) StudentData {
 	studentData := StudentData{
 		ID:               s.ID,
 		University:       s.University,
+		City:             s.City,
 		Skills:           s.Skills,
 		Experiences:      s.Experiences,
 		ShortExperiences: s.ShortExperiences,
 		Wishes:           s.Wishes,
 		Status:           ToStatus(strconv.Itoa(int(uint8(s.Status)))),
 		Resume:           s.Resume,
+		Visibility:       string(s.Visibility),
+		ResumeOnRequest:  s.ResumeOnRequest,
 		UserData: UserData{
 			Email:     s.User.Email,
 			Firstname: strings.Title(s.User.FirstName),
@@ -90,8 +94,9 @@ func ToStudentData(s d.Student) StudentData {
 // to a representativeData struct
 func ToRepresentativeData(r d.Representative) RepresentativeData {
 	representativeData := RepresentativeData{
-		JobTitle:  r.JobTitle,
-		CompanyID: r.CompanyID,
+		JobTitle:    r.JobTitle,
+		CompanyID:   r.CompanyID,
+		CompanyRole: string(r.CompanyRole),
 		UserData: UserData{
 			Email:     r.User.Email,
 			Firstname: r.User.FirstName,
@@ -112,11 +117,113 @@ func ToProjectData(p d.Project) ProjectData {
 		Duration:        p.Duration,
 		Recommendations: p.Recommendations,
 		CompanyID:       p.CompanyID,
+		ContactID:       p.ContactID,
 	}
 
 	return projectData
 }
 
+// ToMessageData maps a message domain struct
+// to a messageData struct
+func ToMessageData(m d.Message) MessageData {
+	messageData := MessageData{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		Sender:         m.Sender,
+		Receiver:       m.Receiver,
+		Body:           m.Body,
+		ProjectID:      m.ProjectID,
+		CreatedAt:      m.CreatedAt,
+	}
+
+	return messageData
+}
+
+// ToConversationData maps a conversation domain struct
+// to a conversationData struct
+func ToConversationData(c d.Conversation) ConversationData {
+	conversationData := ConversationData{
+		ID:           c.ID,
+		Participants: c.Participants,
+		ProjectID:    c.ProjectID,
+		StartedBy:    c.StartedBy,
+		CreatedAt:    c.CreatedAt,
+		LastActivity: c.LastActivity,
+		ReadAt:       c.ReadAt,
+		Unread:       c.Unread,
+	}
+
+	return conversationData
+}
+
+// ToApplicationData maps an application domain struct
+// to an applicationData struct
+func ToApplicationData(a d.Application) ApplicationData {
+	applicationData := ApplicationData{
+		ID:         a.ID,
+		StudentID:  a.StudentID,
+		ProjectID:  a.ProjectID,
+		Motivation: a.Motivation,
+		Resume:     a.Resume,
+		Status:     string(a.Status),
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+
+	return applicationData
+}
+
+// ToInviteLinkData maps an invitelink domain struct
+// to an inviteLinkData struct
+func ToInviteLinkData(i d.InviteLink) InviteLinkData {
+	inviteLinkData := InviteLinkData{
+		ID:         i.ID,
+		Kind:       string(i.Kind),
+		TargetID:   i.TargetID,
+		Role:       i.Role,
+		URL:        i.URL,
+		Email:      i.Email,
+		Status:     string(i.Status()),
+		MaxUses:    i.MaxUses,
+		Uses:       i.Uses,
+		CreatedAt:  i.CreatedAt,
+		ExpiryDate: i.ExpiryDate,
+	}
+
+	return inviteLinkData
+}
+
+// ToShortlistEntryData maps a shortlist entry domain struct
+// to a shortlistEntryData struct
+func ToShortlistEntryData(s d.ShortlistEntry) ShortlistEntryData {
+	entryData := ShortlistEntryData{
+		ID:        s.ID,
+		CompanyID: s.CompanyID,
+		StudentID: s.StudentID,
+		ProjectID: s.ProjectID,
+		Notes:     s.Notes,
+		Tags:      s.Tags,
+		AddedBy:   s.AddedBy,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+
+	return entryData
+}
+
+// ToResumeRequestData maps a resume request domain
+// struct to a resumeRequestData struct
+func ToResumeRequestData(r d.ResumeRequest) ResumeRequestData {
+	requestData := ResumeRequestData{
+		CompanyID:   r.CompanyID,
+		RequestedBy: r.RequestedBy,
+		RequestedAt: r.RequestedAt,
+		Granted:     r.IsGranted(),
+	}
+
+	return requestData
+}
+
 // ToStatus transforms the status number
 // to the corresponding string representation
 func ToStatus(num string) string {
@@ -130,3 +237,36 @@ func ToStatus(num string) string {
 
 	return num
 }
+
+// ToSearchHitData maps a search hit domain struct
+// to a searchHitData struct
+func ToSearchHitData(h d.SearchHit) SearchHitData {
+	return SearchHitData{
+		Kind:      string(h.Kind),
+		ID:        h.ID,
+		CompanyID: h.CompanyID,
+		Title:     h.Title,
+		Snippet:   h.Snippet,
+		Rank:      h.Rank,
+	}
+}
+
+// ToStudentMatchData maps a student match to a matchData struct
+func ToStudentMatchData(m services.StudentMatch) MatchData {
+	studentData := ToStudentData(m.Student)
+	return MatchData{
+		Score:         m.Match.Score,
+		MatchedSkills: m.Match.MatchedSkills,
+		Student:       &studentData,
+	}
+}
+
+// ToProjectMatchData maps a project match to a matchData struct
+func ToProjectMatchData(m services.ProjectMatch) MatchData {
+	projectData := ToProjectData(m.Project)
+	return MatchData{
+		Score:         m.Match.Score,
+		MatchedSkills: m.Match.MatchedSkills,
+		Project:       &projectData,
+	}
+}
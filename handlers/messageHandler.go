@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+
+	"github.com/janabe/cscoupler/domain"
+	"github.com/janabe/cscoupler/services"
+)
+
+// MessageHandler struct containing all
+// message related handler funcs
+type MessageHandler struct {
+	MessageService services.MessageService
+	AuthHandler    AuthHandler
+	Path           string
+}
+
+// MessageData is a struct that corresponds to incoming message data
+type MessageData struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversationID"`
+	Sender         string    `json:"sender"`
+	Receiver       string    `json:"receiver"`
+	Body           string    `json:"body"`
+	ProjectID      string    `json:"projectID"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// ConversationData is a struct that corresponds to outgoing conversation data
+type ConversationData struct {
+	ID           string               `json:"id"`
+	Participants []string             `json:"participants"`
+	ProjectID    string               `json:"projectID"`
+	StartedBy    string               `json:"startedBy"`
+	CreatedAt    time.Time            `json:"createdAt"`
+	LastActivity time.Time            `json:"lastActivity"`
+	ReadAt       map[string]time.Time `json:"readAt"`
+	Unread       int                  `json:"unread"`
+}
+
+// SendMessage sends a message from the logged in user. If the message data
+// contains a conversationID the message is sent in that conversation,
+// otherwise it is sent to the receiver present in the message data
+func (m MessageHandler) SendMessage() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := m.AuthHandler.GetToken(cookie)
+		userID := token.Claims.(jwt.MapClaims)["UserID"].(string)
+
+		var data MessageData
+
+		// check if json is invalid
+		err := decodeJSON(r, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var message domain.Message
+		if data.ConversationID != "" {
+			message, err = m.MessageService.Reply(data.ConversationID, userID, data.Body)
+		} else {
+			message, err = domain.NewMessage(
+				uuid.New().String(),
+				userID,
+				data.Receiver,
+				data.Body,
+				data.ProjectID,
+			)
+
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			message, err = m.MessageService.Send(message)
+		}
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(ToMessageData(message))
+	})
+}
+
+// FetchInbox fetches all conversations the logged in user is part of,
+// ordered from most to least recent activity, with their unread counts
+func (m MessageHandler) FetchInbox() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := m.AuthHandler.GetToken(cookie)
+		userID := token.Claims.(jwt.MapClaims)["UserID"].(string)
+
+		conversations, err := m.MessageService.Inbox(userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		conversationsData := []ConversationData{}
+		for _, conversation := range conversations {
+			conversationsData = append(conversationsData, ToConversationData(conversation))
+		}
+
+		json.NewEncoder(w).Encode(conversationsData)
+	})
+}
+
+// FetchThread fetches all messages of a conversation the logged in
+// user is part of, marking the conversation as read for this user.
+// path = /messages/... where the dots are the id of the conversation
+func (m MessageHandler) FetchThread() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := m.AuthHandler.GetToken(cookie)
+		userID := token.Claims.(jwt.MapClaims)["UserID"].(string)
+
+		conversationID := strings.TrimPrefix(r.URL.Path, m.Path)
+		messages, err := m.MessageService.FindThread(conversationID, userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		messagesData := []MessageData{}
+		for _, message := range messages {
+			messagesData = append(messagesData, ToMessageData(message))
+		}
+
+		json.NewEncoder(w).Encode(messagesData)
+	})
+}
+
+// BlockCompany blocks a company for the logged in student, so its
+// representatives can't start new conversations with the student.
+// path = /messages/block/... where the dots are a company ID
+func (m MessageHandler) BlockCompany() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := m.AuthHandler.GetToken(cookie)
+		studentID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		companyID := strings.TrimPrefix(r.URL.Path, m.Path+"block/")
+		err := m.MessageService.BlockCompany(studentID, companyID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// UnblockCompany lifts the block the logged in student placed on a company.
+// path = /messages/unblock/... where the dots are a company ID
+func (m MessageHandler) UnblockCompany() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := m.AuthHandler.GetToken(cookie)
+		studentID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		companyID := strings.TrimPrefix(r.URL.Path, m.Path+"unblock/")
+		err := m.MessageService.UnblockCompany(studentID, companyID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// FetchBlockedCompanies fetches the ids of all companies
+// the logged in student has blocked
+func (m MessageHandler) FetchBlockedCompanies() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := m.AuthHandler.GetToken(cookie)
+		studentID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		blocks, err := m.MessageService.FindBlocks(studentID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		companyIDs := []string{}
+		for _, b := range blocks {
+			companyIDs = append(companyIDs, b.CompanyID)
+		}
+
+		json.NewEncoder(w).Encode(companyIDs)
+	})
+}
+
+// Register registers all message related handlers
+func (m MessageHandler) Register() {
+	http.Handle(m.Path, LoggingHandler(os.Stdout, m.AuthHandler.Validate("", m.FetchThread())))
+	http.Handle(m.Path+"send", LoggingHandler(os.Stdout, m.AuthHandler.Validate("", m.AuthHandler.Verified(m.SendMessage()))))
+	http.Handle(m.Path+"inbox/", LoggingHandler(os.Stdout, m.AuthHandler.Validate("", m.FetchInbox())))
+	http.Handle(m.Path+"block/", LoggingHandler(os.Stdout, m.AuthHandler.Validate(domain.StudentRole, m.BlockCompany())))
+	http.Handle(m.Path+"unblock/", LoggingHandler(os.Stdout, m.AuthHandler.Validate(domain.StudentRole, m.UnblockCompany())))
+	http.Handle(m.Path+"blocked/", LoggingHandler(os.Stdout, m.AuthHandler.Validate(domain.StudentRole, m.FetchBlockedCompanies())))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// PageData is the envelope a page of a list is returned in.
+// NextCursor is empty when there are no more items.
+type PageData struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"nextCursor"`
+}
+
+// ToPageData wraps the items of a page in an envelope, turning
+// the id to continue after into an opaque cursor
+func ToPageData(items interface{}, next string) PageData {
+	cursor := ""
+	if next != "" {
+		cursor = base64.RawURLEncoding.EncodeToString([]byte(next))
+	}
+
+	return PageData{Items: items, NextCursor: cursor}
+}
+
+// helper func that reads the requested page from
+// the limit and cursor query params of the request
+func toPage(r *http.Request) (domain.Page, error) {
+	limit, err := toLimit(r)
+	if err != nil {
+		return domain.Page{}, err
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(r.URL.Query().Get("cursor"))
+	if err != nil {
+		return domain.Page{}, e.Invalid("cursor", "is invalid")
+	}
+
+	return domain.NewPage(limit, string(after)), nil
+}
+
+// helper func that reads the limit query param of the
+// request, which is 0 if the client didn't provide one
+func toLimit(r *http.Request) (int, error) {
+	l := r.URL.Query().Get("limit")
+	if l == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(l)
+	if err != nil || limit <= 0 {
+		return 0, e.Invalid("limit", "should be a positive number")
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// Problem is a struct that corresponds to outgoing problem details, as
+// described by RFC 7807. Every failed request is answered with one.
+type Problem struct {
+	Type          string             `json:"type"`
+	Title         string             `json:"title"`
+	Status        int                `json:"status"`
+	Detail        string             `json:"detail,omitempty"`
+	InvalidParams []InvalidParamData `json:"invalid-params,omitempty"`
+}
+
+// InvalidParamData is a struct that corresponds to
+// an outgoing reason why a field of a request is invalid
+type InvalidParamData struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// statuses contains the status code each kind of error is reported with
+var statuses = map[e.Kind]int{
+	e.Unexpected:   http.StatusInternalServerError,
+	e.NotFound:     http.StatusNotFound,
+	e.Conflict:     http.StatusConflict,
+	e.Validation:   http.StatusBadRequest,
+	e.Unauthorized: http.StatusUnauthorized,
+	e.Forbidden:    http.StatusForbidden,
+	e.Expired:      http.StatusGone,
+	e.RateLimited:  http.StatusTooManyRequests,
+}
+
+// ToProblem maps an error to the problem reporting it. The details
+// of unexpected errors aren't shared, only that something went wrong.
+func ToProblem(err error) Problem {
+	known, ok := e.As(err)
+	if !ok {
+		known = &e.Error{Kind: e.Unexpected}
+	}
+
+	status := statuses[known.Kind]
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	if known.Kind != e.Unexpected {
+		problem.Detail = known.Message
+	}
+
+	for _, field := range known.Fields {
+		problem.InvalidParams = append(problem.InvalidParams, InvalidParamData{
+			Name:   field.Field,
+			Reason: field.Reason,
+		})
+	}
+
+	return problem
+}
+
+// helper func that logs the error and answers
+// the request with the problem reporting it
+func writeError(w http.ResponseWriter, err error) {
+	logError(err)
+
+	problem := ToProblem(err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// helper func that logs the error, which is all that is done with errors
+// that can't be reported to the client, as the request succeeded anyway
+func logError(err error) {
+	log.Println(err)
+}
+
+// helper func that decodes the json body of the request into v,
+// reporting a body that can't be decoded as a validation error
+func decodeJSON(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		return e.Wrap(err, e.Validation, "request body isn't valid json")
+	}
+
+	return nil
+}
@@ -2,11 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"os"
 	"strings"
 
+	"github.com/dgrijalva/jwt-go"
+
 	"github.com/janabe/cscoupler/domain"
 	"github.com/janabe/cscoupler/services"
 )
@@ -14,9 +15,10 @@ import (
 // ProjectHandler struct containing all
 // project related handler funcs
 type ProjectHandler struct {
-	ProjectService services.ProjectService
-	AuthHandler    AuthHandler
-	Path           string
+	ProjectService  services.ProjectService
+	MatchingService services.MatchingService
+	AuthHandler     AuthHandler
+	Path            string
 }
 
 // ProjectData is a struct that corresponds to incoming project data
@@ -27,55 +29,65 @@ type ProjectData struct {
 	Duration        string   `json:"duration"`
 	Recommendations []string `json:"recommendations"`
 	CompanyID       string   `json:"companyID"`
+	ContactID       string   `json:"contactID"`
+}
+
+// MatchData is a struct that corresponds to outgoing match data,
+// containing either the matched student or the matched project
+type MatchData struct {
+	Score         float64      `json:"score"`
+	MatchedSkills []string     `json:"matchedSkills"`
+	Student       *StudentData `json:"student,omitempty"`
+	Project       *ProjectData `json:"project,omitempty"`
 }
 
-// FetchAllProjects fetches all projects
+// FetchAllProjects fetches a page of all projects,
+// selected with the limit and cursor query params
 func (p ProjectHandler) FetchAllProjects() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" && r.Method != "OPTIONS" {
 			return
 		}
 
-		projects, err := p.ProjectService.FetchAll()
+		page, err := toPage(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		projects, next, err := p.ProjectService.FetchAll(page)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Println(err)
+			writeError(w, err)
 			return
 		}
 
-		var projectsData []ProjectData
+		projectsData := []ProjectData{}
 		for _, p := range projects {
 			projectsData = append(projectsData, ToProjectData(p))
 		}
 
-		json.NewEncoder(w).Encode(projectsData)
+		json.NewEncoder(w).Encode(ToPageData(projectsData, next))
 	})
 }
 
-// DeleteProject deletes a project
+// DeleteProject deletes a project, which can only be done
+// by representatives of the company that listed the project
 func (p ProjectHandler) DeleteProject() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "DELETE" {
 			return
 		}
 
-		// todo: validate that the representative removing the project
-		// is an employee of the company that listed the project.
-		// Otherwise you can delete projects from other companies.
-
 		projectID := strings.TrimPrefix(r.URL.Path, p.Path+"delete/")
-		_, err := p.ProjectService.FindByID(projectID)
-
+		subject, err := p.AuthHandler.Subject(r)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
-		err = p.ProjectService.Delete(projectID)
+		err = p.ProjectService.Delete(subject, projectID)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -83,8 +95,54 @@ func (p ProjectHandler) DeleteProject() http.Handler {
 	})
 }
 
+// FetchMatches fetches the students that fit the project best, which can
+// only be done by representatives of the company owning the project.
+// The amount of students is set with the limit query param.
+// path = /projects/.../matches where the dots are a project ID
+func (p ProjectHandler) FetchMatches() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "OPTIONS" {
+			return
+		}
+
+		limit, err := toLimit(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		token, _ := p.AuthHandler.GetToken(cookie)
+		representativeID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		projectID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, p.Path), "/matches")
+		matches, err := p.MatchingService.MatchesForProject(projectID, representativeID, limit)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		matchesData := []MatchData{}
+		for _, match := range matches {
+			matchesData = append(matchesData, ToStudentMatchData(match))
+		}
+
+		json.NewEncoder(w).Encode(matchesData)
+	})
+}
+
 // Register registers all project related handlers
 func (p ProjectHandler) Register() {
-	http.Handle(p.Path, LoggingHandler(os.Stdout, p.AuthHandler.Validate("", p.FetchAllProjects())))
+	fetchAll := p.AuthHandler.Validate("", p.FetchAllProjects())
+	fetchMatches := p.AuthHandler.Validate(domain.RepresentativeRole, p.FetchMatches())
+
+	http.Handle(p.Path, LoggingHandler(os.Stdout, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/matches") {
+			fetchMatches.ServeHTTP(w, r)
+			return
+		}
+
+		fetchAll.ServeHTTP(w, r)
+	})))
 	http.Handle(p.Path+"delete/", LoggingHandler(os.Stdout, p.AuthHandler.Validate(domain.RepresentativeRole, p.DeleteProject())))
 }
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
@@ -20,20 +20,63 @@ import (
 type RepresentativeHandler struct {
 	RepresentativeService services.RepresentativeService
 	InviteLinkService     services.InviteLinkService
+	ShortlistService      services.ShortlistService
+	StudentService        services.StudentService
 	AuthHandler           AuthHandler
 	Path                  string
 }
 
+// InviteLinkData is a struct that corresponds to
+// incoming and outgoing invitelink data
+type InviteLinkData struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	TargetID   string    `json:"targetID"`
+	Role       string    `json:"role"`
+	URL        string    `json:"url"`
+	Email      string    `json:"email"`
+	Status     string    `json:"status"`
+	MaxUses    int       `json:"maxUses"`
+	Uses       int       `json:"uses"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiryDate time.Time `json:"expiryDate"`
+}
+
 // RepresentativeData is a struct that corresponds to incoming
 // representative data
 type RepresentativeData struct {
-	JobTitle  string   `json:"jobTitle"`
-	CompanyID string   `json:"companyID"`
-	UserData  UserData `json:"user"`
+	JobTitle    string   `json:"jobTitle"`
+	CompanyID   string   `json:"companyID"`
+	CompanyRole string   `json:"companyRole"`
+	UserData    UserData `json:"user"`
+}
+
+// CompanyRoleData is a struct that corresponds to incoming company role data
+type CompanyRoleData struct {
+	CompanyRole string `json:"companyRole"`
+}
+
+// RemovalData is a struct that corresponds to incoming removal data
+type RemovalData struct {
+	SuccessorID string `json:"successorID"`
+}
+
+// ShortlistEntryData is a struct that corresponds to incoming shortlist entry data
+type ShortlistEntryData struct {
+	ID        string    `json:"id"`
+	CompanyID string    `json:"companyID"`
+	StudentID string    `json:"studentID"`
+	ProjectID string    `json:"projectID"`
+	Notes     string    `json:"notes"`
+	Tags      []string  `json:"tags"`
+	AddedBy   string    `json:"addedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 // SignupRepresentative signs up a representative and binds
-// it to the companyID present in the invite-link URL
+// it to the companyID present in the invite-link URL. The email
+// has to be the one the invite-link has been sent to.
 // Format for invite-links: /signup/representatives/invite/[companyID]/[invitelinkID]
 func (r RepresentativeHandler) SignupRepresentative() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -41,36 +84,25 @@ func (r RepresentativeHandler) SignupRepresentative() http.Handler {
 			return
 		}
 
-		ids := strings.TrimPrefix(req.URL.Path, "/signup"+r.Path+"invite/")
-		companyID := strings.Split(ids, "/")[0]
-		inviteID := strings.Split(ids, "/")[1]
-
-		inviteLink, err := r.InviteLinkService.FindByID(inviteID)
-		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+		ids := strings.Split(strings.TrimPrefix(req.URL.Path, "/signup"+r.Path+"invite/"), "/")
+		if len(ids) != 2 {
+			writeError(w, e.ErrorEntityNotFound)
 			return
 		}
+		companyID, inviteID := ids[0], ids[1]
 
-		if inviteLink.HasExpired() {
-			// what to return if an invitelink has expired
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+		var data RepresentativeData
 
-		if inviteLink.HasBeenUsed() {
-			// what to return if an invitelink has been used already
-			w.WriteHeader(http.StatusBadRequest)
+		// check if json is invalid
+		err := decodeJSON(req, &data)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
-		var data RepresentativeData
-
-		// check if json is invalid
-		err = json.NewDecoder(req.Body).Decode(&data)
+		inviteLink, err := r.InviteLinkService.FindUsable(inviteID, domain.RepresentativeInvite, companyID, data.UserData.Email)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -79,15 +111,17 @@ func (r RepresentativeHandler) SignupRepresentative() http.Handler {
 			data.UserData.Password,
 			data.UserData.Firstname,
 			data.UserData.Lastname,
-			domain.RepresentativeRole,
+			inviteLink.Role,
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
+		// the invite-link has been mailed to this email, which verifies it
+		user.EmailVerified = true
+
 		representative, err := domain.NewRepresentative(
 			uuid.New().String(),
 			data.JobTitle,
@@ -96,37 +130,22 @@ func (r RepresentativeHandler) SignupRepresentative() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		err = r.RepresentativeService.Register(representative)
-		if err == e.ErrorEmailAlreadyUsed {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusConflict)
-			return
-		}
-
-		if err == e.ErrorEntityNotFound {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
+		// the invite-link is redeemed at the same time, it can
+		// have been used up by someone else in the meantime
+		err = r.RepresentativeService.Register(representative, inviteLink.ID)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
-		inviteLink.Used = true
-		err = r.InviteLinkService.Update(inviteLink)
+		// the account exists either way, the mail can be sent again later
+		err = r.AuthHandler.UserService.SendVerification(representative.User)
 		if err != nil {
-			fmt.Println(err)
-			// which status to return?
-			w.WriteHeader(http.StatusBadRequest)
-			return
+			logError(err)
 		}
 
 		json.NewEncoder(w).Encode(representative.ID)
@@ -143,8 +162,7 @@ func (r RepresentativeHandler) FetchRepresentativeByID() http.Handler {
 		id := strings.TrimPrefix(req.URL.Path, r.Path)
 		representative, err := r.RepresentativeService.FindByID(id)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
@@ -154,7 +172,8 @@ func (r RepresentativeHandler) FetchRepresentativeByID() http.Handler {
 	})
 }
 
-// FetchCreatedInvitations fetch all created invitations by the representative
+// FetchCreatedInvitations fetch all created invitations by the representative,
+// together with their status (pending, accepted, expired or revoked)
 func (r RepresentativeHandler) FetchCreatedInvitations() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != "GET" {
@@ -167,49 +186,114 @@ func (r RepresentativeHandler) FetchCreatedInvitations() http.Handler {
 
 		_, err := r.RepresentativeService.FindByID(representativeID)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
 		invitations, err := r.InviteLinkService.FindByCreator(representativeID)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
-		json.NewEncoder(w).Encode(invitations)
+		invitationsData := []InviteLinkData{}
+		for _, invitation := range invitations {
+			invitationsData = append(invitationsData, ToInviteLinkData(invitation))
+		}
+
+		json.NewEncoder(w).Encode(invitationsData)
 	})
 }
 
-// MakeInviteLink makes an invite link for the representative to sent
-// to colleagues.
+// MakeInviteLink makes an invite link for a colleague of the representative
+// and mails it to the provided email, only they can use it to sign up
 func (r RepresentativeHandler) MakeInviteLink() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		if req.Method != "GET" {
+		if req.Method != "POST" {
 			return
 		}
 
-		cookie, _ := req.Cookie("token")
-		token, _ := r.AuthHandler.GetToken(cookie)
-		representativeID := token.Claims.(jwt.MapClaims)["ID"].(string)
+		subject, err := r.AuthHandler.Subject(req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		repr, err := r.RepresentativeService.FindByID(subject.ID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var data InviteLinkData
+		err = decodeJSON(req, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		subject.CompanyID = repr.CompanyID
+		inviteLink, err := r.InviteLinkService.Create(subject, services.InviteRequest{
+			Kind:     domain.RepresentativeInvite,
+			TargetID: repr.CompanyID,
+			Email:    data.Email,
+		})
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(ToInviteLinkData(inviteLink))
+	})
+}
+
+// RevokeInvitation revokes an unused invitation of the representative
+// path = /representatives/invitations/revoke/... where the dots are an invitelink ID
+func (r RepresentativeHandler) RevokeInvitation() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			return
+		}
+
+		subject, err := r.AuthHandler.Subject(req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		inviteID := strings.TrimPrefix(req.URL.Path, r.Path+"invitations/revoke/")
+		err = r.InviteLinkService.Revoke(inviteID, subject.ID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	})
+}
 
-		repr, err := r.RepresentativeService.FindByID(representativeID)
+// ResendInvitation mails an unused invitation of the representative again,
+// making it valid for another 24 hours
+// path = /representatives/invitations/resend/... where the dots are an invitelink ID
+func (r RepresentativeHandler) ResendInvitation() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			return
+		}
+
+		subject, err := r.AuthHandler.Subject(req)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
-		inviteLink, err := r.InviteLinkService.CreateRepresentativeInvite(r.Path, repr)
+		inviteID := strings.TrimPrefix(req.URL.Path, r.Path+"invitations/resend/")
+		inviteLink, err := r.InviteLinkService.Resend(inviteID, subject)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
-		json.NewEncoder(w).Encode(inviteLink)
+		json.NewEncoder(w).Encode(ToInviteLinkData(inviteLink))
 	})
 }
 
@@ -225,22 +309,14 @@ func (r RepresentativeHandler) AddProject() http.Handler {
 		reprID := token.Claims.(jwt.MapClaims)["ID"].(string)
 
 		var data ProjectData
-		err := json.NewDecoder(req.Body).Decode(&data)
+		err := decodeJSON(req, &data)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
-		repr, err := r.RepresentativeService.FindByID(reprID)
-		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-
-		project, err := repr.CreateProject(
-			uuid.New().String(),
+		project, err := r.RepresentativeService.CompanyService.AddProject(
+			reprID,
 			data.Description,
 			data.Compensation,
 			data.Duration,
@@ -248,15 +324,7 @@ func (r RepresentativeHandler) AddProject() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		err = r.RepresentativeService.CompanyService.AddProject(project)
-		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -264,7 +332,8 @@ func (r RepresentativeHandler) AddProject() http.Handler {
 	})
 }
 
-// EditRepresentative edits the representative account with the new info
+// EditRepresentative edits the representative account with the new info,
+// which can only be done by the representative themself
 func (r RepresentativeHandler) EditRepresentative() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != "PUT" {
@@ -272,20 +341,24 @@ func (r RepresentativeHandler) EditRepresentative() http.Handler {
 		}
 
 		id := strings.TrimPrefix(req.URL.Path, r.Path+"edit/")
-		_, err := r.RepresentativeService.FindByID(id)
+		representative, err := r.RepresentativeService.FindByID(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.AuthHandler.Authorize(req, domain.EditRepresentativeProfile, domain.Resource{OwnerID: representative.ID})
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
 		var updatedRepresentativeData RepresentativeData
 
 		// check if json is invalid
-		err = json.NewDecoder(req.Body).Decode(&updatedRepresentativeData)
+		err = decodeJSON(req, &updatedRepresentativeData)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -298,28 +371,26 @@ func (r RepresentativeHandler) EditRepresentative() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
+		// representatives can't move themselves to another company
 		updatedRepresentative, err := domain.NewRepresentative(
 			id,
 			updatedRepresentativeData.JobTitle,
-			updatedRepresentativeData.CompanyID,
+			representative.CompanyID,
 			updatedUser,
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		err = r.RepresentativeService.Edit(updatedRepresentative)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -327,12 +398,295 @@ func (r RepresentativeHandler) EditRepresentative() http.Handler {
 	})
 }
 
+// ChangeCompanyRole changes the role of a representative within their company,
+// which can only be done by an admin of that company
+// path = /representatives/role/... where the dots are a representative ID
+func (r RepresentativeHandler) ChangeCompanyRole() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "PUT" {
+			return
+		}
+
+		id := strings.TrimPrefix(req.URL.Path, r.Path+"role/")
+		representative, err := r.RepresentativeService.FindByID(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.AuthHandler.Authorize(req, domain.ChangeCompanyRole, domain.Resource{CompanyID: representative.CompanyID})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var data CompanyRoleData
+		err = decodeJSON(req, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.RepresentativeService.ChangeCompanyRole(id, domain.CompanyRole(data.CompanyRole))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(representative.ID)
+	})
+}
+
+// RemoveRepresentative removes a representative from their company, which can only
+// be done by an admin of that company. Their projects and conversations are handed
+// over to the provided successor.
+// path = /representatives/remove/... where the dots are a representative ID
+func (r RepresentativeHandler) RemoveRepresentative() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			return
+		}
+
+		id := strings.TrimPrefix(req.URL.Path, r.Path+"remove/")
+		representative, err := r.RepresentativeService.FindByID(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.AuthHandler.Authorize(req, domain.RemoveRepresentative, domain.Resource{CompanyID: representative.CompanyID})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var data RemovalData
+		err = decodeJSON(req, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.RepresentativeService.Remove(id, data.SuccessorID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// TransferOwnership makes a colleague the owner of the company,
+// which can only be done by the current owner, who becomes an admin
+// path = /representatives/ownership/... where the dots are the ID of the new owner
+func (r RepresentativeHandler) TransferOwnership() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			return
+		}
+
+		subject, err := r.AuthHandler.Subject(req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		newOwnerID := strings.TrimPrefix(req.URL.Path, r.Path+"ownership/")
+		newOwner, err := r.RepresentativeService.FindByID(newOwnerID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.AuthHandler.Authorize(req, domain.TransferOwnership, domain.Resource{CompanyID: newOwner.CompanyID})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.RepresentativeService.TransferOwnership(subject.ID, newOwnerID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// RequestResume asks a student that only shares their resume on request
+// to share it with the company the logged in representative works for
+// path = /representatives/resume-requests/... where the dots are a student ID
+func (r RepresentativeHandler) RequestResume() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			return
+		}
+
+		subject, err := r.AuthHandler.Subject(req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		studentID := strings.TrimPrefix(req.URL.Path, r.Path+"resume-requests/")
+		err = r.StudentService.RequestResume(studentID, subject.ID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// FetchShortlist fetches the shortlist of the company
+// the logged in representative works for
+func (r RepresentativeHandler) FetchShortlist() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "GET" {
+			return
+		}
+
+		cookie, _ := req.Cookie("token")
+		token, _ := r.AuthHandler.GetToken(cookie)
+		reprID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		entries, err := r.ShortlistService.FindByRepresentative(reprID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		entriesData := []ShortlistEntryData{}
+		for _, entry := range entries {
+			entriesData = append(entriesData, ToShortlistEntryData(entry))
+		}
+
+		json.NewEncoder(w).Encode(entriesData)
+	})
+}
+
+// AddToShortlist adds a student to the shortlist of the
+// company the logged in representative works for
+func (r RepresentativeHandler) AddToShortlist() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			return
+		}
+
+		cookie, _ := req.Cookie("token")
+		token, _ := r.AuthHandler.GetToken(cookie)
+		reprID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		var data ShortlistEntryData
+		err := decodeJSON(req, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		repr, err := r.RepresentativeService.FindByID(reprID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		entry, err := repr.Shortlist(
+			uuid.New().String(),
+			data.StudentID,
+			data.ProjectID,
+			data.Notes,
+			data.Tags,
+		)
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = r.ShortlistService.Add(entry)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(entry.ID)
+	})
+}
+
+// EditShortlistEntry edits the notes, tags and project of a student
+// on the shortlist of the company the logged in representative works for.
+// path = /representatives/shortlist/edit/... where the dots are an entry ID
+func (r RepresentativeHandler) EditShortlistEntry() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "PUT" {
+			return
+		}
+
+		cookie, _ := req.Cookie("token")
+		token, _ := r.AuthHandler.GetToken(cookie)
+		reprID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		var data ShortlistEntryData
+		err := decodeJSON(req, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		entryID := strings.TrimPrefix(req.URL.Path, r.Path+"shortlist/edit/")
+		entry, err := r.ShortlistService.Edit(entryID, reprID, data.ProjectID, data.Notes, data.Tags)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(ToShortlistEntryData(entry))
+	})
+}
+
+// RemoveFromShortlist removes a student from the shortlist of the
+// company the logged in representative works for.
+// path = /representatives/shortlist/delete/... where the dots are an entry ID
+func (r RepresentativeHandler) RemoveFromShortlist() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "DELETE" {
+			return
+		}
+
+		cookie, _ := req.Cookie("token")
+		token, _ := r.AuthHandler.GetToken(cookie)
+		reprID := token.Claims.(jwt.MapClaims)["ID"].(string)
+
+		entryID := strings.TrimPrefix(req.URL.Path, r.Path+"shortlist/delete/")
+		err := r.ShortlistService.Remove(entryID, reprID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
 // Register registers all representative related handlers
 func (r RepresentativeHandler) Register() {
 	http.Handle(r.Path, LoggingHandler(os.Stdout, r.AuthHandler.Validate("", r.FetchRepresentativeByID())))
 	http.Handle("/signup"+r.Path+"invite/", LoggingHandler(os.Stdout, r.SignupRepresentative()))
 	http.Handle(r.Path+"invitelink/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.MakeInviteLink())))
 	http.Handle(r.Path+"invitations/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.FetchCreatedInvitations())))
+	http.Handle(r.Path+"invitations/revoke/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.RevokeInvitation())))
+	http.Handle(r.Path+"invitations/resend/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.ResendInvitation())))
 	http.Handle(r.Path+"projects/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.AddProject())))
 	http.Handle(r.Path+"edit/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.EditRepresentative())))
+	http.Handle(r.Path+"role/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.ChangeCompanyRole())))
+	http.Handle(r.Path+"remove/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.RemoveRepresentative())))
+	http.Handle(r.Path+"ownership/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.TransferOwnership())))
+	http.Handle(r.Path+"resume-requests/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.RequestResume())))
+	http.Handle(r.Path+"shortlist/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.FetchShortlist())))
+	http.Handle(r.Path+"shortlist/add", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.AddToShortlist())))
+	http.Handle(r.Path+"shortlist/edit/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.EditShortlistEntry())))
+	http.Handle(r.Path+"shortlist/delete/", LoggingHandler(os.Stdout, r.AuthHandler.Validate(domain.RepresentativeRole, r.RemoveFromShortlist())))
 }
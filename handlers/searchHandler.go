@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/janabe/cscoupler/domain"
+	"github.com/janabe/cscoupler/services"
+)
+
+// SearchHandler struct containing all
+// search related handler funcs
+type SearchHandler struct {
+	SearchService services.SearchService
+	AuthHandler   AuthHandler
+	Path          string
+}
+
+// SearchHitData is a struct that corresponds to outgoing search hit data
+type SearchHitData struct {
+	Kind      string  `json:"kind"`
+	ID        string  `json:"id"`
+	CompanyID string  `json:"companyID"`
+	Title     string  `json:"title"`
+	Snippet   string  `json:"snippet"`
+	Rank      float64 `json:"rank"`
+}
+
+// Search searches projects and companies on the keywords in the q query
+// param, returning at most limit hits. The snippets of the hits contain
+// the matched keywords wrapped in <b> tags.
+// path = /search?q=...&limit=...
+func (s SearchHandler) Search() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "OPTIONS" {
+			return
+		}
+
+		limit, err := toLimit(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		query, err := domain.NewSearchQuery(r.URL.Query().Get("q"), limit)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		hits, err := s.SearchService.Search(query)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		hitsData := []SearchHitData{}
+		for _, hit := range hits {
+			hitsData = append(hitsData, ToSearchHitData(hit))
+		}
+
+		json.NewEncoder(w).Encode(hitsData)
+	})
+}
+
+// Register registers all search related handlers
+func (s SearchHandler) Register() {
+	http.Handle(s.Path, LoggingHandler(os.Stdout, s.AuthHandler.Validate("", s.Search())))
+}
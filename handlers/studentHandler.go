@@ -1,14 +1,14 @@
 package handlers
 
 import (
+	"archive/zip"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/janabe/cscoupler/util"
 
@@ -21,24 +21,43 @@ import (
 // StudentHandler struct containing all
 // student related handler funcs
 type StudentHandler struct {
-	StudentService services.StudentService
-	AuthHandler    AuthHandler
-	Path           string
+	StudentService  services.StudentService
+	MatchingService services.MatchingService
+	DataService     services.StudentDataService
+	AuthHandler     AuthHandler
+	Path            string
 }
 
 // StudentData is a struct that corresponds to incoming student data
 type StudentData struct {
 	ID               string   `json:"id"`
 	University       string   `json:"university"`
+	City             string   `json:"city"`
 	Skills           []string `json:"skills"`
 	Experiences      []string `json:"experiences"`
 	ShortExperiences []string `json:"shortExperiences"`
 	Wishes           string   `json:"wishes"`
 	Status           string   `json:"status"`
 	Resume           string   `json:"resume"`
+	Visibility       string   `json:"visibility"`
+	ResumeOnRequest  bool     `json:"resumeOnRequest"`
 	UserData         UserData `json:"user"`
 }
 
+// PrivacyData is a struct that corresponds to incoming privacy settings of a student
+type PrivacyData struct {
+	Visibility      string `json:"visibility"`
+	ResumeOnRequest bool   `json:"resumeOnRequest"`
+}
+
+// ResumeRequestData is a struct that corresponds to outgoing resume requests
+type ResumeRequestData struct {
+	CompanyID   string    `json:"companyID"`
+	RequestedBy string    `json:"requestedBy"`
+	RequestedAt time.Time `json:"requestedAt"`
+	Granted     bool      `json:"granted"`
+}
+
 // SignupStudent signs up a new student
 func (s StudentHandler) SignupStudent() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -48,8 +67,7 @@ func (s StudentHandler) SignupStudent() http.Handler {
 
 		resumePath, err := processResume(r)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 		var data StudentData
@@ -57,8 +75,8 @@ func (s StudentHandler) SignupStudent() http.Handler {
 		// check if json is invalid
 		err = json.Unmarshal([]byte(r.FormValue("studentData")), &data)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			err = e.Wrap(err, e.Validation, "provided studentData isn't valid json")
+			writeError(w, err)
 			return
 		}
 
@@ -71,14 +89,14 @@ func (s StudentHandler) SignupStudent() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		student, err := domain.NewStudent(
 			uuid.New().String(),
 			data.University,
+			data.City,
 			data.Skills,
 			data.Experiences,
 			data.ShortExperiences,
@@ -89,29 +107,28 @@ func (s StudentHandler) SignupStudent() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		err = s.StudentService.Register(student)
-		if err == e.ErrorEmailAlreadyUsed {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusConflict)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
+		// the account exists either way, the mail can be sent again later
+		err = s.AuthHandler.UserService.SendVerification(student.User)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
+			logError(err)
 		}
 
 		json.NewEncoder(w).Encode(student.ID)
 	})
 }
 
-// EditStudent edits a student account
+// EditStudent edits a student account, which can
+// only be done by the student themself
 func (s StudentHandler) EditStudent() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "PUT" {
@@ -121,15 +138,19 @@ func (s StudentHandler) EditStudent() http.Handler {
 		studentID := strings.TrimPrefix(r.URL.Path, s.Path+"edit/")
 		student, err := s.StudentService.FindByID(studentID)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
+			return
+		}
+
+		err = s.AuthHandler.Authorize(r, domain.EditStudentProfile, domain.Resource{OwnerID: student.ID})
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
 		resumePath, err := processResume(r)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -138,8 +159,8 @@ func (s StudentHandler) EditStudent() http.Handler {
 		// check if json is invalid
 		err = json.Unmarshal([]byte(r.FormValue("studentData")), &updatedData)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			err = e.Wrap(err, e.Validation, "provided studentData isn't valid json")
+			writeError(w, err)
 			return
 		}
 
@@ -152,14 +173,14 @@ func (s StudentHandler) EditStudent() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		updatedStudent, err := domain.NewStudent(
 			studentID,
 			updatedData.University,
+			updatedData.City,
 			updatedData.Skills,
 			updatedData.Experiences,
 			updatedData.ShortExperiences,
@@ -170,31 +191,27 @@ func (s StudentHandler) EditStudent() http.Handler {
 		)
 
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
 		err = s.StudentService.Edit(updatedStudent)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusBadRequest) // what header to return
+			writeError(w, err)
 			return
 		}
 
-		// remove the old resume file of the student
-		err = os.Remove(student.Resume)
-		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+		// the student has been updated either way, so an
+		// old resume that can't be removed is only reported
+		removeResume(student.Resume)
 
 		json.NewEncoder(w).Encode(updatedStudent.ID)
 	})
 }
 
-// FetchStudentByID fetches a student based on ID
+// FetchStudentByID fetches a student based on ID, which can be done by
+// the student themself and by representatives the profile is visible to.
+// The resume is left out if the student doesn't share it with the company.
 // path = /students/... where the dots are a student ID
 func (s StudentHandler) FetchStudentByID() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -202,12 +219,16 @@ func (s StudentHandler) FetchStudentByID() http.Handler {
 			return
 		}
 
-		id := strings.TrimPrefix(r.URL.Path, s.Path)
-		student, err := s.StudentService.FindByID(id)
+		subject, err := s.AuthHandler.Subject(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
+		id := strings.TrimPrefix(r.URL.Path, s.Path)
+		student, err := s.StudentService.FindVisible(id, subject)
 		if err != nil {
-			fmt.Println(err)
-			w.WriteHeader(http.StatusNotFound)
+			writeError(w, err)
 			return
 		}
 
@@ -216,37 +237,323 @@ func (s StudentHandler) FetchStudentByID() http.Handler {
 	})
 }
 
-// FetchAllStudents fetches all the students
+// FetchAllStudents fetches a page of the students the representative may view, optionally filtered
+// on the query params skills, skillsMatch (any or all), university, city,
+// status (available or unavailable) and q (free text). The page is
+// selected with the limit and cursor query params.
 func (s StudentHandler) FetchAllStudents() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" && r.Method != "OPTIONS" {
 			return
 		}
 
-		students, err := s.StudentService.FindAll()
+		query, err := toStudentQuery(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		page, err := toPage(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		subject, err := s.AuthHandler.Subject(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			fmt.Println(err)
+			writeError(w, err)
 			return
 		}
 
-		var studentsData []StudentData
+		students, next, err := s.StudentService.Search(query, page, subject.ID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		studentsData := []StudentData{}
 		for _, s := range students {
 			studentsData = append(studentsData, ToStudentData(s))
 		}
 
-		json.NewEncoder(w).Encode(studentsData)
+		json.NewEncoder(w).Encode(ToPageData(studentsData, next))
+	})
+}
+
+// FetchRecommendedProjects fetches the projects the student fits best,
+// which can only be done by the student themself. The amount of projects is set with the limit query param.
+// path = /students/.../recommended-projects where the dots are the ID of the student
+func (s StudentHandler) FetchRecommendedProjects() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "OPTIONS" {
+			return
+		}
+
+		limit, err := toLimit(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		studentID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, s.Path), "/recommended-projects")
+		err = s.AuthHandler.Authorize(r, domain.ReadRecommendedProjects, domain.Resource{OwnerID: studentID})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		matches, err := s.MatchingService.RecommendedProjects(studentID, limit)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		matchesData := []MatchData{}
+		for _, match := range matches {
+			matchesData = append(matchesData, ToProjectMatchData(match))
+		}
+
+		json.NewEncoder(w).Encode(matchesData)
+	})
+}
+
+// ChangePrivacy changes which representatives may view the profile of the student,
+// and if their resume is only shared on request, which can only be done by the student themself
+// path = /students/privacy/... where the dots are a student ID
+func (s StudentHandler) ChangePrivacy() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" {
+			return
+		}
+
+		studentID := strings.TrimPrefix(r.URL.Path, s.Path+"privacy/")
+		err := s.AuthHandler.Authorize(r, domain.EditStudentProfile, domain.Resource{OwnerID: studentID})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var data PrivacyData
+		err = decodeJSON(r, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = s.StudentService.ChangePrivacy(studentID, domain.Visibility(data.Visibility), data.ResumeOnRequest)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// FetchResumeRequests fetches all companies that asked
+// for the resume of the logged in student
+// path = /students/resume-requests/
+func (s StudentHandler) FetchResumeRequests() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			return
+		}
+
+		subject, err := s.AuthHandler.Subject(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		requests, err := s.StudentService.FindResumeRequests(subject.ID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		requestsData := []ResumeRequestData{}
+		for _, request := range requests {
+			requestsData = append(requestsData, ToResumeRequestData(request))
+		}
+
+		json.NewEncoder(w).Encode(requestsData)
+	})
+}
+
+// AnswerResumeRequest grants or declines the request of a company for the
+// resume of the logged in student. Declining a granted request stops
+// sharing the resume with the company.
+// path = /students/resume-requests/grant/... or /students/resume-requests/decline/...
+// where the dots are a company ID
+func (s StudentHandler) AnswerResumeRequest() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			return
+		}
+
+		subject, err := s.AuthHandler.Subject(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, s.Path+"resume-requests/")
+		switch {
+		case strings.HasPrefix(path, "grant/"):
+			err = s.StudentService.GrantResume(subject.ID, strings.TrimPrefix(path, "grant/"))
+		case strings.HasPrefix(path, "decline/"):
+			err = s.StudentService.DeclineResume(subject.ID, strings.TrimPrefix(path, "decline/"))
+		default:
+			writeError(w, e.New(e.NotFound, "resume requests can only be granted or declined"))
+			return
+		}
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// ExportStudentData downloads all personal data stored about the student
+// as a zip file, which can only be done by the student themself
+// path = /students/export/... where the dots are a student ID
+func (s StudentHandler) ExportStudentData() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			return
+		}
+
+		studentID := strings.TrimPrefix(r.URL.Path, s.Path+"export/")
+		err := s.AuthHandler.Authorize(r, domain.ExportStudentData, domain.Resource{OwnerID: studentID})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		export, err := s.DataService.Export(studentID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="cscoupler-data.zip"`)
+		err = writeStudentExport(w, export)
+		if err != nil {
+			logError(err)
+			return
+		}
+	})
+}
+
+// DeleteStudent deletes the account of the student and all data tied to it,
+// which can only be done by the student themself, after entering their password
+// path = /students/delete/... where the dots are a student ID
+func (s StudentHandler) DeleteStudent() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			return
+		}
+
+		studentID := strings.TrimPrefix(r.URL.Path, s.Path+"delete/")
+		err := s.AuthHandler.Authorize(r, domain.DeleteStudentAccount, domain.Resource{OwnerID: studentID})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var data UserData
+		err = decodeJSON(r, &data)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resumes, err := s.DataService.Delete(studentID, data.Password)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// the account is gone either way, so resumes
+		// that can't be removed are only reported
+		for _, resume := range resumes {
+			removeResume(resume)
+		}
+
+		clearTokens(w)
+		w.WriteHeader(http.StatusOK)
 	})
 }
 
 // Register registers all student related handlers
 func (s StudentHandler) Register() {
-	http.Handle(s.Path, LoggingHandler(os.Stdout, s.AuthHandler.Validate("", s.FetchStudentByID())))
+	fetchByID := s.AuthHandler.Validate("", s.FetchStudentByID())
+	fetchRecommended := s.AuthHandler.Validate(domain.StudentRole, s.FetchRecommendedProjects())
+
+	http.Handle(s.Path, LoggingHandler(os.Stdout, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/recommended-projects") {
+			fetchRecommended.ServeHTTP(w, r)
+			return
+		}
+
+		fetchByID.ServeHTTP(w, r)
+	})))
 	http.Handle(s.Path+"edit/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.StudentRole, s.EditStudent())))
+	http.Handle(s.Path+"privacy/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.StudentRole, s.ChangePrivacy())))
+	http.Handle(s.Path+"resume-requests/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.StudentRole, s.FetchResumeRequests())))
+	http.Handle(s.Path+"resume-requests/grant/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.StudentRole, s.AnswerResumeRequest())))
+	http.Handle(s.Path+"resume-requests/decline/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.StudentRole, s.AnswerResumeRequest())))
+	http.Handle(s.Path+"export/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.StudentRole, s.ExportStudentData())))
+	http.Handle(s.Path+"delete/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.StudentRole, s.DeleteStudent())))
 	http.Handle("/signup/student", LoggingHandler(os.Stdout, s.SignupStudent()))
 	http.Handle(s.Path+"all/", LoggingHandler(os.Stdout, s.AuthHandler.Validate(domain.RepresentativeRole, s.FetchAllStudents())))
 }
 
+// helper func that reads the student filters from the query params
+// of the request. Skills can be comma separated and/or repeated.
+func toStudentQuery(r *http.Request) (domain.StudentQuery, error) {
+	params := r.URL.Query()
+	query := domain.StudentQuery{
+		University: strings.TrimSpace(params.Get("university")),
+		City:       strings.TrimSpace(params.Get("city")),
+		Text:       strings.TrimSpace(params.Get("q")),
+	}
+
+	for _, param := range params["skills"] {
+		for _, skill := range strings.Split(param, ",") {
+			if skill = strings.TrimSpace(skill); skill != "" {
+				query.Skills = append(query.Skills, skill)
+			}
+		}
+	}
+
+	switch strings.ToLower(params.Get("skillsMatch")) {
+	case "", "any":
+	case "all":
+		query.AllSkills = true
+	default:
+		return domain.StudentQuery{}, e.Invalid("skillsMatch", "should be any or all")
+	}
+
+	switch strings.ToLower(params.Get("status")) {
+	case "":
+	case "available":
+		status := domain.Available
+		query.Status = &status
+	case "unavailable":
+		status := domain.Unavailable
+		query.Status = &status
+	default:
+		return domain.StudentQuery{}, e.Invalid("status", "should be available or unavailable")
+	}
+
+	return query, nil
+}
+
 // todo: FIX, something goes wrong with saving the pdf file
 // Something something octet stream
 // Helper func to extract the uploaded resume file
@@ -257,25 +564,23 @@ func processResume(r *http.Request) (string, error) {
 	r.ParseMultipartForm(32 << 20)
 	file, handler, err := r.FormFile("resume")
 	if err != nil {
-		return "", err
+		return "", e.Wrap(err, e.Validation, "provided resume is missing")
 	}
 
 	isPdf := util.HasCorrectContentType(file, "application/pdf")
 	if !isPdf {
-		return "", errors.New("incorrect content type")
+		return "", e.Invalid("resume", "should be a pdf")
 	}
 
 	defer file.Close()
 
 	resumePath, err := filepath.Abs("./resumes/" + uuid.New().String() + "-" + handler.Filename)
 	if err != nil {
-		fmt.Println(err)
 		return "", err
 	}
 
 	dest, err := os.OpenFile(resumePath, os.O_WRONLY|os.O_CREATE, 0666)
 	if err != nil {
-		fmt.Println(err)
 		return "", err
 	}
 	defer dest.Close()
@@ -283,3 +588,91 @@ func processResume(r *http.Request) (string, error) {
 
 	return resumePath, nil
 }
+
+// Helper func that removes a stored resume file,
+// only reporting when it can't be removed
+func removeResume(resumePath string) {
+	err := os.Remove(resumePath)
+	if err != nil {
+		logError(err)
+	}
+}
+
+// Helper func that writes the export of a student as a zip file,
+// containing their data as json files together with their resumes
+func writeStudentExport(w io.Writer, export domain.StudentExport) error {
+	archive := zip.NewWriter(w)
+
+	conversationsData := []ConversationData{}
+	for _, conversation := range export.Conversations {
+		conversationsData = append(conversationsData, ToConversationData(conversation))
+	}
+
+	messagesData := []MessageData{}
+	for _, message := range export.Messages {
+		messagesData = append(messagesData, ToMessageData(message))
+	}
+
+	applicationsData := []ApplicationData{}
+	for _, application := range export.Applications {
+		applicationsData = append(applicationsData, ToApplicationData(application))
+	}
+
+	files := map[string]interface{}{
+		"student.json":       ToStudentData(export.Student),
+		"conversations.json": conversationsData,
+		"messages.json":      messagesData,
+		"applications.json":  applicationsData,
+	}
+
+	for name, data := range files {
+		file, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	resumes := map[string]string{}
+	if export.Student.Resume != "" {
+		resumes["resume"+filepath.Ext(export.Student.Resume)] = export.Student.Resume
+	}
+
+	for _, application := range export.Applications {
+		if application.Resume != "" {
+			resumes["applications/"+application.ID+filepath.Ext(application.Resume)] = application.Resume
+		}
+	}
+
+	for name, path := range resumes {
+		err := addFile(archive, name, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Helper func that copies the file at the provided path into the zip file
+func addFile(archive *zip.Writer, name, path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, source)
+	return err
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// FileMailer writes each mail to its own file in the provided directory
+// instead of sending it, so mails can be inspected during development
+type FileMailer struct {
+	Dir string
+}
+
+// Send writes the mail to a new file in the directory of the mailer.
+// The html version, if any, is written next to it so it can be opened
+// in a browser.
+func (f FileMailer) Send(mail domain.Mail) error {
+	name := fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), uuid.New().String())
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", mail.To, mail.Subject, mail.Body)
+	err := ioutil.WriteFile(filepath.Join(f.Dir, name+".txt"), []byte(content), 0600)
+	if err != nil || mail.HTML == "" {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(f.Dir, name+".html"), []byte(mail.HTML), 0600)
+}
@@ -0,0 +1,20 @@
+package mail
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// LogMailer writes mails to the provided writer instead of sending
+// them, which is useful during development, e.g. with os.Stdout
+type LogMailer struct {
+	Out io.Writer
+}
+
+// Send writes the mail to the writer of the mailer
+func (l LogMailer) Send(mail domain.Mail) error {
+	_, err := fmt.Fprintf(l.Out, "To: %s\nSubject: %s\n\n%s\n", mail.To, mail.Subject, mail.Body)
+	return err
+}
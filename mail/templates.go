@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// template struct containing the subject,
+// text and html templates of a mail event
+type template struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// templates contains the template of each mail event. The data of an
+// outbox mail is filled in, a key missing in the data is an error.
+var templates = map[domain.MailEvent]template{
+	domain.InviteMail: newTemplate(
+		`{{.Inviter}} invited you to join {{.Target}} on cscoupler`,
+		`Hi,
+
+{{.Inviter}} invited you to join {{.Target}} on cscoupler as {{.Role}}.
+Use the following link before {{.ExpiresAt}} to create your account:
+
+{{.Link}}
+
+If you don't know {{.Inviter}}, you can ignore this mail.`,
+		`<p>Hi,</p>
+<p>{{.Inviter}} invited you to join <strong>{{.Target}}</strong> on cscoupler as {{.Role}}.
+Use the following link before {{.ExpiresAt}} to create your account:</p>
+<p><a href="{{.Link}}">Join {{.Target}}</a></p>
+<p>If you don't know {{.Inviter}}, you can ignore this mail.</p>`,
+	),
+
+	domain.NewMessageMail: newTemplate(
+		`New message from {{.Sender}}`,
+		`Hi {{.Receiver}},
+
+{{.Sender}} sent you a message on cscoupler:
+
+{{.Preview}}
+
+Read and reply to it here: {{.Link}}`,
+		`<p>Hi {{.Receiver}},</p>
+<p>{{.Sender}} sent you a message on cscoupler:</p>
+<blockquote>{{.Preview}}</blockquote>
+<p><a href="{{.Link}}">Read and reply</a></p>`,
+	),
+
+	domain.ApplicationStatusMail: newTemplate(
+		`Your application to {{.Company}} is {{.Status}}`,
+		`Hi {{.Student}},
+
+The status of your application to {{.Company}} for the project
+"{{.Project}}" has changed to: {{.Status}}.
+
+See your applications here: {{.Link}}`,
+		`<p>Hi {{.Student}},</p>
+<p>The status of your application to <strong>{{.Company}}</strong> for the project
+"{{.Project}}" has changed to: {{.Status}}.</p>
+<p><a href="{{.Link}}">See your applications</a></p>`,
+	),
+}
+
+// Render creates the mail to send from the outbox mail,
+// by filling in its data in the templates of its event
+func Render(outboxMail domain.OutboxMail) (domain.Mail, error) {
+	t, ok := templates[outboxMail.Event]
+	if !ok {
+		return domain.Mail{}, errors.New("no template for mail event: " + string(outboxMail.Event))
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, outboxMail.Data); err != nil {
+		return domain.Mail{}, err
+	}
+
+	if err := t.text.Execute(&text, outboxMail.Data); err != nil {
+		return domain.Mail{}, err
+	}
+
+	if err := t.html.Execute(&html, outboxMail.Data); err != nil {
+		return domain.Mail{}, err
+	}
+
+	return domain.Mail{
+		To:      outboxMail.To,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// helper func that parses the templates of a mail event,
+// panicking if they are invalid as they are fixed at compile time
+func newTemplate(subject, text, html string) template {
+	return template{
+		subject: texttemplate.Must(texttemplate.New("subject").Option("missingkey=error").Parse(subject)),
+		text:    texttemplate.Must(texttemplate.New("text").Option("missingkey=error").Parse(text)),
+		html:    htmltemplate.Must(htmltemplate.New("html").Option("missingkey=error").Parse(html)),
+	}
+}
@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// default settings of the worker, used when they aren't set
+const (
+	defaultInterval  = 10 * time.Second
+	defaultBatchSize = 20
+	defaultLease     = 5 * time.Minute
+)
+
+// Worker sends the mails in the outbox in the background.
+// Mails that fail to be sent are tried again later,
+// waiting longer after each failed attempt.
+type Worker struct {
+	Outbox    domain.OutboxRepository
+	Mailer    domain.Mailer
+	Interval  time.Duration // how long to wait between checking the outbox
+	BatchSize int           // how many mails to send at most per check
+	Lease     time.Duration // how long a claimed mail is skipped by other workers
+}
+
+// Run sends the mails in the outbox until stop is closed.
+// It should be run in its own goroutine.
+func (w Worker) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.interval())
+	defer ticker.Stop()
+
+	for {
+		_, err := w.SendDue()
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends the mails in the outbox that are due, returning
+// how many have been sent. Mails that fail are marked for retry,
+// only an error with the outbox itself is returned.
+func (w Worker) SendDue() (int, error) {
+	batchSize := w.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	lease := w.Lease
+	if lease <= 0 {
+		lease = defaultLease
+	}
+
+	mails, err := w.Outbox.ClaimDue(time.Now(), lease, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, outboxMail := range mails {
+		err = w.send(outboxMail)
+		if err != nil {
+			outboxMail.Fail(err, time.Now())
+			fmt.Printf("sending mail %s failed (attempt %d): %v\n", outboxMail.ID, outboxMail.Attempts, err)
+
+			err = w.Outbox.MarkFailed(outboxMail)
+			if err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		err = w.Outbox.MarkSent(outboxMail.ID, time.Now())
+		if err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// helper func that renders and sends a single mail
+func (w Worker) send(outboxMail domain.OutboxMail) error {
+	mail, err := Render(outboxMail)
+	if err != nil {
+		return err
+	}
+
+	return w.Mailer.Send(mail)
+}
+
+// helper func that returns how long to wait between checks
+func (w Worker) interval() time.Duration {
+	if w.Interval <= 0 {
+		return defaultInterval
+	}
+
+	return w.Interval
+}
@@ -1,30 +1,22 @@
 package main
 
 import (
-	"database/sql"
-
-	"github.com/janabe/cscoupler/util"
-
-	_ "github.com/lib/pq"
+	"fmt"
+	"os"
 
 	"github.com/janabe/cscoupler/server"
 )
 
 func main() {
-	db := ConnectToDB()
-	defer db.Close()
-	server := server.NewServer(db)
-	server.Run()
-}
-
-// ConnectToDB connects to the database
-// and returns
-func ConnectToDB() *sql.DB {
-	dsn := util.GetDSN("./.secret.json")
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		panic(err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := server.Migrate(os.Args[2:], os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	return db
+	server := server.NewServer()
+	server.Run()
 }
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/janabe/cscoupler/database/postgres/migrations"
+	"github.com/janabe/cscoupler/util"
+)
+
+// MigrateUsage explains the arguments of the migrate command
+const MigrateUsage = `usage: main migrate <command>
+
+commands:
+  up             apply all migrations that haven't been applied yet
+  down           undo the latest applied migration
+  status         list the migrations and when they have been applied
+  to <version>   apply or undo migrations until version is the latest applied one`
+
+// Migrate runs the migrate command with the provided arguments against the
+// database set in .secret.json, writing what has been done to out
+func Migrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(MigrateUsage)
+	}
+
+	db := connectToDB(util.GetDSN("./.secret.json"))
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = migrator.Up()
+	case args[0] == "down" && len(args) == 1:
+		err = migrator.Down()
+	case args[0] == "to" && len(args) == 2:
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return errors.New("version should be a number: " + args[1])
+		}
+		err = migrator.To(version)
+	case args[0] == "status" && len(args) == 1:
+		return printMigrationStatus(migrator, out)
+	default:
+		return errors.New(MigrateUsage)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "database is at version %d\n", version)
+	return nil
+}
+
+// migrate applies all migrations the database doesn't have yet. Instances
+// starting at the same time wait for each other, so only one migrates.
+func (s *Server) migrate() {
+	migrator, err := migrations.NewMigrator(s.db)
+	if err != nil {
+		panic(err)
+	}
+
+	err = migrator.Up()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// helper func that writes the status of all migrations to out
+func printMigrationStatus(migrator migrations.Migrator, out io.Writer) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.IsApplied() {
+			appliedAt = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(out, "%03d %-30s %s\n", status.Migration.Version, status.Migration.Name, appliedAt)
+	}
+
+	return nil
+}
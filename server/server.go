@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
+	_ "github.com/lib/pq"
 	"github.com/rs/cors"
 
+	"github.com/janabe/cscoupler/database/memory"
 	pg "github.com/janabe/cscoupler/database/postgres"
 	d "github.com/janabe/cscoupler/domain"
 	"github.com/janabe/cscoupler/handlers"
+	"github.com/janabe/cscoupler/mail"
 	ser "github.com/janabe/cscoupler/services"
 	"github.com/janabe/cscoupler/util"
 )
@@ -23,9 +28,17 @@ type Server struct {
 	userService           ser.UserService
 	companyService        ser.CompanyService
 	studentService        ser.StudentService
+	studentDataService    ser.StudentDataService
 	projectService        ser.ProjectService
 	inviteLinkService     ser.InviteLinkService
 	representativeService ser.RepresentativeService
+	messageService        ser.MessageService
+	applicationService    ser.ApplicationService
+	shortlistService      ser.ShortlistService
+	searchService         ser.SearchService
+	matchingService       ser.MatchingService
+	policyService         ser.PolicyService
+	sessionService        ser.SessionService
 
 	userRepo           d.UserRepository
 	studentRepo        d.StudentRepository
@@ -33,20 +46,44 @@ type Server struct {
 	projectRepo        d.ProjectRepository
 	inviteLinkRepo     d.InviteLinkRepository
 	representativeRepo d.RepresentativeRepository
+	messageRepo        d.MessageRepository
+	conversationRepo   d.ConversationRepository
+	blockRepo          d.BlockRepository
+	applicationRepo    d.ApplicationRepository
+	shortlistRepo      d.ShortlistRepository
+	resumeRequestRepo  d.ResumeRequestRepository
+	searchRepo         d.SearchRepository
+	sessionRepo        d.SessionRepository
+	userTokenRepo      d.UserTokenRepository
+	outboxRepo         d.OutboxRepository
+	unitOfWork         d.UnitOfWork
+
+	mailer       d.Mailer
+	outboxWorker mail.Worker
 }
 
-// NewServer creates a new server which can be run
-// to start the app
-func NewServer(db *sql.DB) *Server {
-	server := Server{db: db}
-	server.initRepos()
+// NewServer creates a new server which can be run to start the app. Its data is
+// stored in postgres or in memory, depending on the storage set in .secret.json.
+// A postgres database is migrated to the latest version first.
+func NewServer() *Server {
+	server := Server{}
+	if util.GetStorage("./.secret.json") == util.MemoryStorage {
+		server.initMemoryRepos()
+	} else {
+		server.db = connectToDB(util.GetDSN("./.secret.json"))
+		server.migrate()
+		server.initPostgresRepos()
+	}
+
 	server.initServices()
 	server.initHandlers()
 	return &server
 }
 
-// Run runs the server
+// Run runs the server, together with the worker sending queued mails
 func (s *Server) Run() {
+	go s.outboxWorker.Run(nil)
+
 	fmt.Println("Running server, listening on port 3000...")
 	// log.Fatal(http.ListenAndServeTLS(":3000", "./server/cert.pem", "./server/key.pem", nil))
 	mux := http.DefaultServeMux
@@ -59,40 +96,149 @@ func (s *Server) Run() {
 	log.Fatal(http.ListenAndServe(":3000", h))
 }
 
-func (s *Server) initRepos() {
-	s.userRepo = pg.UserRepo{DB: s.db}
-	s.inviteLinkRepo = pg.InviteLinkRepo{DB: s.db}
-	s.studentRepo = pg.StudentRepo{DB: s.db, UserRepo: s.userRepo.(pg.UserRepo)}
-	s.representativeRepo = pg.RepresentativeRepo{DB: s.db, UserRepo: s.userRepo.(pg.UserRepo)}
-	s.companyRepo = pg.CompanyRepo{DB: s.db, ReprRepo: s.representativeRepo.(pg.RepresentativeRepo)}
-	s.projectRepo = pg.ProjectRepo{DB: s.db}
+func (s *Server) initPostgresRepos() {
+	s.initRepos(pg.NewRepositories(s.db), pg.UnitOfWork{DB: s.db})
+}
+
+// initMemoryRepos stores all data in memory, so the app runs without postgres.
+// All data is lost when the server stops.
+func (s *Server) initMemoryRepos() {
+	store := memory.NewStore()
+	s.initRepos(memory.NewRepositories(store), memory.UnitOfWork{Store: store})
+}
+
+// helper func that sets the repositories, and the unit of work
+// spanning them, of the storage backend the services use
+func (s *Server) initRepos(repos d.Repositories, unitOfWork d.UnitOfWork) {
+	s.userRepo = repos.Users
+	s.studentRepo = repos.Students
+	s.companyRepo = repos.Companies
+	s.projectRepo = repos.Projects
+	s.inviteLinkRepo = repos.InviteLinks
+	s.representativeRepo = repos.Representatives
+	s.messageRepo = repos.Messages
+	s.conversationRepo = repos.Conversations
+	s.blockRepo = repos.Blocks
+	s.applicationRepo = repos.Applications
+	s.shortlistRepo = repos.Shortlist
+	s.resumeRequestRepo = repos.ResumeRequests
+	s.searchRepo = repos.Search
+	s.sessionRepo = repos.Sessions
+	s.userTokenRepo = repos.UserTokens
+	s.outboxRepo = repos.Outbox
+	s.unitOfWork = unitOfWork
 }
 
 func (s *Server) initServices() {
-	s.userService = ser.UserService{UserRepo: s.userRepo}
-	s.companyService = ser.CompanyService{CompanyRepo: s.companyRepo}
-	s.inviteLinkService = ser.InviteLinkService{InviteLinkRepo: s.inviteLinkRepo}
-	s.studentService = ser.StudentService{StudentRepo: s.studentRepo}
+	clientURL := util.GetClientURL("./.secret.json")
+	s.mailer = newMailer(util.GetMailConfig("./.secret.json"))
+	s.outboxWorker = mail.Worker{Outbox: s.outboxRepo, Mailer: s.mailer}
+
+	s.sessionService = ser.SessionService{SessionRepo: s.sessionRepo, RefreshTTL: ser.DefaultRefreshTTL}
+	s.userService = ser.UserService{
+		UserRepo:   s.userRepo,
+		TokenRepo:  s.userTokenRepo,
+		Mailer:     s.mailer,
+		UnitOfWork: s.unitOfWork,
+		ClientURL:  clientURL,
+	}
+	s.companyService = ser.CompanyService{CompanyRepo: s.companyRepo, UnitOfWork: s.unitOfWork}
 	s.representativeService = ser.RepresentativeService{
 		RepresentativeRepo: s.representativeRepo,
 		CompanyService:     s.companyService,
 		UserService:        s.userService,
+		UnitOfWork:         s.unitOfWork,
 	}
 
 	s.companyService.ReprService = &s.representativeService
-	s.projectService = ser.ProjectService{ProjectRepo: s.projectRepo}
+	s.policyService = ser.PolicyService{RepresentativeService: s.representativeService}
+	s.studentService = ser.StudentService{
+		StudentRepo:       s.studentRepo,
+		ApplicationRepo:   s.applicationRepo,
+		ProjectRepo:       s.projectRepo,
+		ResumeRequestRepo: s.resumeRequestRepo,
+		PolicyService:     s.policyService,
+	}
+	s.inviteLinkService = ser.InviteLinkService{
+		InviteLinkRepo: s.inviteLinkRepo,
+		UserService:    s.userService,
+		ClientURL:      clientURL,
+	}
+	s.inviteLinkService.RegisterKind(ser.RepresentativeInvitations{
+		CompanyService: s.companyService,
+		PolicyService:  s.policyService,
+	})
+	s.projectService = ser.ProjectService{
+		ProjectRepo:   s.projectRepo,
+		PolicyService: s.policyService,
+		UnitOfWork:    s.unitOfWork,
+	}
+
+	messagingConfig := util.GetMessagingConfig("./.secret.json")
+	s.messageService = ser.MessageService{
+		MessageRepo:           s.messageRepo,
+		ConversationRepo:      s.conversationRepo,
+		BlockRepo:             s.blockRepo,
+		UserService:           s.userService,
+		ProjectService:        s.projectService,
+		RepresentativeService: s.representativeService,
+		UnitOfWork:            s.unitOfWork,
+		Policy: ser.MessagePolicy{
+			MaxConversationsPerRepresentative: messagingConfig.MaxConversationsPerRepresentative,
+			MaxConversationsPerCompany:        messagingConfig.MaxConversationsPerCompany,
+			Window:                            time.Duration(messagingConfig.WindowHours) * time.Hour,
+		},
+		ClientURL: clientURL,
+	}
+
+	s.applicationService = ser.ApplicationService{
+		ApplicationRepo: s.applicationRepo,
+		ProjectService:  s.projectService,
+		StudentService:  s.studentService,
+		CompanyService:  s.companyService,
+		PolicyService:   s.policyService,
+		UnitOfWork:      s.unitOfWork,
+		ClientURL:       clientURL,
+	}
+
+	s.shortlistService = ser.ShortlistService{
+		ShortlistRepo:         s.shortlistRepo,
+		StudentService:        s.studentService,
+		ProjectService:        s.projectService,
+		RepresentativeService: s.representativeService,
+		PolicyService:         s.policyService,
+	}
+
+	s.studentDataService = ser.StudentDataService{
+		StudentRepo:      s.studentRepo,
+		ConversationRepo: s.conversationRepo,
+		MessageRepo:      s.messageRepo,
+		ApplicationRepo:  s.applicationRepo,
+		UserService:      s.userService,
+	}
+
+	s.searchService = ser.SearchService{SearchRepo: s.searchRepo}
+	s.matchingService = ser.MatchingService{
+		StudentRepo:   s.studentRepo,
+		ProjectRepo:   s.projectRepo,
+		PolicyService: s.policyService,
+	}
 }
 
 func (s *Server) initHandlers() {
 	authHandler := handlers.AuthHandler{
-		JWTKey:      util.GetJWTSecret("./.secret.json"),
-		UserService: s.userService,
+		JWTKey:         util.GetJWTSecret("./.secret.json"),
+		UserService:    s.userService,
+		PolicyService:  s.policyService,
+		SessionService: s.sessionService,
 	}
 
 	studentHandler := handlers.StudentHandler{
-		StudentService: s.studentService,
-		AuthHandler:    authHandler,
-		Path:           "/students/",
+		StudentService:  s.studentService,
+		MatchingService: s.matchingService,
+		DataService:     s.studentDataService,
+		AuthHandler:     authHandler,
+		Path:            "/students/",
 	}
 
 	companyHandler := handlers.CompanyHandler{
@@ -104,14 +250,35 @@ func (s *Server) initHandlers() {
 	representativeHandler := handlers.RepresentativeHandler{
 		RepresentativeService: s.representativeService,
 		InviteLinkService:     s.inviteLinkService,
+		ShortlistService:      s.shortlistService,
+		StudentService:        s.studentService,
 		AuthHandler:           authHandler,
 		Path:                  "/representatives/",
 	}
 
 	projectHandler := handlers.ProjectHandler{
-		ProjectService: s.projectService,
+		ProjectService:  s.projectService,
+		MatchingService: s.matchingService,
+		AuthHandler:     authHandler,
+		Path:            "/projects/",
+	}
+
+	messageHandler := handlers.MessageHandler{
+		MessageService: s.messageService,
 		AuthHandler:    authHandler,
-		Path:           "/projects/",
+		Path:           "/messages/",
+	}
+
+	applicationHandler := handlers.ApplicationHandler{
+		ApplicationService: s.applicationService,
+		AuthHandler:        authHandler,
+		Path:               "/applications/",
+	}
+
+	searchHandler := handlers.SearchHandler{
+		SearchService: s.searchService,
+		AuthHandler:   authHandler,
+		Path:          "/search",
 	}
 
 	authHandler.Register()
@@ -119,4 +286,38 @@ func (s *Server) initHandlers() {
 	companyHandler.Register()
 	representativeHandler.Register()
 	projectHandler.Register()
+	messageHandler.Register()
+	applicationHandler.Register()
+	searchHandler.Register()
+}
+
+// connectToDB connects to the postgres database with the provided data source name
+func connectToDB(dsn string) *sql.DB {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+// newMailer creates the mailer sending mails through the configured
+// SMTP server, or writing them to files in the configured directory.
+// Mails are printed to the terminal if neither is set.
+func newMailer(config util.MailConfig) d.Mailer {
+	if config.SMTP.Host != "" {
+		return mail.SMTPMailer{
+			Host:     config.SMTP.Host,
+			Port:     config.SMTP.Port,
+			Username: config.SMTP.Username,
+			Password: config.SMTP.Password,
+			From:     config.SMTP.From,
+		}
+	}
+
+	if config.Dir != "" {
+		return mail.FileMailer{Dir: config.Dir}
+	}
+
+	return mail.LogMailer{Out: os.Stdout}
 }
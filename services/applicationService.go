@@ -0,0 +1,186 @@
+package services
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// statuses a student can move their own application to,
+// all other statuses are set by the representatives
+// of the company owning the project
+var studentStatuses = map[domain.ApplicationStatus]bool{
+	domain.ApplicationAccepted:  true,
+	domain.ApplicationWithdrawn: true,
+}
+
+// ApplicationService struct, containing all features
+// the app supports regarding applications to projects
+type ApplicationService struct {
+	ApplicationRepo domain.ApplicationRepository
+	ProjectService  ProjectService
+	StudentService  StudentService
+	CompanyService  CompanyService
+	PolicyService   PolicyService
+	UnitOfWork      domain.UnitOfWork
+	ClientURL       string // base url of the client, used in links sent by mail
+}
+
+// Apply submits the application of a student to a project.
+// A student can only apply once to the same project. The resume
+// sent along is stored by the caller, who has to remove it again
+// when the application can't be submitted.
+func (a ApplicationService) Apply(application domain.Application) error {
+	return a.UnitOfWork.Do(func(repos domain.Repositories) error {
+		_, err := repos.Projects.FindByID(application.ProjectID)
+		if err != nil {
+			return err
+		}
+
+		_, err = repos.Applications.FindByStudentAndProject(application.StudentID, application.ProjectID)
+		if err == nil {
+			return e.ErrorAlreadyApplied
+		}
+
+		if !e.Is(err, e.ErrorEntityNotFound) {
+			return err
+		}
+
+		return repos.Applications.Create(application)
+	})
+}
+
+// MoveByStudent moves the application of the student to
+// the provided status, which has to be accepted or withdrawn
+func (a ApplicationService) MoveByStudent(applicationID, studentID string, status domain.ApplicationStatus) (domain.Application, error) {
+	application, err := a.ApplicationRepo.FindByID(applicationID)
+	if err != nil {
+		return domain.Application{}, e.ErrorEntityNotFound
+	}
+
+	if application.StudentID != studentID || !studentStatuses[status] {
+		return domain.Application{}, e.ErrorForbidden
+	}
+
+	return a.move(application, status, false)
+}
+
+// MoveByRepresentative moves the application to the provided status.
+// The representative has to work for the company owning the project
+// the application is for. The student is notified by mail.
+func (a ApplicationService) MoveByRepresentative(applicationID, representativeID string, status domain.ApplicationStatus) (domain.Application, error) {
+	application, err := a.ApplicationRepo.FindByID(applicationID)
+	if err != nil {
+		return domain.Application{}, e.ErrorEntityNotFound
+	}
+
+	if studentStatuses[status] || !a.worksOnProject(representativeID, application.ProjectID, domain.ManageApplications) {
+		return domain.Application{}, e.ErrorForbidden
+	}
+
+	return a.move(application, status, true)
+}
+
+// FindByStudent finds all applications of the student
+func (a ApplicationService) FindByStudent(studentID string) ([]domain.Application, error) {
+	applications, err := a.ApplicationRepo.FindByStudent(studentID)
+	if err != nil {
+		return []domain.Application{}, err
+	}
+
+	return applications, nil
+}
+
+// FindByProject finds all applications to the project. The representative
+// has to work for the company owning the project.
+func (a ApplicationService) FindByProject(projectID, representativeID string) ([]domain.Application, error) {
+	if !a.ProjectService.Exists(projectID) {
+		return []domain.Application{}, e.ErrorEntityNotFound
+	}
+
+	if !a.worksOnProject(representativeID, projectID, domain.ReadApplications) {
+		return []domain.Application{}, e.ErrorForbidden
+	}
+
+	applications, err := a.ApplicationRepo.FindByProject(projectID)
+	if err != nil {
+		return []domain.Application{}, err
+	}
+
+	return applications, nil
+}
+
+// helper func that moves the application to the provided status if
+// this transition is allowed, and stores it. When notify is set, the
+// mail notifying the student is queued together with the change.
+func (a ApplicationService) move(application domain.Application, status domain.ApplicationStatus, notify bool) (domain.Application, error) {
+	if !application.CanMoveTo(status) {
+		return domain.Application{}, e.ErrorInvalidTransition
+	}
+
+	err := application.MoveTo(status)
+	if err != nil {
+		return domain.Application{}, err
+	}
+
+	if !notify {
+		err = a.ApplicationRepo.Update(application)
+		if err != nil {
+			return domain.Application{}, err
+		}
+
+		return application, nil
+	}
+
+	mail, err := a.statusMail(application)
+	if err != nil {
+		return domain.Application{}, err
+	}
+
+	err = a.ApplicationRepo.UpdateWithMail(application, mail)
+	if err != nil {
+		return domain.Application{}, err
+	}
+
+	return application, nil
+}
+
+// helper func that creates the mail notifying the
+// student of the new status of their application
+func (a ApplicationService) statusMail(application domain.Application) (domain.OutboxMail, error) {
+	student, err := a.StudentService.FindByID(application.StudentID)
+	if err != nil {
+		return domain.OutboxMail{}, err
+	}
+
+	project, err := a.ProjectService.FindByID(application.ProjectID)
+	if err != nil {
+		return domain.OutboxMail{}, err
+	}
+
+	company, err := a.CompanyService.FindByID(project.CompanyID)
+	if err != nil {
+		return domain.OutboxMail{}, err
+	}
+
+	return domain.NewOutboxMail(uuid.New().String(), domain.ApplicationStatusMail, student.User.Email, map[string]string{
+		"Student": student.User.FirstName,
+		"Company": company.Name,
+		"Project": summarize(project.Description, 60),
+		"Status":  string(application.Status),
+		"Link":    a.ClientURL + "/applications",
+	})
+}
+
+// helper func that checks if the representative may perform
+// the action on the applications to the project
+func (a ApplicationService) worksOnProject(representativeID, projectID string, action domain.Action) bool {
+	project, err := a.ProjectService.FindByID(projectID)
+	if err != nil {
+		return false
+	}
+
+	resource := domain.Resource{CompanyID: project.CompanyID}
+	return a.PolicyService.AuthorizeRepresentative(representativeID, action, resource) == nil
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"github.com/google/uuid"
 	"github.com/janabe/cscoupler/domain"
 	e "github.com/janabe/cscoupler/errors"
 )
@@ -10,25 +11,27 @@ import (
 type CompanyService struct {
 	CompanyRepo domain.CompanyRepository
 	ReprService *RepresentativeService
+	UnitOfWork  domain.UnitOfWork
 }
 
-// Register registers a new company and their main representative
+// Register registers a new company and their main
+// representative, who becomes the owner of the company
 func (c CompanyService) Register(company domain.Company) error {
-	if c.NameAlreadyUsed(company.Name) {
-		return e.ErrorCompanyNameAlreadyUsed
-	}
+	company.Representatives[0].CompanyRole = domain.CompanyOwner
 
-	if c.ReprService.UserService.EmailAlreadyUsed(
-		company.Representatives[0].User.Email) {
-		return e.ErrorEmailAlreadyUsed
-	}
+	return c.UnitOfWork.Do(func(repos domain.Repositories) error {
+		_, err := repos.Companies.FindByName(company.Name)
+		if err == nil {
+			return e.ErrorCompanyNameAlreadyUsed
+		}
 
-	err := c.CompanyRepo.Create(company)
-	if err != nil {
-		return err
-	}
+		_, err = repos.Users.FindByEmail(company.Representatives[0].User.Email)
+		if err == nil {
+			return e.ErrorEmailAlreadyUsed
+		}
 
-	return nil
+		return repos.Companies.Create(company)
+	})
 }
 
 // FindByID finds a company based on ID
@@ -71,22 +74,39 @@ func (c CompanyService) NameAlreadyUsed(name string) bool {
 	return true
 }
 
-// AddProject adds a project to the company
-func (c CompanyService) AddProject(p domain.Project) error {
-	err := c.CompanyRepo.AddProject(p)
+// AddProject adds a new project to the company of the representative,
+// who becomes the contact of the project. Representatives that have
+// been removed from their company can't add projects to it anymore.
+func (c CompanyService) AddProject(representativeID, desc, comp, dur string, recs []string) (domain.Project, error) {
+	var project domain.Project
+	err := c.UnitOfWork.Do(func(repos domain.Repositories) error {
+		repr, err := repos.Representatives.FindByID(representativeID)
+		if err != nil || repr.IsRemoved() {
+			return e.ErrorEntityNotFound
+		}
+
+		project, err = repr.CreateProject(uuid.New().String(), desc, comp, dur, recs)
+		if err != nil {
+			return err
+		}
+
+		return repos.Companies.AddProject(project)
+	})
+
 	if err != nil {
-		return err
+		return domain.Project{}, err
 	}
 
-	return nil
+	return project, nil
 }
 
-// FindAll finds all companies present
-func (c CompanyService) FindAll() ([]domain.Company, error) {
-	companies, err := c.CompanyRepo.FindAll()
+// FindAll finds a page of all companies present, along
+// with the id to continue after for the next page
+func (c CompanyService) FindAll(page domain.Page) ([]domain.Company, string, error) {
+	companies, next, err := c.CompanyRepo.FindAll(page)
 	if err != nil {
-		return []domain.Company{}, err
+		return []domain.Company{}, "", err
 	}
 
-	return companies, nil
+	return companies, next, nil
 }
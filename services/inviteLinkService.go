@@ -1,35 +1,204 @@
 package services
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	d "github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
+// InvitationKind contains everything that differs between the kinds of
+// invitations, such as inviting representatives to a company. Supporting
+// a new kind, e.g. university staff or students joining a team, only
+// takes registering an InvitationKind with the InviteLinkService.
+type InvitationKind interface {
+	// Kind returns the kind of invitations this handles
+	Kind() d.InviteKind
+
+	// Role returns the role invitees get when signing up
+	Role() string
+
+	// SignupPath returns the path invitees sign up on, which is followed
+	// by the target and invitation id, e.g. /signup/representatives/invite/
+	SignupPath() string
+
+	// AllowsOpenInvites tells if invitations may be created without an
+	// email, so anyone with the link can use it, possibly more than once
+	AllowsOpenInvites() bool
+
+	// Target checks if the inviter may invite people to the target,
+	// returning e.ErrorForbidden if not. Otherwise, it returns the
+	// name of the target, as shown to the invitee.
+	Target(inviter d.Subject, targetID string) (string, error)
+}
+
+// InviteRequest struct conveying the invitation an inviter wants to create
+type InviteRequest struct {
+	Kind     d.InviteKind
+	TargetID string
+	Email    string // the email the invitation is mailed to, empty for open invitations
+	MaxUses  int    // how often an open invitation can be used, 1 if left empty
+}
+
 // InviteLinkService struct, containing all features
 // the app supports regarding invite links
 type InviteLinkService struct {
 	InviteLinkRepo d.InviteLinkRepository
+	UserService    UserService
+	ClientURL      string // base url of the client, used in links sent by mail
+	kinds          map[d.InviteKind]InvitationKind
+}
+
+// RegisterKind makes the InviteLinkService support the kind of invitations
+func (i *InviteLinkService) RegisterKind(kind InvitationKind) {
+	if i.kinds == nil {
+		i.kinds = map[d.InviteKind]InvitationKind{}
+	}
+
+	i.kinds[kind.Kind()] = kind
 }
 
-// todo: refactor/ improve code. This is not conform open/closed principal
-// if i want to add a feature to create an invitelink for universities,
-// to bind a new student to that university, i need to create a new func in
-// this file. This can be improved i think.
-
-// CreateRepresentativeInvite creates a new invitelink
-// for new representatives.
-// Path should be a relative path, like: /signup/representatives/
-func (i InviteLinkService) CreateRepresentativeInvite(path string, r d.Representative) (d.InviteLink, error) {
-	// todo: replace util.URL to the domain name of the client, otherwise the created invitelink
-	// points to the endpoint of the server and not to the front-end
-	urlTemplate := "/signup" + path + "invite/<[companyID]>/<[inviteID]>"
-	inviteLinkID := uuid.New().String()
-	inviteLink, err := r.GenerateInviteLink(inviteLinkID, urlTemplate)
+// Create creates a new invitation of the requested kind, which is mailed
+// to the requested email. Invitations without an email aren't mailed and
+// are only allowed for kinds supporting open invitations.
+func (i InviteLinkService) Create(inviter d.Subject, request InviteRequest) (d.InviteLink, error) {
+	kind, ok := i.kinds[request.Kind]
+	if !ok {
+		return d.InviteLink{}, e.ErrorEntityNotFound
+	}
+
+	if request.Email == "" && !kind.AllowsOpenInvites() {
+		return d.InviteLink{}, e.ErrorForbidden
+	}
+
+	target, err := kind.Target(inviter, request.TargetID)
+	if err != nil {
+		return d.InviteLink{}, err
+	}
+
+	if request.MaxUses == 0 {
+		request.MaxUses = 1
+	}
+
+	id := uuid.New().String()
+	inviteLink, err := d.NewInviteLink(
+		id,
+		request.Kind,
+		request.TargetID,
+		kind.Role(),
+		kind.SignupPath()+request.TargetID+"/"+id,
+		request.Email,
+		inviter.ID,
+		request.MaxUses,
+		d.InviteLinkValidFor,
+	)
+
+	if err != nil {
+		return d.InviteLink{}, err
+	}
+
+	if inviteLink.Email == "" {
+		err = i.InviteLinkRepo.Create(inviteLink)
+		if err != nil {
+			return d.InviteLink{}, err
+		}
+
+		return inviteLink, nil
+	}
+
+	mail, err := i.inviteMail(inviteLink, inviter, target)
+	if err != nil {
+		return d.InviteLink{}, err
+	}
+
+	err = i.InviteLinkRepo.CreateWithMail(inviteLink, mail)
+	if err != nil {
+		return d.InviteLink{}, err
+	}
+
+	return inviteLink, nil
+}
+
+// FindUsable fetches the invitation of the provided kind and target if it
+// can still be used to sign up with the provided email. It returns
+// e.ErrorInviteExpired if it has expired, e.ErrorInviteNotPending if it has
+// been used up or revoked, and e.ErrorForbidden if it has been sent to
+// another email.
+func (i InviteLinkService) FindUsable(id string, kind d.InviteKind, targetID, email string) (d.InviteLink, error) {
+	inviteLink, err := i.InviteLinkRepo.FindByID(id)
+	if err != nil || inviteLink.Kind != kind || inviteLink.TargetID != targetID {
+		return d.InviteLink{}, e.ErrorEntityNotFound
+	}
+
+	if inviteLink.Status() == d.InviteExpired {
+		return d.InviteLink{}, e.ErrorInviteExpired
+	}
+
+	if inviteLink.Status() != d.InvitePending {
+		return d.InviteLink{}, e.ErrorInviteNotPending
+	}
+
+	if !inviteLink.IsFor(email) {
+		return d.InviteLink{}, e.ErrorForbidden
+	}
+
+	return inviteLink, nil
+}
+
+// Revoke revokes the invitation, so it can no longer be used.
+// Only the user that created it can revoke it.
+func (i InviteLinkService) Revoke(id, creatorID string) error {
+	inviteLink, err := i.InviteLinkRepo.FindByID(id)
+	if err != nil {
+		return e.ErrorEntityNotFound
+	}
+
+	if inviteLink.CreatedBy != creatorID {
+		return e.ErrorForbidden
+	}
+
+	return i.InviteLinkRepo.Revoke(id, time.Now())
+}
+
+// Resend makes the invitation valid again and mails it to the invitee
+// once more. Only the user that created it can resend it, as long as
+// they may still invite people to its target.
+func (i InviteLinkService) Resend(id string, inviter d.Subject) (d.InviteLink, error) {
+	inviteLink, err := i.InviteLinkRepo.FindByID(id)
+	if err != nil {
+		return d.InviteLink{}, e.ErrorEntityNotFound
+	}
+
+	if inviteLink.CreatedBy != inviter.ID {
+		return d.InviteLink{}, e.ErrorForbidden
+	}
+
+	kind, ok := i.kinds[inviteLink.Kind]
+	if !ok {
+		return d.InviteLink{}, e.ErrorEntityNotFound
+	}
+
+	target, err := kind.Target(inviter, inviteLink.TargetID)
+	if err != nil {
+		return d.InviteLink{}, err
+	}
+
+	if inviteLink.Email == "" {
+		return d.InviteLink{}, e.ErrorInviteNotPending
+	}
+
+	err = inviteLink.Renew(d.InviteLinkValidFor)
+	if err != nil {
+		return d.InviteLink{}, e.ErrorInviteNotPending
+	}
+
+	mail, err := i.inviteMail(inviteLink, inviter, target)
 	if err != nil {
 		return d.InviteLink{}, err
 	}
 
-	err = i.InviteLinkRepo.Create(inviteLink)
+	err = i.InviteLinkRepo.RenewWithMail(inviteLink, mail)
 	if err != nil {
 		return d.InviteLink{}, err
 	}
@@ -48,8 +217,8 @@ func (i InviteLinkService) FindByID(id string) (d.InviteLink, error) {
 }
 
 // FindByCreator fetches all inviteLinks that are created by the provided id
-func (i InviteLinkService) FindByCreator(representativeID string) ([]d.InviteLink, error) {
-	inviteLinks, err := i.InviteLinkRepo.FindByCreator(representativeID)
+func (i InviteLinkService) FindByCreator(creatorID string) ([]d.InviteLink, error) {
+	inviteLinks, err := i.InviteLinkRepo.FindByCreator(creatorID)
 	if err != nil {
 		return []d.InviteLink{}, err
 	}
@@ -57,12 +226,18 @@ func (i InviteLinkService) FindByCreator(representativeID string) ([]d.InviteLin
 	return inviteLinks, nil
 }
 
-// Update updates the invitelink
-func (i InviteLinkService) Update(inviteLink d.InviteLink) error {
-	err := i.InviteLinkRepo.Update(inviteLink)
+// helper func that creates the mail sending the invitelink to the invitee
+func (i InviteLinkService) inviteMail(inviteLink d.InviteLink, inviter d.Subject, target string) (d.OutboxMail, error) {
+	user, err := i.UserService.FindByID(inviter.UserID)
 	if err != nil {
-		return err
+		return d.OutboxMail{}, err
 	}
 
-	return nil
+	return d.NewOutboxMail(uuid.New().String(), d.InviteMail, inviteLink.Email, map[string]string{
+		"Inviter":   fullName(user),
+		"Target":    target,
+		"Role":      inviteLink.Role,
+		"Link":      i.ClientURL + inviteLink.URL,
+		"ExpiresAt": inviteLink.ExpiryDate.Format("January 2, 2006 15:04 MST"),
+	})
 }
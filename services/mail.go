@@ -0,0 +1,25 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/janabe/cscoupler/domain"
+)
+
+// helper func that returns the full name of the user, as used in mails
+func fullName(user domain.User) string {
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// helper func that shortens the text to at most max characters,
+// so it can be used as preview or title in mails
+func summarize(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	return strings.TrimSpace(string(runes[:max])) + "..."
+}
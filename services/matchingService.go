@@ -0,0 +1,117 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// StudentMatch pairs a student with how well they fit a project
+type StudentMatch struct {
+	Student domain.Student
+	Match   domain.Match
+}
+
+// ProjectMatch pairs a project with how well a student fits it
+type ProjectMatch struct {
+	Project domain.Project
+	Match   domain.Match
+}
+
+// MatchingService struct, containing all features the app
+// supports regarding matching students with projects
+type MatchingService struct {
+	StudentRepo   domain.StudentRepository
+	ProjectRepo   domain.ProjectRepository
+	PolicyService PolicyService
+}
+
+// MatchesForProject finds the students that fit the project best, best fit first.
+// Only representatives of the company owning the project can see its matches.
+// Students that don't fit the project at all, or that the company may not
+// view, are left out.
+func (m MatchingService) MatchesForProject(projectID, representativeID string, limit int) ([]StudentMatch, error) {
+	project, err := m.ProjectRepo.FindByID(projectID)
+	if err != nil {
+		return []StudentMatch{}, e.ErrorEntityNotFound
+	}
+
+	resource := domain.Resource{CompanyID: project.CompanyID}
+	err = m.PolicyService.AuthorizeRepresentative(representativeID, domain.ReadProjectMatches, resource)
+	if err != nil {
+		return []StudentMatch{}, err
+	}
+
+	matches := []StudentMatch{}
+	page := domain.NewPage(domain.MaxPageLimit, "")
+	for {
+		query := domain.StudentQuery{VisibleTo: project.CompanyID}
+		students, next, err := m.StudentRepo.FindByQuery(query, page)
+		if err != nil {
+			return []StudentMatch{}, err
+		}
+
+		for _, student := range students {
+			match := domain.Score(student, project)
+			if match.Score > 0 {
+				matches = append(matches, StudentMatch{Student: student.WithoutResume(), Match: match})
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		page.After = next
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Match.Score > matches[j].Match.Score
+	})
+
+	if limit = domain.NewPage(limit, "").Limit; len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// RecommendedProjects finds the projects the student fits best, best fit first.
+// Projects the student doesn't fit at all are left out.
+func (m MatchingService) RecommendedProjects(studentID string, limit int) ([]ProjectMatch, error) {
+	student, err := m.StudentRepo.FindByID(studentID)
+	if err != nil {
+		return []ProjectMatch{}, e.ErrorEntityNotFound
+	}
+
+	matches := []ProjectMatch{}
+	page := domain.NewPage(domain.MaxPageLimit, "")
+	for {
+		projects, next, err := m.ProjectRepo.FindAll(page)
+		if err != nil {
+			return []ProjectMatch{}, err
+		}
+
+		for _, project := range projects {
+			match := domain.Score(student, project)
+			if match.Score > 0 {
+				matches = append(matches, ProjectMatch{Project: project, Match: match})
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		page.After = next
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Match.Score > matches[j].Match.Score
+	})
+
+	if limit = domain.NewPage(limit, "").Limit; len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
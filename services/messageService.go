@@ -0,0 +1,362 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// MessageService struct, containing all features
+// the app supports regarding messages and conversations
+type MessageService struct {
+	MessageRepo           domain.MessageRepository
+	ConversationRepo      domain.ConversationRepository
+	BlockRepo             domain.BlockRepository
+	UserService           UserService
+	ProjectService        ProjectService
+	RepresentativeService RepresentativeService
+	Policy                MessagePolicy
+	UnitOfWork            domain.UnitOfWork
+	ClientURL             string // base url of the client, used in links sent by mail
+}
+
+// MessagePolicy contains the limits on the amount of new
+// conversations representatives can start with students,
+// to prevent them from spamming every student.
+// A limit of 0 means there is no limit.
+type MessagePolicy struct {
+	MaxConversationsPerRepresentative int
+	MaxConversationsPerCompany        int
+	Window                            time.Duration // the period the limits apply to, e.g. 24 hours
+}
+
+// Send sends a message to its receiver. The message gets added to the
+// conversation between the sender and receiver about the message's
+// project, which is started if it doesn't exist yet. The project has to
+// belong to the company of the representative taking part, whether they
+// send or receive the message. A new conversation is only stored together
+// with its first message, so neither is kept without the other.
+// It returns the message as it has been stored.
+func (m MessageService) Send(message domain.Message) (domain.Message, error) {
+	sender, err := m.UserService.FindByID(message.Sender)
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	receiver, err := m.UserService.FindByID(message.Receiver)
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	err = m.checkProject(message.ProjectID, sender, receiver)
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	// whether the sender may start a conversation is checked in the unit of work
+	// creating it, so concurrent messages can't exceed the limits together
+	err = m.UnitOfWork.Do(func(repos domain.Repositories) error {
+		conversation, err := repos.Conversations.FindBetween(message.Sender, message.Receiver, message.ProjectID)
+		isNew := e.Is(err, e.ErrorEntityNotFound)
+		if err != nil && !isNew {
+			return err
+		}
+
+		if isNew {
+			err = m.mayStartConversation(repos, sender, receiver)
+			if err != nil {
+				return err
+			}
+
+			conversation, err = domain.NewConversation(
+				uuid.New().String(),
+				message.Sender,
+				message.Receiver,
+				message.ProjectID,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			err = repos.Conversations.Create(conversation)
+			if err != nil {
+				return err
+			}
+		}
+
+		message.ConversationID = conversation.ID
+		return m.store(repos, message, sender, receiver)
+	})
+
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	return message, nil
+}
+
+// Reply sends a message in an existing conversation
+// to the other participant of the conversation
+func (m MessageService) Reply(conversationID, senderID, body string) (domain.Message, error) {
+	conversation, err := m.ConversationRepo.FindByID(conversationID)
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	if !conversation.HasParticipant(senderID) {
+		return domain.Message{}, e.ErrorNotParticipant
+	}
+
+	message, err := domain.NewMessage(
+		uuid.New().String(),
+		senderID,
+		conversation.Partner(senderID),
+		body,
+		conversation.ProjectID,
+	)
+
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	sender, err := m.UserService.FindByID(message.Sender)
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	receiver, err := m.UserService.FindByID(message.Receiver)
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	message.ConversationID = conversation.ID
+	err = m.UnitOfWork.Do(func(repos domain.Repositories) error {
+		return m.store(repos, message, sender, receiver)
+	})
+
+	if err != nil {
+		return domain.Message{}, err
+	}
+
+	return message, nil
+}
+
+// Inbox finds all conversations the user is part of, ordered
+// from most to least recent activity, with the unread count
+// filled in for this user
+func (m MessageService) Inbox(userID string) ([]domain.Conversation, error) {
+	conversations, err := m.ConversationRepo.FindByParticipant(userID)
+	if err != nil {
+		return []domain.Conversation{}, err
+	}
+
+	return conversations, nil
+}
+
+// FindThread finds all messages of the conversation, marking the
+// conversation as read for the user requesting them
+func (m MessageService) FindThread(conversationID, userID string) ([]domain.Message, error) {
+	conversation, err := m.ConversationRepo.FindByID(conversationID)
+	if err != nil {
+		return []domain.Message{}, err
+	}
+
+	if !conversation.HasParticipant(userID) {
+		return []domain.Message{}, e.ErrorNotParticipant
+	}
+
+	messages, err := m.MessageRepo.FindByConversation(conversationID)
+	if err != nil {
+		return []domain.Message{}, err
+	}
+
+	err = m.ConversationRepo.MarkRead(conversationID, userID, time.Now())
+	if err != nil {
+		return []domain.Message{}, err
+	}
+
+	return messages, nil
+}
+
+// BlockCompany blocks the company for the student, so its
+// representatives can't start new conversations with the student
+func (m MessageService) BlockCompany(studentID, companyID string) error {
+	if !m.RepresentativeService.CompanyService.Exists(companyID) {
+		return e.ErrorEntityNotFound
+	}
+
+	block, err := domain.NewBlock(studentID, companyID)
+	if err != nil {
+		return err
+	}
+
+	err = m.BlockRepo.Create(block)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnblockCompany lifts the block the student placed on the company
+func (m MessageService) UnblockCompany(studentID, companyID string) error {
+	err := m.BlockRepo.Delete(studentID, companyID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindBlocks finds all blocks the student placed on companies
+func (m MessageService) FindBlocks(studentID string) ([]domain.Block, error) {
+	blocks, err := m.BlockRepo.FindByStudent(studentID)
+	if err != nil {
+		return []domain.Block{}, err
+	}
+
+	return blocks, nil
+}
+
+// helper func that checks if the project a message is about exists and
+// belongs to the company of the representative taking part, which is the
+// sender if they are a representative. Messages between students can't be
+// about a project, as no company takes part.
+func (m MessageService) checkProject(projectID string, sender, receiver domain.User) error {
+	if projectID == "" {
+		return nil
+	}
+
+	project, err := m.ProjectService.FindByID(projectID)
+	if err != nil {
+		return err
+	}
+
+	user := sender
+	if sender.Role != domain.RepresentativeRole {
+		user = receiver
+	}
+
+	if user.Role != domain.RepresentativeRole {
+		return e.ErrorForbidden
+	}
+
+	representativeID, err := m.UserService.FindRoleID(user)
+	if err != nil {
+		return err
+	}
+
+	representative, err := m.RepresentativeService.FindByID(representativeID)
+	if err != nil {
+		return err
+	}
+
+	if project.CompanyID != representative.CompanyID {
+		return e.ErrorForbidden
+	}
+
+	return nil
+}
+
+// helper func that checks, in the repos of a unit of work, if the sender is
+// allowed to start a new conversation with the receiver. Only representatives
+// contacting students are restricted, by the blocks students placed on companies
+// and by the limits of the message policy. The limit of the company is checked
+// first, as counting locks the representatives counted for, which then always
+// includes the sender.
+func (m MessageService) mayStartConversation(repos domain.Repositories, sender, receiver domain.User) error {
+	if sender.Role != domain.RepresentativeRole || receiver.Role != domain.StudentRole {
+		return nil
+	}
+
+	representativeID, err := repos.Users.FindRoleID(sender)
+	if err != nil {
+		return err
+	}
+
+	representative, err := repos.Representatives.FindByID(representativeID)
+	if err != nil {
+		return err
+	}
+
+	studentID, err := repos.Users.FindRoleID(receiver)
+	if err != nil {
+		return err
+	}
+
+	blocked, err := repos.Blocks.Exists(studentID, representative.CompanyID)
+	if err != nil {
+		return err
+	}
+
+	if blocked {
+		return e.ErrorBlocked
+	}
+
+	since := time.Now().Add(-m.Policy.Window)
+	if m.Policy.MaxConversationsPerCompany > 0 {
+		company, err := repos.Companies.FindByID(representative.CompanyID)
+		if err != nil {
+			return err
+		}
+
+		userIDs := []string{}
+		for _, r := range company.Representatives {
+			userIDs = append(userIDs, r.User.ID)
+		}
+
+		count, err := repos.Conversations.CountStartedBy(userIDs, since)
+		if err != nil {
+			return err
+		}
+
+		if count >= m.Policy.MaxConversationsPerCompany {
+			return e.ErrorRateLimited
+		}
+	}
+
+	if m.Policy.MaxConversationsPerRepresentative > 0 {
+		count, err := repos.Conversations.CountStartedBy([]string{sender.ID}, since)
+		if err != nil {
+			return err
+		}
+
+		if count >= m.Policy.MaxConversationsPerRepresentative {
+			return e.ErrorRateLimited
+		}
+	}
+
+	return nil
+}
+
+// helper func that stores the message in the repos of a unit of work and queues
+// the mail notifying its receiver, the sender has read the conversation up until
+// their own message
+func (m MessageService) store(repos domain.Repositories, message domain.Message, sender, receiver domain.User) error {
+	mail, err := domain.NewOutboxMail(uuid.New().String(), domain.NewMessageMail, receiver.Email, map[string]string{
+		"Sender":   fullName(sender),
+		"Receiver": receiver.FirstName,
+		"Preview":  summarize(message.Body, 200),
+		"Link":     m.ClientURL + "/messages/" + message.ConversationID,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	err = repos.Messages.CreateWithMail(message, mail)
+	if err != nil {
+		return err
+	}
+
+	err = repos.Conversations.MarkRead(message.ConversationID, message.Sender, message.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// PolicyService struct, deciding which
+// subjects may perform which actions
+type PolicyService struct {
+	RepresentativeService RepresentativeService
+}
+
+// Authorize checks if the subject may perform the action on the resource,
+// returning e.ErrorForbidden if not. The subject is resolved first.
+func (p PolicyService) Authorize(subject domain.Subject, action domain.Action, resource domain.Resource) error {
+	subject = p.Resolve(subject)
+	if !domain.IsAllowed(subject, action, resource) {
+		return e.ErrorForbidden
+	}
+
+	return nil
+}
+
+// Resolve looks up the company of representatives, and their role within
+// it, when the subject doesn't contain them yet. Representatives that have
+// been removed from their company don't get either.
+func (p PolicyService) Resolve(subject domain.Subject) domain.Subject {
+	if subject.Role == domain.RepresentativeRole && (subject.CompanyID == "" || subject.CompanyRole == "") {
+		representative, err := p.RepresentativeService.FindByID(subject.ID)
+		if err == nil && !representative.IsRemoved() {
+			subject.CompanyID = representative.CompanyID
+			subject.CompanyRole = representative.CompanyRole
+		}
+	}
+
+	return subject
+}
+
+// AuthorizeRepresentative checks if the representative with the provided
+// id may perform the action on the resource, returning e.ErrorForbidden if not
+func (p PolicyService) AuthorizeRepresentative(representativeID string, action domain.Action, resource domain.Resource) error {
+	subject := domain.Subject{ID: representativeID, Role: domain.RepresentativeRole}
+	return p.Authorize(subject, action, resource)
+}
@@ -2,40 +2,65 @@ package services
 
 import (
 	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // ProjectService struct, containing all features
 // the app support regarding just projects
 type ProjectService struct {
-	ProjectRepo domain.ProjectRepository
+	ProjectRepo   domain.ProjectRepository
+	PolicyService PolicyService
+	UnitOfWork    domain.UnitOfWork
 }
 
 // FindByID finds a project by ID
 func (p ProjectService) FindByID(id string) (domain.Project, error) {
 	project, err := p.ProjectRepo.FindByID(id)
 	if err != nil {
-		return domain.Project{}, nil
+		return domain.Project{}, err
 	}
 
 	return project, nil
 }
 
-// Delete deletes a project
-func (p ProjectService) Delete(id string) error {
-	err := p.ProjectRepo.Delete(id)
+// Exists checks if a project exists with the provided id
+func (p ProjectService) Exists(id string) bool {
+	_, err := p.ProjectRepo.FindByID(id)
 	if err != nil {
-		return err
+		return false
 	}
 
-	return nil
+	return true
 }
 
-// FetchAll fetches all projects
-func (p ProjectService) FetchAll() ([]domain.Project, error) {
-	projects, err := p.ProjectRepo.FindAll()
+// Delete deletes a project, which the subject has to be allowed to do.
+// It returns e.ErrorEntityNotFound if the project doesn't exist and
+// e.ErrorForbidden if the subject may not delete it.
+func (p ProjectService) Delete(subject domain.Subject, id string) error {
+	subject = p.PolicyService.Resolve(subject)
+
+	return p.UnitOfWork.Do(func(repos domain.Repositories) error {
+		project, err := repos.Projects.FindByID(id)
+		if err != nil {
+			return e.ErrorEntityNotFound
+		}
+
+		resource := domain.Resource{CompanyID: project.CompanyID}
+		if !domain.IsAllowed(subject, domain.DeleteProject, resource) {
+			return e.ErrorForbidden
+		}
+
+		return repos.Projects.Delete(id)
+	})
+}
+
+// FetchAll fetches a page of all projects, along with
+// the id to continue after for the next page
+func (p ProjectService) FetchAll(page domain.Page) ([]domain.Project, string, error) {
+	projects, next, err := p.ProjectRepo.FindAll(page)
 	if err != nil {
-		return []domain.Project{}, err
+		return []domain.Project{}, "", err
 	}
 
-	return projects, nil
+	return projects, next, nil
 }
@@ -0,0 +1,49 @@
+package services
+
+import (
+	d "github.com/janabe/cscoupler/domain"
+)
+
+// RepresentativeInvitations struct, the kind of invitations
+// inviting someone to become a representative of a company
+type RepresentativeInvitations struct {
+	CompanyService CompanyService
+	PolicyService  PolicyService
+}
+
+// Kind returns the kind of invitations this handles
+func (r RepresentativeInvitations) Kind() d.InviteKind {
+	return d.RepresentativeInvite
+}
+
+// Role returns the role invitees get when signing up
+func (r RepresentativeInvitations) Role() string {
+	return d.RepresentativeRole
+}
+
+// SignupPath returns the path representatives sign up on
+func (r RepresentativeInvitations) SignupPath() string {
+	return "/signup/representatives/invite/"
+}
+
+// AllowsOpenInvites returns false, as representatives get access to
+// the data of their company, so each one has to be invited by email
+func (r RepresentativeInvitations) AllowsOpenInvites() bool {
+	return false
+}
+
+// Target checks if the inviter may invite representatives to the
+// company with the provided id, returning the name of the company
+func (r RepresentativeInvitations) Target(inviter d.Subject, companyID string) (string, error) {
+	err := r.PolicyService.Authorize(inviter, d.InviteRepresentative, d.Resource{CompanyID: companyID})
+	if err != nil {
+		return "", err
+	}
+
+	company, err := r.CompanyService.FindByID(companyID)
+	if err != nil {
+		return "", err
+	}
+
+	return company.Name, nil
+}
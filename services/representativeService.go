@@ -1,6 +1,8 @@
 package services
 
 import (
+	"time"
+
 	"github.com/janabe/cscoupler/domain"
 	e "github.com/janabe/cscoupler/errors"
 )
@@ -11,20 +13,26 @@ type RepresentativeService struct {
 	RepresentativeRepo domain.RepresentativeRepository
 	CompanyService     CompanyService
 	UserService        UserService
+	UnitOfWork         domain.UnitOfWork
 }
 
-// Register registers a representive with the provided data
-func (r RepresentativeService) Register(representative domain.Representative) error {
-	if !r.CompanyService.Exists(representative.CompanyID) {
-		return e.ErrorEntityNotFound
-	}
+// Register registers a representive, who signed up with the invitation
+// with the provided id. The invitation is redeemed at the same time, so
+// it is never used up without the representative being registered.
+func (r RepresentativeService) Register(representative domain.Representative, inviteID string) error {
+	return r.UnitOfWork.Do(func(repos domain.Repositories) error {
+		_, err := repos.Companies.FindByID(representative.CompanyID)
+		if err != nil {
+			return e.ErrorEntityNotFound
+		}
 
-	err := r.RepresentativeRepo.Create(representative)
-	if err != nil {
-		return err
-	}
+		err = repos.Representatives.Create(representative)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return repos.InviteLinks.Redeem(inviteID, time.Now())
+	})
 }
 
 // Edit edits the representative's information
@@ -46,3 +54,77 @@ func (r RepresentativeService) FindByID(id string) (domain.Representative, error
 
 	return repr, nil
 }
+
+// ChangeCompanyRole gives the representative another role within their
+// company. Ownership can't be given or taken away this way, it can only
+// be handed over by the owner with TransferOwnership.
+func (r RepresentativeService) ChangeCompanyRole(representativeID string, role domain.CompanyRole) error {
+	if !domain.IsValidCompanyRole(role) {
+		return e.Invalid("companyRole", "doesn't exist")
+	}
+
+	if role == domain.CompanyOwner {
+		return e.ErrorForbidden
+	}
+
+	representative, err := r.FindByID(representativeID)
+	if err != nil || representative.IsRemoved() {
+		return e.ErrorEntityNotFound
+	}
+
+	if representative.CompanyRole == domain.CompanyOwner {
+		return e.ErrorForbidden
+	}
+
+	return r.RepresentativeRepo.UpdateCompanyRole(representativeID, role)
+}
+
+// Remove removes the representative from their company, handing their
+// projects and conversations over to the successor, who has to work
+// for the same company. The owner can't be removed before handing
+// over the ownership with TransferOwnership.
+func (r RepresentativeService) Remove(representativeID, successorID string) error {
+	representative, err := r.FindByID(representativeID)
+	if err != nil || representative.IsRemoved() {
+		return e.ErrorEntityNotFound
+	}
+
+	if representative.CompanyRole == domain.CompanyOwner {
+		return e.ErrorForbidden
+	}
+
+	successor, err := r.FindByID(successorID)
+	if err != nil || successor.IsRemoved() {
+		return e.ErrorEntityNotFound
+	}
+
+	if successor.ID == representative.ID || successor.CompanyID != representative.CompanyID {
+		return e.Invalid("successor", "has to be a colleague of the representative")
+	}
+
+	return r.RepresentativeRepo.Remove(representativeID, successorID, time.Now())
+}
+
+// TransferOwnership makes the new owner, who has to work for the same
+// company, the owner of the company. The current owner becomes an admin.
+func (r RepresentativeService) TransferOwnership(ownerID, newOwnerID string) error {
+	owner, err := r.FindByID(ownerID)
+	if err != nil || owner.IsRemoved() {
+		return e.ErrorEntityNotFound
+	}
+
+	if owner.CompanyRole != domain.CompanyOwner {
+		return e.ErrorForbidden
+	}
+
+	newOwner, err := r.FindByID(newOwnerID)
+	if err != nil || newOwner.IsRemoved() {
+		return e.ErrorEntityNotFound
+	}
+
+	if newOwner.ID == owner.ID || newOwner.CompanyID != owner.CompanyID {
+		return e.Invalid("newOwner", "has to be a colleague of the owner")
+	}
+
+	return r.RepresentativeRepo.TransferOwnership(ownerID, newOwnerID)
+}
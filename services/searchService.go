@@ -0,0 +1,22 @@
+package services
+
+import (
+	"github.com/janabe/cscoupler/domain"
+)
+
+// SearchService struct, containing all features
+// the app supports regarding searching
+type SearchService struct {
+	SearchRepo domain.SearchRepository
+}
+
+// Search finds the projects and companies matching
+// the provided query, most relevant first
+func (s SearchService) Search(query domain.SearchQuery) ([]domain.SearchHit, error) {
+	hits, err := s.SearchRepo.Search(query)
+	if err != nil {
+		return []domain.SearchHit{}, err
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/util"
+)
+
+// DefaultRefreshTTL is how long a refresh token
+// stays valid when no other duration is set
+const DefaultRefreshTTL = 30 * 24 * time.Hour
+
+// SessionService struct, containing all features
+// the app supports regarding sessions of users
+type SessionService struct {
+	SessionRepo domain.SessionRepository
+	RefreshTTL  time.Duration
+}
+
+// Start starts a new session for the user, returning
+// the session together with its refresh token
+func (s SessionService) Start(userID string) (domain.Session, string, error) {
+	token, err := util.GenerateToken()
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+
+	session, err := domain.NewSession(uuid.New().String(), uuid.New().String(), userID, token, s.refreshTTL())
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+
+	err = s.SessionRepo.Create(session)
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+
+	return session, token, nil
+}
+
+// Refresh rotates the session holding the refresh token, returning the new
+// session together with its refresh token. Using a refresh token that has
+// already been used revokes all sessions of its family, as this means the
+// token has been stolen by someone.
+func (s SessionService) Refresh(token string) (domain.Session, string, error) {
+	session, err := s.SessionRepo.FindByTokenHash(domain.HashToken(token))
+	if err != nil {
+		return domain.Session{}, "", e.ErrorInvalidToken
+	}
+
+	if session.IsUsed() {
+		return domain.Session{}, "", s.revokeReused(session)
+	}
+
+	if !session.IsActive() {
+		return domain.Session{}, "", e.ErrorInvalidToken
+	}
+
+	newToken, err := util.GenerateToken()
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+
+	newSession, err := session.Rotate(uuid.New().String(), newToken, s.refreshTTL())
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+
+	err = s.SessionRepo.Rotate(session, newSession)
+	if e.Is(err, e.ErrorTokenReused) {
+		return domain.Session{}, "", s.revokeReused(session)
+	}
+
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+
+	return newSession, newToken, nil
+}
+
+// SignOut revokes all sessions of the family, signing out the device
+func (s SessionService) SignOut(familyID string) error {
+	return s.SessionRepo.RevokeFamily(familyID, time.Now())
+}
+
+// SignOutAll revokes all sessions of the user, signing out all devices
+func (s SessionService) SignOutAll(userID string) error {
+	return s.SessionRepo.RevokeByUser(userID, time.Now())
+}
+
+// IsActive checks if the family of sessions hasn't been revoked or expired
+func (s SessionService) IsActive(familyID string) bool {
+	active, err := s.SessionRepo.IsFamilyActive(familyID)
+	if err != nil {
+		return false
+	}
+
+	return active
+}
+
+// helper func that revokes the family of the reused session
+func (s SessionService) revokeReused(session domain.Session) error {
+	err := s.SessionRepo.RevokeFamily(session.FamilyID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return e.ErrorTokenReused
+}
+
+// helper func that returns how long refresh tokens stay valid
+func (s SessionService) refreshTTL() time.Duration {
+	if s.RefreshTTL <= 0 {
+		return DefaultRefreshTTL
+	}
+
+	return s.RefreshTTL
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// ShortlistService struct, containing all features
+// the app supports regarding the shortlists of companies
+type ShortlistService struct {
+	ShortlistRepo         domain.ShortlistRepository
+	StudentService        StudentService
+	ProjectService        ProjectService
+	RepresentativeService RepresentativeService
+	PolicyService         PolicyService
+}
+
+// Add adds a student to the shortlist of a company. If the entry is
+// tied to a project, this project has to belong to the same company.
+// The representative adding the entry has to be allowed to manage the
+// shortlist of the company.
+func (s ShortlistService) Add(entry domain.ShortlistEntry) error {
+	resource := domain.Resource{CompanyID: entry.CompanyID}
+	err := s.PolicyService.AuthorizeRepresentative(entry.AddedBy, domain.ManageShortlist, resource)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.StudentService.FindByID(entry.StudentID)
+	if err != nil {
+		return e.ErrorEntityNotFound
+	}
+
+	err = s.checkProject(entry)
+	if err != nil {
+		return err
+	}
+
+	err = s.ShortlistRepo.Create(entry)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByRepresentative finds the shortlist of the
+// company the representative works for
+func (s ShortlistService) FindByRepresentative(representativeID string) ([]domain.ShortlistEntry, error) {
+	representative, err := s.RepresentativeService.FindByID(representativeID)
+	if err != nil {
+		return []domain.ShortlistEntry{}, e.ErrorEntityNotFound
+	}
+
+	entries, err := s.ShortlistRepo.FindByCompany(representative.CompanyID)
+	if err != nil {
+		return []domain.ShortlistEntry{}, err
+	}
+
+	return entries, nil
+}
+
+// Edit edits the notes, tags and project of a shortlist entry. The representative
+// has to work for the company owning the shortlist. The student can't be on the
+// shortlist twice for the same project, or twice without a project.
+func (s ShortlistService) Edit(entryID, representativeID, projectID, notes string, tags []string) (domain.ShortlistEntry, error) {
+	entry, err := s.findOwnEntry(entryID, representativeID)
+	if err != nil {
+		return domain.ShortlistEntry{}, err
+	}
+
+	entry.ProjectID = projectID
+	entry.Edit(notes, tags)
+
+	err = s.checkProject(entry)
+	if err != nil {
+		return domain.ShortlistEntry{}, err
+	}
+
+	err = s.ShortlistRepo.Update(entry)
+	if err != nil {
+		return domain.ShortlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// Remove removes a student from the shortlist. The representative
+// has to work for the company owning the shortlist.
+func (s ShortlistService) Remove(entryID, representativeID string) error {
+	_, err := s.findOwnEntry(entryID, representativeID)
+	if err != nil {
+		return err
+	}
+
+	err = s.ShortlistRepo.Delete(entryID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// helper func that finds the entry, checking if it is on the
+// shortlist of the company the representative works for
+func (s ShortlistService) findOwnEntry(entryID, representativeID string) (domain.ShortlistEntry, error) {
+	entry, err := s.ShortlistRepo.FindByID(entryID)
+	if err != nil {
+		return domain.ShortlistEntry{}, e.ErrorEntityNotFound
+	}
+
+	resource := domain.Resource{CompanyID: entry.CompanyID}
+	err = s.PolicyService.AuthorizeRepresentative(representativeID, domain.ManageShortlist, resource)
+	if err != nil {
+		return domain.ShortlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// helper func that checks if the project the entry is tied to
+// exists and belongs to the company owning the shortlist
+func (s ShortlistService) checkProject(entry domain.ShortlistEntry) error {
+	if entry.ProjectID == "" {
+		return nil
+	}
+
+	project, err := s.ProjectService.FindByID(entry.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if project.CompanyID != entry.CompanyID {
+		return e.ErrorForbidden
+	}
+
+	return nil
+}
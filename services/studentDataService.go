@@ -0,0 +1,97 @@
+package services
+
+import (
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// StudentDataService struct, containing the features that
+// let students download or erase their personal data
+type StudentDataService struct {
+	StudentRepo      domain.StudentRepository
+	ConversationRepo domain.ConversationRepository
+	MessageRepo      domain.MessageRepository
+	ApplicationRepo  domain.ApplicationRepository
+	UserService      UserService
+}
+
+// Export gathers all personal data stored about the student: their
+// profile, their conversations with the messages in them and their
+// applications. The resume files themselves aren't read.
+func (s StudentDataService) Export(studentID string) (domain.StudentExport, error) {
+	student, err := s.StudentRepo.FindByID(studentID)
+	if err != nil {
+		return domain.StudentExport{}, e.ErrorEntityNotFound
+	}
+
+	conversations, err := s.ConversationRepo.FindByParticipant(student.User.ID)
+	if err != nil {
+		return domain.StudentExport{}, err
+	}
+
+	messages := []domain.Message{}
+	for _, conversation := range conversations {
+		thread, err := s.MessageRepo.FindByConversation(conversation.ID)
+		if err != nil {
+			return domain.StudentExport{}, err
+		}
+
+		messages = append(messages, thread...)
+	}
+
+	applications, err := s.ApplicationRepo.FindByStudent(student.ID)
+	if err != nil {
+		return domain.StudentExport{}, err
+	}
+
+	return domain.StudentExport{
+		Student:       student,
+		Conversations: conversations,
+		Messages:      messages,
+		Applications:  applications,
+	}, nil
+}
+
+// Delete deletes the account of the student and everything tied to it,
+// after checking their password once more. It returns the paths of the
+// resumes of the student and their applications, which are no longer
+// used and have to be removed from disk.
+func (s StudentDataService) Delete(studentID, password string) ([]string, error) {
+	student, err := s.StudentRepo.FindByID(studentID)
+	if err != nil {
+		return []string{}, e.ErrorEntityNotFound
+	}
+
+	// students are fetched without the hash of their password
+	user, err := s.UserService.FindByID(student.User.ID)
+	if err != nil {
+		return []string{}, e.ErrorEntityNotFound
+	}
+
+	if !s.UserService.ValidatePassword(user.HashedPassword, password) {
+		return []string{}, e.ErrorForbidden
+	}
+
+	applications, err := s.ApplicationRepo.FindByStudent(student.ID)
+	if err != nil {
+		return []string{}, err
+	}
+
+	err = s.StudentRepo.Delete(student)
+	if err != nil {
+		return []string{}, err
+	}
+
+	resumes := []string{}
+	if student.Resume != "" {
+		resumes = append(resumes, student.Resume)
+	}
+
+	for _, application := range applications {
+		if application.Resume != "" {
+			resumes = append(resumes, application.Resume)
+		}
+	}
+
+	return resumes, nil
+}
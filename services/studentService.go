@@ -1,13 +1,20 @@
 package services
 
 import (
+	"time"
+
 	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
 )
 
 // StudentService struct, containing all features
 // the app supports regaring students
 type StudentService struct {
-	StudentRepo domain.StudentRepository
+	StudentRepo       domain.StudentRepository
+	ApplicationRepo   domain.ApplicationRepository
+	ProjectRepo       domain.ProjectRepository
+	ResumeRequestRepo domain.ResumeRequestRepository
+	PolicyService     PolicyService
 }
 
 // Register registers a new Student
@@ -40,12 +47,167 @@ func (s StudentService) FindByID(id string) (domain.Student, error) {
 	return student, nil
 }
 
-// FindAll finds all students present
-func (s StudentService) FindAll() ([]domain.Student, error) {
-	students, err := s.StudentRepo.FindAll()
+// FindAll finds a page of all students present, along
+// with the id to continue after for the next page
+func (s StudentService) FindAll(page domain.Page) ([]domain.Student, string, error) {
+	students, next, err := s.StudentRepo.FindAll(page)
+	if err != nil {
+		return []domain.Student{}, "", err
+	}
+
+	return students, next, nil
+}
+
+// FindVisible finds a student based on an identifier, as seen by the subject. It
+// returns e.ErrorForbidden if the subject may not view the profile of the student,
+// and leaves out the resume if the student doesn't share it with the subject.
+func (s StudentService) FindVisible(id string, subject domain.Subject) (domain.Student, error) {
+	student, err := s.StudentRepo.FindByID(id)
+	if err != nil {
+		return domain.Student{}, err
+	}
+
+	subject = s.PolicyService.Resolve(subject)
+	appliedTo, err := s.appliedCompanies(student.ID)
+	if err != nil {
+		return domain.Student{}, err
+	}
+
+	profile := domain.Resource{OwnerID: student.ID, Visibility: student.Visibility, SharedWith: appliedTo}
+	err = s.PolicyService.Authorize(subject, domain.ReadStudentProfile, profile)
+	if err != nil {
+		return domain.Student{}, err
+	}
+
+	if !student.ResumeOnRequest {
+		return student, nil
+	}
+
+	grantedTo, err := s.grantedCompanies(student.ID)
+	if err != nil {
+		return domain.Student{}, err
+	}
+
+	resume := domain.Resource{OwnerID: student.ID, SharedWith: grantedTo}
+	if s.PolicyService.Authorize(subject, domain.ReadStudentResume, resume) != nil {
+		student.Resume = ""
+	}
+
+	return student, nil
+}
+
+// Search finds a page of the students matching the provided query, that the
+// representative may view, along with the id to continue after for the next
+// page. Resumes of students that only share them on request are left out.
+func (s StudentService) Search(query domain.StudentQuery, page domain.Page, representativeID string) ([]domain.Student, string, error) {
+	subject := s.PolicyService.Resolve(domain.Subject{ID: representativeID, Role: domain.RepresentativeRole})
+	if subject.CompanyID == "" {
+		return []domain.Student{}, "", e.ErrorForbidden
+	}
+
+	query.VisibleTo = subject.CompanyID
+	students, next, err := s.StudentRepo.FindByQuery(query, page)
+	if err != nil {
+		return []domain.Student{}, "", err
+	}
+
+	for i := range students {
+		students[i] = students[i].WithoutResume()
+	}
+
+	return students, next, nil
+}
+
+// ChangePrivacy changes which representatives may view the profile of the
+// student, and if their resume is only shared on request
+func (s StudentService) ChangePrivacy(studentID string, visibility domain.Visibility, resumeOnRequest bool) error {
+	if !domain.IsValidVisibility(visibility) {
+		return e.Invalid("visibility", "doesn't exist")
+	}
+
+	_, err := s.StudentRepo.FindByID(studentID)
+	if err != nil {
+		return err
+	}
+
+	return s.StudentRepo.UpdatePrivacy(studentID, visibility, resumeOnRequest)
+}
+
+// RequestResume asks the student to share their resume with the company of the
+// representative. Only representatives that may view the profile can ask for it.
+func (s StudentService) RequestResume(studentID, representativeID string) error {
+	subject := s.PolicyService.Resolve(domain.Subject{ID: representativeID, Role: domain.RepresentativeRole})
+	student, err := s.FindVisible(studentID, subject)
+	if err != nil {
+		return err
+	}
+
+	if !student.ResumeOnRequest {
+		return e.New(e.Conflict, "resume of the student is already shared")
+	}
+
+	request, err := domain.NewResumeRequest(student.ID, subject.CompanyID, representativeID)
+	if err != nil {
+		return err
+	}
+
+	return s.ResumeRequestRepo.Create(request)
+}
+
+// FindResumeRequests finds all companies that asked for the resume of the student
+func (s StudentService) FindResumeRequests(studentID string) ([]domain.ResumeRequest, error) {
+	requests, err := s.ResumeRequestRepo.FindByStudent(studentID)
 	if err != nil {
-		return []domain.Student{}, err
+		return []domain.ResumeRequest{}, err
+	}
+
+	return requests, nil
+}
+
+// GrantResume shares the resume of the student with the company that asked for it
+func (s StudentService) GrantResume(studentID, companyID string) error {
+	return s.ResumeRequestRepo.Grant(studentID, companyID, time.Now())
+}
+
+// DeclineResume declines the request of the company for the resume of the
+// student, or stops sharing it with the company if it has been granted before
+func (s StudentService) DeclineResume(studentID, companyID string) error {
+	return s.ResumeRequestRepo.Delete(studentID, companyID)
+}
+
+// helper func that finds the ids of the companies the student applied to
+func (s StudentService) appliedCompanies(studentID string) ([]string, error) {
+	applications, err := s.ApplicationRepo.FindByStudent(studentID)
+	if err != nil {
+		return []string{}, err
+	}
+
+	companies := []string{}
+	for _, application := range applications {
+		project, err := s.ProjectRepo.FindByID(application.ProjectID)
+		if err != nil {
+			continue
+		}
+
+		companies = append(companies, project.CompanyID)
+	}
+
+	return companies, nil
+}
+
+// helper func that finds the ids of the companies the student shares their resume with
+func (s StudentService) grantedCompanies(studentID string) ([]string, error) {
+	requests, err := s.ResumeRequestRepo.FindByStudent(studentID)
+	if err != nil {
+		return []string{}, err
+	}
+
+	companies := []string{}
+	for _, request := range requests {
+		if request.IsGranted() {
+			companies = append(companies, request.CompanyID)
+		}
 	}
 
-	return students, nil
+	return companies, nil
 }
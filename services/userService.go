@@ -1,16 +1,31 @@
 package services
 
 import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/janabe/cscoupler/domain"
 	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/util"
 )
 
+// passwordResetValidFor is how long a password reset token can be used
+const passwordResetValidFor = time.Hour
+
+// emailVerificationValidFor is how long an email verification token can be used
+const emailVerificationValidFor = 24 * time.Hour
+
 // UserService struct, containing all features
 // the app supports regaring users
 type UserService struct {
-	UserRepo domain.UserRepository
+	UserRepo   domain.UserRepository
+	TokenRepo  domain.UserTokenRepository
+	Mailer     domain.Mailer
+	UnitOfWork domain.UnitOfWork
+	ClientURL  string // base url of the client, used in links sent by mail
 }
 
 // Register registers a user
@@ -23,6 +38,12 @@ func (u UserService) Register(user domain.User) error {
 	return err
 }
 
+// FindByID finds a user based on id
+func (u UserService) FindByID(id string) (domain.User, error) {
+	user, err := u.UserRepo.FindByID(id)
+	return user, err
+}
+
 // FindByEmail finds a user based on email
 func (u UserService) FindByEmail(email string) (domain.User, error) {
 	user, err := u.UserRepo.FindByEmail(email)
@@ -60,3 +81,160 @@ func (u UserService) FindRoleID(user domain.User) (string, error) {
 	roleID, err := u.UserRepo.FindRoleID(user)
 	return roleID, err
 }
+
+// RequestPasswordReset mails a link to reset the password to the user with
+// the provided email. No error is returned when there is no such user, so
+// this can't be used to find out which emails have an account.
+func (u UserService) RequestPasswordReset(email string) error {
+	user, err := u.FindByEmail(strings.ToLower(email))
+	if err != nil {
+		return nil
+	}
+
+	token, err := util.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	userToken, err := domain.NewUserToken(uuid.New().String(), user.ID, domain.PasswordReset, token, passwordResetValidFor)
+	if err != nil {
+		return err
+	}
+
+	err = u.TokenRepo.Create(userToken)
+	if err != nil {
+		return err
+	}
+
+	return u.Mailer.Send(domain.Mail{
+		To:      user.Email,
+		Subject: "Reset your cscoupler password",
+		Body: "Someone requested to reset the password of your cscoupler account.\n" +
+			"Use the following link within an hour to choose a new password:\n\n" +
+			u.ClientURL + "/reset-password?token=" + token + "\n\n" +
+			"If this wasn't you, you can ignore this mail.",
+	})
+}
+
+// ResetPassword sets the password of the user the reset token was sent to.
+// The token can only be used once, and all sessions of the user are revoked
+// so every device has to sign in with the new password, all in one unit of work.
+func (u UserService) ResetPassword(token, password string) error {
+	userToken, err := u.findToken(token, domain.PasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := domain.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return u.UnitOfWork.Do(func(repos domain.Repositories) error {
+		err := repos.UserTokens.MarkUsed(userToken.ID)
+		if err != nil {
+			return err
+		}
+
+		err = repos.Users.UpdatePassword(userToken.UserID, hash)
+		if err != nil {
+			return err
+		}
+
+		return repos.Sessions.RevokeByUser(userToken.UserID, time.Now())
+	})
+}
+
+// SendVerification mails a link to verify their email to the user
+func (u UserService) SendVerification(user domain.User) error {
+	if user.EmailVerified {
+		return nil
+	}
+
+	token, err := util.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	userToken, err := domain.NewUserToken(uuid.New().String(), user.ID, domain.EmailVerification, token, emailVerificationValidFor)
+	if err != nil {
+		return err
+	}
+
+	err = u.TokenRepo.Create(userToken)
+	if err != nil {
+		return err
+	}
+
+	return u.Mailer.Send(domain.Mail{
+		To:      user.Email,
+		Subject: "Verify your cscoupler email",
+		Body: "Welcome to cscoupler!\n" +
+			"Use the following link within a day to verify your email:\n\n" +
+			u.ClientURL + "/verify-email?token=" + token + "\n\n" +
+			"If you didn't create an account, you can ignore this mail.",
+	})
+}
+
+// ResendVerification mails a new verification link
+// to the user with the provided id
+func (u UserService) ResendVerification(userID string) error {
+	user, err := u.FindByID(userID)
+	if err != nil {
+		return e.ErrorEntityNotFound
+	}
+
+	return u.SendVerification(user)
+}
+
+// VerifyEmail marks the email the verification token was sent to
+// as verified. The token can only be used once, so both happen in one unit of work.
+func (u UserService) VerifyEmail(token string) error {
+	userToken, err := u.findToken(token, domain.EmailVerification)
+	if err != nil {
+		return err
+	}
+
+	return u.UnitOfWork.Do(func(repos domain.Repositories) error {
+		err := repos.UserTokens.MarkUsed(userToken.ID)
+		if err != nil {
+			return err
+		}
+
+		return repos.Users.MarkEmailVerified(userToken.UserID)
+	})
+}
+
+// IsEmailVerified checks if the user with the provided id has verified their email
+func (u UserService) IsEmailVerified(userID string) bool {
+	user, err := u.FindByID(userID)
+	if err != nil {
+		return false
+	}
+
+	return user.EmailVerified
+}
+
+// helper func that finds the token the user got for the purpose. It returns
+// e.ErrorTokenExpired if the token has expired, and e.ErrorInvalidToken if it
+// doesn't exist, has been used already or has been given for another purpose.
+func (u UserService) findToken(token string, purpose domain.TokenPurpose) (domain.UserToken, error) {
+	userToken, err := u.TokenRepo.FindByHash(domain.HashToken(token))
+	if e.Is(err, e.ErrorEntityNotFound) {
+		return domain.UserToken{}, e.ErrorInvalidToken
+	}
+
+	if err != nil {
+		return domain.UserToken{}, err
+	}
+
+	if userToken.Purpose != purpose || userToken.HasBeenUsed() {
+		return domain.UserToken{}, e.ErrorInvalidToken
+	}
+
+	if userToken.HasExpired() {
+		return domain.UserToken{}, e.ErrorTokenExpired
+	}
+
+	return userToken, nil
+}
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+// inviter is the subject of the owner of the company the invitations are for
+var inviter = domain.Subject{UserID: "u-r1", ID: "r1", Role: domain.RepresentativeRole}
+
+func newInviteLinkService() (services.InviteLinkService, memory.OutboxRepo) {
+	store := memory.NewStore()
+	representative := domain.Representative{ID: "r1", CompanyID: "c1", CompanyRole: domain.CompanyOwner}
+	representative.User = domain.User{ID: "u-r1", FirstName: "jan", LastName: "jansen", Email: "r1@example.com", Role: domain.RepresentativeRole}
+	mustSucceed(memory.CompanyRepo{Store: store}.Create(domain.Company{
+		ID:              "c1",
+		Name:            "acme",
+		Representatives: []domain.Representative{representative},
+	}))
+
+	userService := services.UserService{UserRepo: memory.UserRepo{Store: store}}
+	representativeService := services.RepresentativeService{
+		RepresentativeRepo: memory.RepresentativeRepo{Store: store},
+		UserService:        userService,
+	}
+
+	i := services.InviteLinkService{
+		InviteLinkRepo: memory.InviteLinkRepo{Store: store},
+		UserService:    userService,
+		ClientURL:      "http://localhost:8080",
+	}
+	i.RegisterKind(services.RepresentativeInvitations{
+		CompanyService: services.CompanyService{CompanyRepo: memory.CompanyRepo{Store: store}},
+		PolicyService:  services.PolicyService{RepresentativeService: representativeService},
+	})
+
+	return i, memory.OutboxRepo{Store: store}
+}
+
+// helper func that claims the mails that are due in the outbox
+func dueMails(t *testing.T, outbox memory.OutboxRepo) []domain.OutboxMail {
+	mails, err := outbox.ClaimDue(time.Now(), time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mails
+}
+
+func TestInviteByEmail(t *testing.T) {
+	i, outbox := newInviteLinkService()
+	request := services.InviteRequest{Kind: domain.RepresentativeInvite, TargetID: "c1", Email: "new@example.com"}
+	inviteLink, err := i.Create(inviter, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mails := dueMails(t, outbox)
+	if len(mails) != 1 || mails[0].To != "new@example.com" || mails[0].Data["Target"] != "acme" {
+		t.Fatalf("mails = %+v, want the invitation to acme mailed to new@example.com", mails)
+	}
+
+	if _, err := i.FindUsable(inviteLink.ID, domain.RepresentativeInvite, "c1", "NEW@example.com"); err != nil {
+		t.Errorf("invitation should be usable by the invitee: %v", err)
+	}
+
+	if _, err := i.FindUsable(inviteLink.ID, domain.RepresentativeInvite, "c1", "other@example.com"); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("invitation used by someone else = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	// representatives have to be invited by email
+	request.Email = ""
+	if _, err := i.Create(inviter, request); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("open invitation = %v, want %v", err, e.ErrorForbidden)
+	}
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	i, _ := newInviteLinkService()
+	inviteLink, err := i.Create(inviter, services.InviteRequest{Kind: domain.RepresentativeInvite, TargetID: "c1", Email: "new@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.Revoke(inviteLink.ID, "someone else"); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("revoke by someone else = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := i.Revoke(inviteLink.ID, inviter.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := i.FindUsable(inviteLink.ID, domain.RepresentativeInvite, "c1", "new@example.com"); !e.Is(err, e.ErrorInviteNotPending) {
+		t.Errorf("revoked invitation = %v, want %v", err, e.ErrorInviteNotPending)
+	}
+
+	// revoked invitations can't be made valid again
+	if _, err := i.Resend(inviteLink.ID, inviter); !e.Is(err, e.ErrorInviteNotPending) {
+		t.Errorf("resend of revoked invitation = %v, want %v", err, e.ErrorInviteNotPending)
+	}
+}
+
+func TestResendExpiredInvitation(t *testing.T) {
+	i, outbox := newInviteLinkService()
+	inviteLink, err := domain.NewInviteLink("i1", domain.RepresentativeInvite, "c1", domain.RepresentativeRole,
+		"/signup/representatives/invite/c1/i1", "new@example.com", inviter.ID, 1, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSucceed(i.InviteLinkRepo.Create(inviteLink))
+
+	if _, err := i.FindUsable(inviteLink.ID, domain.RepresentativeInvite, "c1", "new@example.com"); !e.Is(err, e.ErrorInviteExpired) {
+		t.Fatalf("expired invitation = %v, want %v", err, e.ErrorInviteExpired)
+	}
+
+	if _, err := i.Resend(inviteLink.ID, domain.Subject{ID: "r2", Role: domain.RepresentativeRole}); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("resend by someone else = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if _, err := i.Resend(inviteLink.ID, inviter); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := i.FindUsable(inviteLink.ID, domain.RepresentativeInvite, "c1", "new@example.com"); err != nil {
+		t.Errorf("resent invitation should be usable again: %v", err)
+	}
+
+	if mails := dueMails(t, outbox); len(mails) != 1 || mails[0].To != "new@example.com" {
+		t.Errorf("mails = %+v, want the invitation mailed again", mails)
+	}
+}
+
+// teamInvitations is a kind of invitations that can be open,
+// inviting students to join a team of the inviter
+type teamInvitations struct{}
+
+func (teamInvitations) Kind() domain.InviteKind { return "team" }
+func (teamInvitations) Role() string            { return domain.StudentRole }
+func (teamInvitations) SignupPath() string      { return "/signup/students/team/" }
+func (teamInvitations) AllowsOpenInvites() bool { return true }
+func (teamInvitations) Target(inviter domain.Subject, teamID string) (string, error) {
+	if teamID != "team-of-"+inviter.ID {
+		return "", e.ErrorForbidden
+	}
+
+	return "the team of " + inviter.ID, nil
+}
+
+func TestInvitationKinds(t *testing.T) {
+	i, outbox := newInviteLinkService()
+	if _, err := i.Create(inviter, services.InviteRequest{Kind: "team", TargetID: "team-of-r1"}); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Fatalf("invitation of an unknown kind = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+
+	i.RegisterKind(teamInvitations{})
+	if _, err := i.Create(inviter, services.InviteRequest{Kind: "team", TargetID: "team-of-r2"}); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("invitation to a target of someone else = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	inviteLink, err := i.Create(inviter, services.InviteRequest{Kind: "team", TargetID: "team-of-r1", MaxUses: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if inviteLink.Role != domain.StudentRole || inviteLink.URL != "/signup/students/team/team-of-r1/"+inviteLink.ID {
+		t.Errorf("invitation = %+v, want the role and signup path of its kind", inviteLink)
+	}
+
+	if mails := dueMails(t, outbox); len(mails) != 0 {
+		t.Errorf("open invitation should not be mailed, got %+v", mails)
+	}
+
+	// invitations are only found for their own kind and target
+	if _, err := i.FindUsable(inviteLink.ID, domain.RepresentativeInvite, "team-of-r1", ""); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("invitation of another kind = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+
+	if _, err := i.FindUsable(inviteLink.ID, "team", "team-of-r2", ""); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("invitation to another target = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+
+	// open invitations can be used by anyone, as often as allowed
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		if _, err := i.FindUsable(inviteLink.ID, "team", "team-of-r1", email); err != nil {
+			t.Fatalf("open invitation should be usable by %s: %v", email, err)
+		}
+
+		mustSucceed(i.InviteLinkRepo.Redeem(inviteLink.ID, time.Now()))
+	}
+
+	if _, err := i.FindUsable(inviteLink.ID, "team", "team-of-r1", "third@example.com"); !e.Is(err, e.ErrorInviteNotPending) {
+		t.Errorf("used up invitation = %v, want %v", err, e.ErrorInviteNotPending)
+	}
+}
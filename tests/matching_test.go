@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+func newMatchingService() services.MatchingService {
+	store := memory.NewStore()
+
+	students := memory.StudentRepo{Store: store}
+	for _, student := range []domain.Student{
+		{ID: "s1", University: "tu delft", Skills: []string{"Golang", "Postgres", "Docker"}, Wishes: "I want to build backend services"},
+		{ID: "s2", University: "uva", Skills: []string{"Vue.js", "CSS"}, Wishes: "frontend design"},
+		{ID: "s3", University: "vu", Skills: []string{"cooking"}, Wishes: "gardening"},
+	} {
+		student.User = domain.User{ID: "u-" + student.ID, Email: student.ID + "@example.com", Role: domain.StudentRole}
+		mustSucceed(students.Create(student))
+	}
+
+	companies := memory.CompanyRepo{Store: store}
+	for _, company := range []domain.Company{
+		{ID: "c1", Representatives: []domain.Representative{{ID: "r1", CompanyID: "c1"}}},
+		{ID: "c2", Representatives: []domain.Representative{{ID: "r2", CompanyID: "c2"}}},
+	} {
+		repr := &company.Representatives[0]
+		repr.User = domain.User{ID: "u-" + repr.ID, Email: repr.ID + "@example.com", Role: domain.RepresentativeRole}
+		mustSucceed(companies.Create(company))
+	}
+
+	for _, project := range []domain.Project{
+		{ID: "p1", CompanyID: "c1", Description: "Build backend services in go for students of tu delft", Recommendations: []string{"go", "postgresql"}},
+		{ID: "p2", CompanyID: "c1", Description: "Redesign our frontend", Recommendations: []string{"vue", "css", "javascript"}},
+		{ID: "p3", CompanyID: "c2", Description: "Support the sales team", Recommendations: []string{"excel"}},
+	} {
+		mustSucceed(companies.AddProject(project))
+	}
+
+	return services.MatchingService{
+		StudentRepo: students,
+		ProjectRepo: memory.ProjectRepo{Store: store},
+		PolicyService: services.PolicyService{
+			RepresentativeService: services.RepresentativeService{RepresentativeRepo: memory.RepresentativeRepo{Store: store}},
+		},
+	}
+}
+
+// helper func that stops the test setup when storing its data fails
+func mustSucceed(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestNormalizeSkill(t *testing.T) {
+	cases := map[string]string{
+		"Golang":            "go",
+		"  JS ":             "javascript",
+		"Node.js":           "nodejs",
+		"Machine  Learning": "machine learning",
+		"rust":              "rust",
+	}
+
+	for skill, want := range cases {
+		if got := domain.NormalizeSkill(skill); got != want {
+			t.Errorf("NormalizeSkill(%q) = %q, want %q", skill, got, want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	project := domain.Project{
+		ID:              "p1",
+		Description:     "Build backend services in go and postgres for students of tu delft",
+		Recommendations: []string{"go", "postgresql"},
+	}
+
+	perfect := domain.Student{
+		ID:         "s1",
+		University: "TU Delft",
+		Skills:     []string{"golang", "postgres"},
+		Wishes:     "backend services",
+	}
+
+	match := domain.Score(perfect, project)
+	if match.Score < 0.999 || match.Score > 1.001 {
+		t.Errorf("expected a perfect score, got %v", match.Score)
+	}
+
+	if len(match.MatchedSkills) != 2 {
+		t.Errorf("expected 2 matched skills, got %v", match.MatchedSkills)
+	}
+
+	partial := domain.Student{ID: "s2", Skills: []string{"go", "java"}}
+	if score := domain.Score(partial, project).Score; score <= 0 || score >= match.Score {
+		t.Errorf("expected a partial score, got %v", score)
+	}
+
+	none := domain.Student{ID: "s3", Skills: []string{"cooking"}, Wishes: "gardening"}
+	if score := domain.Score(none, project).Score; score != 0 {
+		t.Errorf("expected no score, got %v", score)
+	}
+}
+
+func TestMatchesForProject(t *testing.T) {
+	m := newMatchingService()
+
+	matches, err := m.MatchesForProject("p1", "r1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matches) != 1 || matches[0].Student.ID != "s1" {
+		t.Fatalf("expected only student s1 to match, got %v", matches)
+	}
+
+	if _, err := m.MatchesForProject("p1", "r2", 0); err != e.ErrorForbidden {
+		t.Errorf("expected %v for a representative of another company, got %v", e.ErrorForbidden, err)
+	}
+
+	if _, err := m.MatchesForProject("unknown", "r1", 0); err != e.ErrorEntityNotFound {
+		t.Errorf("expected %v for an unknown project, got %v", e.ErrorEntityNotFound, err)
+	}
+}
+
+func TestRecommendedProjects(t *testing.T) {
+	m := newMatchingService()
+
+	matches, err := m.RecommendedProjects("s2", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matches) == 0 || matches[0].Project.ID != "p2" {
+		t.Fatalf("expected project p2 to be recommended first, got %v", matches)
+	}
+
+	matches, err = m.RecommendedProjects("s1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matches) != 1 || matches[0].Project.ID != "p1" {
+		t.Fatalf("expected only project p1 to be recommended, got %v", matches)
+	}
+
+	if _, err := m.RecommendedProjects("unknown", 0); err != e.ErrorEntityNotFound {
+		t.Errorf("expected %v for an unknown student, got %v", e.ErrorEntityNotFound, err)
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/janabe/cscoupler/database/contract"
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+)
+
+// TestMemoryBackend runs the contracts against the repositories
+// the server creates when the memory storage is selected
+func TestMemoryBackend(t *testing.T) {
+	contract.Run(t, func(t *testing.T) contract.Repos {
+		store := memory.NewStore()
+		repos := memory.NewRepositories(store)
+		return contract.Repos{
+			Users:           repos.Users,
+			Students:        repos.Students,
+			Representatives: repos.Representatives,
+			Companies:       repos.Companies,
+			Projects:        repos.Projects,
+			InviteLinks:     repos.InviteLinks,
+			Conversations:   repos.Conversations,
+			Messages:        repos.Messages,
+			Blocks:          repos.Blocks,
+			Applications:    repos.Applications,
+			Shortlist:       repos.Shortlist,
+			ResumeRequests:  repos.ResumeRequests,
+			Sessions:        repos.Sessions,
+			UserTokens:      repos.UserTokens,
+			Outbox:          repos.Outbox,
+			UnitOfWork:      memory.UnitOfWork{Store: store},
+		}
+	})
+}
+
+func TestMemoryStoresCopies(t *testing.T) {
+	students := memory.StudentRepo{Store: memory.NewStore()}
+
+	student := domain.Student{ID: "s1", Skills: []string{"go"}}
+	student.User = domain.User{ID: "u-s1", Email: "s1@example.com", Role: domain.StudentRole}
+	mustSucceed(students.Create(student))
+	student.Skills[0] = "changed before reading"
+
+	found, err := students.FindByID("s1")
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	found.Skills[0] = "changed after reading"
+
+	found, err = students.FindByID("s1")
+	if err != nil {
+		t.Fatalf("FindByID() failed: %v", err)
+	}
+	if got := found.Skills[0]; got != "go" {
+		t.Errorf("stored skill = %q, want %q", got, "go")
+	}
+}
+
+func TestMemoryUnitOfWorkSeesOwnChanges(t *testing.T) {
+	store := memory.NewStore()
+	uow := memory.UnitOfWork{Store: store}
+	failure := e.New(e.Conflict, "stop")
+
+	err := uow.Do(func(repos domain.Repositories) error {
+		student := domain.Student{ID: "s1"}
+		student.User = domain.User{ID: "u-s1", Email: "s1@example.com", Role: domain.StudentRole}
+		if err := repos.Students.Create(student); err != nil {
+			return err
+		}
+
+		if _, err := repos.Users.FindByEmail("s1@example.com"); err != nil {
+			t.Errorf("user of the student not found within the unit of work: %v", err)
+		}
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("Do() = %v, want the error of the use case", err)
+	}
+
+	if _, err := (memory.UserRepo{Store: store}).FindByEmail("s1@example.com"); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("FindByEmail() after rollback = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+}
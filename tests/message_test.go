@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+func newMessageService() services.MessageService {
+	store := memory.NewStore()
+
+	for _, id := range []string{"s1", "s2"} {
+		student := domain.Student{ID: id, User: domain.User{ID: "u-" + id, Email: id + "@example.com", Role: domain.StudentRole}}
+		mustSucceed(memory.StudentRepo{Store: store}.Create(student))
+	}
+
+	companies := memory.CompanyRepo{Store: store}
+	for _, company := range []domain.Company{
+		{ID: "c1", Representatives: []domain.Representative{{ID: "r1", CompanyID: "c1"}}},
+		{ID: "c2", Representatives: []domain.Representative{{ID: "r2", CompanyID: "c2"}}},
+	} {
+		repr := &company.Representatives[0]
+		repr.User = domain.User{ID: "u-" + repr.ID, Email: repr.ID + "@example.com", Role: domain.RepresentativeRole}
+		mustSucceed(companies.Create(company))
+		mustSucceed(companies.AddProject(domain.Project{ID: "p-" + company.ID, CompanyID: company.ID}))
+	}
+
+	userService := services.UserService{UserRepo: memory.UserRepo{Store: store}}
+	return services.MessageService{
+		MessageRepo:      memory.MessageRepo{Store: store},
+		ConversationRepo: memory.ConversationRepo{Store: store},
+		BlockRepo:        memory.BlockRepo{Store: store},
+		UserService:      userService,
+		ProjectService:   services.ProjectService{ProjectRepo: memory.ProjectRepo{Store: store}},
+		RepresentativeService: services.RepresentativeService{
+			RepresentativeRepo: memory.RepresentativeRepo{Store: store},
+			UserService:        userService,
+		},
+		UnitOfWork: memory.UnitOfWork{Store: store},
+	}
+}
+
+func TestSendAboutProject(t *testing.T) {
+	m := newMessageService()
+
+	send := func(sender, receiver, projectID string) error {
+		message, err := domain.NewMessage("m-"+sender+"-"+projectID, sender, receiver, "hello", projectID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = m.Send(message)
+		return err
+	}
+
+	if err := send("u-r1", "u-s1", "p-c1"); err != nil {
+		t.Errorf("representative should be able to send a message about a project of their company: %v", err)
+	}
+
+	if err := send("u-r1", "u-s1", "p-c2"); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("message about a project of another company = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := send("u-s1", "u-r2", "p-c1"); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("student messaging about a project of another company = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := send("u-r1", "u-s1", "unknown"); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("message about an unknown project = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+
+	inbox, err := m.Inbox("u-s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inbox) != 1 {
+		t.Errorf("only the allowed message should have started a conversation, got %d", len(inbox))
+	}
+}
+
+func TestStartConversationLimit(t *testing.T) {
+	m := newMessageService()
+	m.Policy = services.MessagePolicy{MaxConversationsPerRepresentative: 1, Window: time.Hour}
+
+	sent := 0
+	send := func(receiver string) error {
+		sent++
+		message, err := domain.NewMessage(fmt.Sprint("m", sent), "u-r1", receiver, "hello", "p-c1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = m.Send(message)
+		return err
+	}
+
+	if err := send("u-s1"); err != nil {
+		t.Fatalf("first conversation should be started: %v", err)
+	}
+
+	// messages in a conversation that has been started don't count
+	if err := send("u-s1"); err != nil {
+		t.Errorf("message in the started conversation = %v, want no error", err)
+	}
+
+	if err := send("u-s2"); !e.Is(err, e.ErrorRateLimited) {
+		t.Errorf("conversation over the limit = %v, want %v", err, e.ErrorRateLimited)
+	}
+
+	inbox, err := m.Inbox("u-s2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inbox) != 0 {
+		t.Errorf("conversation over the limit should not be stored, got %d", len(inbox))
+	}
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/janabe/cscoupler/database/postgres/migrations"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := migrations.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) == 0 {
+		t.Fatal("no migrations have been embedded, run go generate")
+	}
+
+	for i, migration := range all {
+		if i > 0 && migration.Version <= all[i-1].Version {
+			t.Errorf("migration %d comes after %d", migration.Version, all[i-1].Version)
+		}
+
+		if migration.Name == "" || migration.Up == "" || migration.Down == "" {
+			t.Errorf("migration %d should have a name, an up and a down file", migration.Version)
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest := migrator.Migrations[len(migrator.Migrations)-1].Version
+	assertVersion := func(want int) {
+		t.Helper()
+		version, err := migrator.Version()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if version != want {
+			t.Fatalf("Version = %d, want %d", version, want)
+		}
+	}
+
+	if err := migrator.To(0); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(0)
+
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(latest)
+
+	// applying again changes nothing
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	assertVersion(latest)
+
+	if err := migrator.Down(); err != nil {
+		t.Fatal(err)
+	}
+	if len(migrator.Migrations) > 1 {
+		assertVersion(migrator.Migrations[len(migrator.Migrations)-2].Version)
+	} else {
+		assertVersion(0)
+	}
+
+	if err := migrator.To(latest); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, status := range statuses {
+		if !status.IsApplied() {
+			t.Errorf("migration %d should have been applied", status.Migration.Version)
+		}
+	}
+
+	if err := migrator.To(latest + 1); err == nil {
+		t.Error("migrating to an unknown version should fail")
+	}
+}
+
+func TestMigrateFromBaseline(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create the database as the former initdb.sql did, without recording a version
+	if err := migrator.To(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(migrator.Migrations[0].Up); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		studentUser  = "00000000-0000-0000-0000-000000000001"
+		reprUser     = "00000000-0000-0000-0000-000000000002"
+		companyID    = "00000000-0000-0000-0000-000000000003"
+		reprID       = "00000000-0000-0000-0000-000000000004"
+		inviteLinkID = "00000000-0000-0000-0000-000000000005"
+	)
+
+	for _, query := range []string{
+		`INSERT INTO "User" VALUES ('` + studentUser + `', 'Jan', 'Jansen', 'jan@example.com', 'hash', 'student');`,
+		`INSERT INTO "User" VALUES ('` + reprUser + `', 'Piet', 'Pieters', 'piet@example.com', 'hash', 'representative');`,
+		`INSERT INTO "Company" VALUES ('` + companyID + `', 'Acme', 'information', 'description');`,
+		`INSERT INTO "Representative" VALUES ('` + reprID + `', 'recruiter', '` + reprUser + `', '` + companyID + `');`,
+		`INSERT INTO "Message" (message_id, created_at, sender, receiver, body) VALUES
+			('00000000-0000-0000-0000-000000000006', now() - interval '1 hour', '` + reprUser + `', '` + studentUser + `', 'hello'),
+			('00000000-0000-0000-0000-000000000007', now(), '` + studentUser + `', '` + reprUser + `', 'hi');`,
+		`INSERT INTO "Invite_Link" VALUES ('` + inviteLinkID + `', '/signup/representatives/invite/` + companyID + `/` + inviteLinkID + `',
+			now(), now() + interval '1 day', TRUE, '` + reprID + `');`,
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := map[string][]string{
+		"User":                     {"user_id", "first_name", "last_name", "email", "hashed_password", "role", "email_verified"},
+		"Company":                  {"company_id", "name", "information", "description", "search_vector"},
+		"Student":                  {"student_id", "university", "city", "skills", "experiences", "short_experiences", "wishes", "status", "resume", "ref_user", "visibility", "resume_on_request"},
+		"Representative":           {"representative_id", "job_title", "ref_user", "ref_company", "company_role", "removed_at"},
+		"Project":                  {"project_id", "description", "compensation", "duration", "recommendations", "ref_company", "ref_representative", "search_vector"},
+		"Conversation":             {"conversation_id", "created_at", "last_activity", "started_by", "ref_project"},
+		"Conversation_Participant": {"ref_conversation", "ref_user", "read_at"},
+		"Message":                  {"message_id", "ref_conversation", "created_at", "sender", "receiver", "body", "ref_project"},
+		"Address":                  {"address_id", "street", "zipcode", "city", "number", "ref_company"},
+		"Invite_Link":              {"invite_link_id", "kind", "target_id", "role", "url", "email", "created_at", "expiry_date", "max_uses", "uses", "created_by", "revoked_at"},
+		"Block":                    {"ref_student", "ref_company", "created_at"},
+		"Application":              {"application_id", "ref_student", "ref_project", "motivation", "resume", "status", "created_at", "updated_at"},
+		"Shortlist_Entry":          {"shortlist_entry_id", "ref_company", "ref_student", "ref_project", "notes", "tags", "added_by", "created_at", "updated_at"},
+		"Resume_Request":           {"ref_student", "ref_company", "requested_by", "requested_at", "granted_at"},
+		"Session":                  {"session_id", "family_id", "ref_user", "token_hash", "created_at", "expires_at", "used_at", "revoked_at"},
+		"User_Token":               {"user_token_id", "ref_user", "purpose", "token_hash", "created_at", "expiry_date", "used"},
+		"Mail_Outbox":              {"mail_id", "event", "recipient", "data", "attempts", "last_error", "created_at", "next_attempt_at", "sent_at"},
+	}
+
+	for table, want := range schema {
+		rows, err := db.Query(`SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1;`, table)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		columns := []string{}
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				t.Fatal(err)
+			}
+			columns = append(columns, column)
+		}
+		rows.Close()
+
+		sort.Strings(columns)
+		sort.Strings(want)
+		if strings.Join(columns, ",") != strings.Join(want, ",") {
+			t.Errorf("columns of %s = %v, want %v", table, columns, want)
+		}
+	}
+
+	// the messages are grouped into a single conversation between both users
+	var conversations, participants int
+	err = db.QueryRow(`SELECT count(DISTINCT ref_conversation) FROM "Message" WHERE ref_conversation IS NOT NULL;`).Scan(&conversations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.QueryRow(`SELECT count(*) FROM "Conversation_Participant";`).Scan(&participants)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conversations != 1 || participants != 2 {
+		t.Errorf("expected 1 conversation with 2 participants, got %d with %d", conversations, participants)
+	}
+
+	// the used invitelink is converted into a used up invitation of the company
+	var targetID string
+	var uses int
+	err = db.QueryRow(`SELECT target_id, uses FROM "Invite_Link" WHERE invite_link_id = $1;`, inviteLinkID).Scan(&targetID, &uses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if targetID != companyID || uses != 1 {
+		t.Errorf("invitation has target %q and %d uses, want %q and 1", targetID, uses, companyID)
+	}
+
+	// all migrations can be undone again
+	if err := migrator.To(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+}
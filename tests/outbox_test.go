@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	"github.com/janabe/cscoupler/mail"
+)
+
+func newMessageMail(id string, data map[string]string) domain.OutboxMail {
+	outboxMail, err := domain.NewOutboxMail(id, domain.NewMessageMail, id+"@example.com", data)
+	if err != nil {
+		panic(err)
+	}
+
+	return outboxMail
+}
+
+func TestRender(t *testing.T) {
+	rendered, err := mail.Render(newMessageMail("m1", map[string]string{
+		"Sender":   "Jan Jansen",
+		"Receiver": "Piet",
+		"Preview":  "<script>hello</script>",
+		"Link":     "http://localhost:8080/messages/c1",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rendered.To != "m1@example.com" || rendered.Subject != "New message from Jan Jansen" {
+		t.Errorf("rendered mail = %+v, want it sent to m1 about the message of Jan Jansen", rendered)
+	}
+
+	if !strings.Contains(rendered.Body, "<script>hello</script>") || strings.Contains(rendered.HTML, "<script>") {
+		t.Errorf("expected the preview as is in the text, and escaped in the html, got %q", rendered.HTML)
+	}
+
+	if _, err := mail.Render(newMessageMail("m2", map[string]string{"Sender": "Jan Jansen"})); err == nil {
+		t.Error("rendering a mail missing data should fail")
+	}
+}
+
+func TestWorkerSendsDueMails(t *testing.T) {
+	outbox := memory.OutboxRepo{Store: memory.NewStore()}
+	mails := mailbox{mails: &[]domain.Mail{}}
+	worker := mail.Worker{Outbox: outbox, Mailer: mails}
+
+	mustSucceed(outbox.Enqueue(newMessageMail("m1", map[string]string{
+		"Sender": "Jan Jansen", "Receiver": "Piet", "Preview": "hello", "Link": "http://localhost:8080",
+	})))
+	mustSucceed(outbox.Enqueue(newMessageMail("m2", map[string]string{})))
+
+	sent, err := worker.SendDue()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != 1 || len(*mails.mails) != 1 || (*mails.mails)[0].To != "m1@example.com" {
+		t.Fatalf("SendDue = %d with mails %+v, want only the mail that can be rendered", sent, *mails.mails)
+	}
+
+	// the sent mail is done, the failed one waits before it is tried again
+	sent, err = worker.SendDue()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != 0 || len(*mails.mails) != 1 {
+		t.Errorf("second SendDue = %d, want no mails sent again", sent)
+	}
+}
+
+func TestFailedMailBacksOff(t *testing.T) {
+	outboxMail := newMessageMail("m1", nil)
+	at := time.Now()
+
+	outboxMail.Fail(errors.New("connection refused"), at)
+	first := outboxMail.NextAttemptAt.Sub(at)
+
+	outboxMail.Fail(errors.New("connection refused"), at)
+	second := outboxMail.NextAttemptAt.Sub(at)
+
+	if first <= 0 || second != 2*first {
+		t.Errorf("backoffs = %v, %v, want the second twice the first", first, second)
+	}
+
+	if outboxMail.Attempts != 2 || outboxMail.LastError != "connection refused" {
+		t.Errorf("failed mail = %+v, want 2 attempts and the last error", outboxMail)
+	}
+
+	for !outboxMail.IsDead() {
+		outboxMail.Fail(errors.New("connection refused"), at)
+	}
+
+	if backoff := outboxMail.NextAttemptAt.Sub(at); backoff > 6*time.Hour {
+		t.Errorf("backoff = %v, want it capped", backoff)
+	}
+}
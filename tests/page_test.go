@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	"github.com/janabe/cscoupler/handlers"
+	"github.com/janabe/cscoupler/services"
+)
+
+func TestNewPage(t *testing.T) {
+	cases := map[int]int{
+		-1:                      domain.DefaultPageLimit,
+		0:                       domain.DefaultPageLimit,
+		5:                       5,
+		domain.MaxPageLimit + 1: domain.MaxPageLimit,
+	}
+
+	for limit, want := range cases {
+		if got := domain.NewPage(limit, "").Limit; got != want {
+			t.Errorf("NewPage(%d).Limit = %d, want %d", limit, got, want)
+		}
+	}
+}
+
+func TestFetchCompaniesByCursor(t *testing.T) {
+	store := memory.NewStore()
+	companies := memory.CompanyRepo{Store: store}
+	for _, id := range []string{"c2", "c3", "c1"} {
+		mustSucceed(companies.Create(domain.Company{ID: id, Name: "company " + id}))
+	}
+
+	handler := handlers.CompanyHandler{CompanyService: services.CompanyService{CompanyRepo: companies}}
+	fetch := func(query string) (int, []string, string) {
+		rec := httptest.NewRecorder()
+		handler.FetchAllCompanies().ServeHTTP(rec, httptest.NewRequest("GET", "/companies/all?"+query, nil))
+
+		var page struct {
+			Items      []handlers.CompanyData `json:"items"`
+			NextCursor string                 `json:"nextCursor"`
+		}
+
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ids := []string{}
+		for _, company := range page.Items {
+			ids = append(ids, company.ID)
+		}
+
+		return rec.Code, ids, page.NextCursor
+	}
+
+	// the cursor of every page leads to the next one, until there is none
+	got := []string{}
+	cursor := ""
+	for i := 0; i < 3; i++ {
+		status, ids, next := fetch("limit=2&cursor=" + cursor)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want %d", status, http.StatusOK)
+		}
+
+		got = append(got, ids...)
+		cursor = next
+		if cursor == "" {
+			break
+		}
+	}
+
+	if want := []string{"c1", "c2", "c3"}; !equalStrings(got, want) || cursor != "" {
+		t.Errorf("companies of all pages = %v, %q, want %v without a cursor to continue", got, cursor, want)
+	}
+
+	for _, query := range []string{"cursor=no*cursor", "limit=-1", "limit=ten"} {
+		if status, _, _ := fetch(query); status != http.StatusBadRequest {
+			t.Errorf("status of %q = %d, want %d", query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+// helper func that checks if both slices contain the same strings in the same order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
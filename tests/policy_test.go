@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/handlers"
+	"github.com/janabe/cscoupler/services"
+)
+
+func TestIsAllowed(t *testing.T) {
+	student := domain.Subject{ID: "s1", Role: domain.StudentRole}
+	owner := domain.Subject{ID: "r1", Role: domain.RepresentativeRole, CompanyID: "c1", CompanyRole: domain.CompanyOwner}
+	outsider := domain.Subject{ID: "r2", Role: domain.RepresentativeRole, CompanyID: "c2", CompanyRole: domain.CompanyOwner}
+
+	profile := domain.Resource{OwnerID: "s1"}
+	project := domain.Resource{CompanyID: "c1"}
+
+	cases := []struct {
+		subject  domain.Subject
+		action   domain.Action
+		resource domain.Resource
+		want     bool
+	}{
+		{student, domain.EditStudentProfile, profile, true},
+		{domain.Subject{ID: "s2", Role: domain.StudentRole}, domain.EditStudentProfile, profile, false},
+		{owner, domain.EditStudentProfile, profile, false},
+		{owner, domain.ReadStudentProfile, profile, true},
+		{student, domain.DeleteProject, project, false},
+		{owner, domain.DeleteProject, project, true},
+		{outsider, domain.DeleteProject, project, false},
+		{outsider, domain.ReadApplications, project, false},
+		{domain.Subject{}, domain.ReadStudentProfile, profile, false},
+	}
+
+	for _, c := range cases {
+		if got := domain.IsAllowed(c.subject, c.action, c.resource); got != c.want {
+			t.Errorf("IsAllowed(%+v, %q, %+v) = %v, want %v", c.subject, c.action, c.resource, got, c.want)
+		}
+	}
+}
+
+func TestAuthorizeResolvesCompany(t *testing.T) {
+	store := memory.NewStore()
+	companies := memory.CompanyRepo{Store: store}
+	for _, id := range []string{"c1", "c2"} {
+		representative := domain.Representative{ID: "r-" + id, CompanyID: id, CompanyRole: domain.CompanyOwner}
+		representative.User = domain.User{ID: "u-" + representative.ID, Email: representative.ID + "@example.com", Role: domain.RepresentativeRole}
+		mustSucceed(companies.Create(domain.Company{ID: id, Representatives: []domain.Representative{representative}}))
+	}
+
+	policy := services.PolicyService{
+		RepresentativeService: services.RepresentativeService{RepresentativeRepo: memory.RepresentativeRepo{Store: store}},
+	}
+
+	project := domain.Resource{CompanyID: "c1"}
+	if err := policy.AuthorizeRepresentative("r-c1", domain.DeleteProject, project); err != nil {
+		t.Errorf("representative of the company owning the project = %v, want no error", err)
+	}
+
+	if err := policy.AuthorizeRepresentative("r-c2", domain.DeleteProject, project); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("representative of another company = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := policy.AuthorizeRepresentative("unknown", domain.DeleteProject, project); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("unknown representative = %v, want %v", err, e.ErrorForbidden)
+	}
+}
+
+func TestValidateRole(t *testing.T) {
+	store := memory.NewStore()
+	user := domain.User{ID: "u-s1", Email: "s1@example.com", Role: domain.StudentRole}
+	mustSucceed(memory.StudentRepo{Store: store}.Create(domain.Student{ID: "s1", User: user}))
+
+	sessions := services.SessionService{SessionRepo: memory.SessionRepo{Store: store}}
+	session, _, err := sessions.Start(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := handlers.AuthHandler{
+		JWTKey:         []byte("secret"),
+		UserService:    services.UserService{UserRepo: memory.UserRepo{Store: store}},
+		SessionService: sessions,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &handlers.Claims{
+		ID:             "s1",
+		Email:          user.Email,
+		UserID:         user.ID,
+		Role:           user.Role,
+		Session:        session.FamilyID,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()},
+	}).SignedString(auth.JWTKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(role string) int {
+		ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		rec := httptest.NewRecorder()
+		auth.Validate(role, ok).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if status := validate(domain.StudentRole); status != http.StatusOK {
+		t.Errorf("status for the role of the user = %d, want %d", status, http.StatusOK)
+	}
+
+	if status := validate(domain.RepresentativeRole); status != http.StatusForbidden {
+		t.Errorf("status for another role = %d, want %d", status, http.StatusForbidden)
+	}
+
+	if err := sessions.SignOut(session.FamilyID); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := validate(""); status != http.StatusUnauthorized {
+		t.Errorf("status after signing out = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+// newPrivacyService creates students that are visible to all representatives
+// (open), only to c1 they applied to (applied) and to no one (hidden).
+// Representative r1 works for c1, r2 for c2. Only applied shares their
+// resume on request.
+func newPrivacyService() services.StudentService {
+	store := memory.NewStore()
+	companies := memory.CompanyRepo{Store: store}
+	for _, id := range []string{"1", "2"} {
+		representative := domain.Representative{ID: "r" + id, CompanyID: "c" + id, CompanyRole: domain.CompanyOwner}
+		representative.User = domain.User{ID: "u-r" + id, Email: "r" + id + "@example.com", Role: domain.RepresentativeRole}
+		mustSucceed(companies.Create(domain.Company{ID: "c" + id, Representatives: []domain.Representative{representative}}))
+	}
+	mustSucceed(companies.AddProject(domain.Project{ID: "p1", CompanyID: "c1"}))
+
+	students := memory.StudentRepo{Store: store}
+	for _, student := range []domain.Student{
+		{ID: "open", Visibility: domain.VisibleToRepresentatives, Resume: "open.pdf"},
+		{ID: "applied", Visibility: domain.VisibleToAppliedCompanies, Resume: "applied.pdf", ResumeOnRequest: true},
+		{ID: "hidden", Visibility: domain.Hidden, Resume: "hidden.pdf"},
+	} {
+		student.User = domain.User{ID: "u-" + student.ID, Email: student.ID + "@example.com", Role: domain.StudentRole}
+		mustSucceed(students.Create(student))
+	}
+
+	application, err := domain.NewApplication("a1", "applied", "p1", "hire me", "")
+	if err != nil {
+		panic(err)
+	}
+	mustSucceed(memory.ApplicationRepo{Store: store}.Create(application))
+
+	return services.StudentService{
+		StudentRepo:       students,
+		ApplicationRepo:   memory.ApplicationRepo{Store: store},
+		ProjectRepo:       memory.ProjectRepo{Store: store},
+		ResumeRequestRepo: memory.ResumeRequestRepo{Store: store},
+		PolicyService: services.PolicyService{
+			RepresentativeService: services.RepresentativeService{RepresentativeRepo: memory.RepresentativeRepo{Store: store}},
+		},
+	}
+}
+
+// helper func that creates the subject of a representative
+func representative(id string) domain.Subject {
+	return domain.Subject{ID: id, Role: domain.RepresentativeRole}
+}
+
+func TestProfileVisibility(t *testing.T) {
+	s := newPrivacyService()
+	cases := []struct {
+		studentID string
+		subject   domain.Subject
+		visible   bool
+	}{
+		{"open", representative("r2"), true},
+		{"applied", representative("r1"), true},
+		{"applied", representative("r2"), false},
+		{"hidden", representative("r1"), false},
+		{"hidden", domain.Subject{ID: "hidden", Role: domain.StudentRole}, true},
+		{"open", domain.Subject{ID: "applied", Role: domain.StudentRole}, false},
+	}
+
+	for _, c := range cases {
+		_, err := s.FindVisible(c.studentID, c.subject)
+		if c.visible && err != nil {
+			t.Errorf("%s viewed by %s = %v, want it visible", c.studentID, c.subject.ID, err)
+		}
+
+		if !c.visible && !e.Is(err, e.ErrorForbidden) {
+			t.Errorf("%s viewed by %s = %v, want %v", c.studentID, c.subject.ID, err, e.ErrorForbidden)
+		}
+	}
+
+	if _, err := s.FindVisible("unknown", representative("r1")); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("unknown student = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+
+	for representativeID, want := range map[string][]string{"r1": {"applied", "open"}, "r2": {"open"}} {
+		found, _, err := s.Search(domain.StudentQuery{}, domain.Page{Limit: 10}, representativeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := []string{}
+		for _, student := range found {
+			ids = append(ids, student.ID)
+			if student.ID == "applied" && student.Resume != "" {
+				t.Error("search results should leave out resumes shared on request")
+			}
+		}
+
+		if !equalStrings(ids, want) {
+			t.Errorf("Search by %s = %v, want %v", representativeID, ids, want)
+		}
+	}
+}
+
+func TestResumeOnRequest(t *testing.T) {
+	s := newPrivacyService()
+	resumeOf := func(studentID string) string {
+		student, err := s.FindVisible(studentID, representative("r1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return student.Resume
+	}
+
+	if resume := resumeOf("open"); resume != "open.pdf" {
+		t.Errorf("resume of open = %q, want it shared", resume)
+	}
+
+	if resume := resumeOf("applied"); resume != "" {
+		t.Errorf("resume of applied = %q, want it only shared on request", resume)
+	}
+
+	if err := s.RequestResume("open", "r1"); e.KindOf(err) != e.Conflict {
+		t.Errorf("request for a shared resume = %v, want a conflict", err)
+	}
+
+	if err := s.RequestResume("applied", "r1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.GrantResume("applied", "c1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if resume := resumeOf("applied"); resume != "applied.pdf" {
+		t.Errorf("resume of applied after granting = %q, want it shared", resume)
+	}
+
+	if err := s.DeclineResume("applied", "c1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if resume := resumeOf("applied"); resume != "" {
+		t.Errorf("resume of applied after declining = %q, want it no longer shared", resume)
+	}
+
+	if err := s.GrantResume("applied", "c2"); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("grant without request = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+}
+
+func TestChangePrivacy(t *testing.T) {
+	s := newPrivacyService()
+	if err := s.ChangePrivacy("open", "everyone", false); e.KindOf(err) != e.Validation {
+		t.Errorf("change to unknown visibility = %v, want a validation error", err)
+	}
+
+	if err := s.ChangePrivacy("unknown", domain.Hidden, false); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("change of unknown student = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+
+	if err := s.ChangePrivacy("open", domain.Hidden, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.FindVisible("open", representative("r2")); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("hidden student = %v, want %v", err, e.ErrorForbidden)
+	}
+}
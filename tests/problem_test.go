@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/handlers"
+)
+
+func TestToProblem(t *testing.T) {
+	cases := map[error]int{
+		e.ErrorEntityNotFound:                            http.StatusNotFound,
+		e.ErrorEmailAlreadyUsed:                          http.StatusConflict,
+		e.ErrorForbidden:                                 http.StatusForbidden,
+		e.ErrorInvalidCredentials:                        http.StatusUnauthorized,
+		e.ErrorInviteExpired:                             http.StatusGone,
+		e.ErrorRateLimited:                               http.StatusTooManyRequests,
+		e.Invalid("email", "is invalid"):                 http.StatusBadRequest,
+		e.Wrap(sql.ErrConnDone, e.NotFound, "not found"): http.StatusNotFound,
+		sql.ErrConnDone:                                  http.StatusInternalServerError,
+	}
+
+	for err, want := range cases {
+		if got := handlers.ToProblem(err).Status; got != want {
+			t.Errorf("ToProblem(%q).Status = %d, want %d", err, got, want)
+		}
+	}
+}
+
+func TestToProblemDetails(t *testing.T) {
+	problem := handlers.ToProblem(e.InvalidFields(
+		e.FieldError{Field: "email", Reason: "is invalid"},
+		e.FieldError{Field: "password", Reason: "is too short"},
+	))
+
+	if problem.Title != "Bad Request" || problem.Detail == "" {
+		t.Errorf("expected the title and detail of a validation error, got %+v", problem)
+	}
+
+	if len(problem.InvalidParams) != 2 || problem.InvalidParams[1].Name != "password" {
+		t.Errorf("expected both invalid fields, got %+v", problem.InvalidParams)
+	}
+
+	// unexpected errors don't reveal what went wrong
+	if detail := handlers.ToProblem(sql.ErrConnDone).Detail; detail != "" {
+		t.Errorf("expected no detail for an unexpected error, got %q", detail)
+	}
+}
+
+func TestIs(t *testing.T) {
+	wrapped := e.Wrap(e.ErrorEntityNotFound, e.Forbidden, "not allowed")
+	if !e.Is(wrapped, e.ErrorEntityNotFound) {
+		t.Error("expected the cause of a wrapped error to be found")
+	}
+
+	if e.Is(wrapped, e.ErrorForbidden) {
+		t.Error("expected an error of the same kind not to be the same error")
+	}
+
+	if kind := e.KindOf(wrapped); kind != e.Forbidden {
+		t.Errorf("KindOf = %v, want %v", kind, e.Forbidden)
+	}
+}
+
+func TestMalformedBodyIsValidationProblem(t *testing.T) {
+	handler := handlers.RepresentativeHandler{Path: "/representatives/"}
+	req := httptest.NewRequest("POST", "/signup/representatives/invite/company-id/invite-id", strings.NewReader("{not json"))
+	rec := httptest.NewRecorder()
+
+	handler.SignupRepresentative().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", contentType)
+	}
+
+	var problem handlers.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+
+	if problem.Status != http.StatusBadRequest || problem.Detail == "" {
+		t.Errorf("expected a validation problem, got %+v", problem)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+
+	"github.com/janabe/cscoupler/database/contract"
+	"github.com/janabe/cscoupler/database/memory"
+	pg "github.com/janabe/cscoupler/database/postgres"
+	"github.com/janabe/cscoupler/database/postgres/migrations"
+)
+
+// testDSNVariable names the environment variable holding the data source name of a
+// postgres database the repository contracts may run against. All of its data is lost.
+const testDSNVariable = "CSCOUPLER_TEST_DSN"
+
+func TestMemoryRepositories(t *testing.T) {
+	contract.Run(t, func(t *testing.T) contract.Repos {
+		store := memory.NewStore()
+		return contract.Repos{
+			Users:           memory.UserRepo{Store: store},
+			Students:        memory.StudentRepo{Store: store},
+			Representatives: memory.RepresentativeRepo{Store: store},
+			Companies:       memory.CompanyRepo{Store: store},
+			Projects:        memory.ProjectRepo{Store: store},
+			InviteLinks:     memory.InviteLinkRepo{Store: store},
+			Conversations:   memory.ConversationRepo{Store: store},
+			Messages:        memory.MessageRepo{Store: store},
+			Blocks:          memory.BlockRepo{Store: store},
+			Applications:    memory.ApplicationRepo{Store: store},
+			Shortlist:       memory.ShortlistRepo{Store: store},
+			ResumeRequests:  memory.ResumeRequestRepo{Store: store},
+			Sessions:        memory.SessionRepo{Store: store},
+			UserTokens:      memory.UserTokenRepo{Store: store},
+			Outbox:          memory.OutboxRepo{Store: store},
+			UnitOfWork:      memory.UnitOfWork{Store: store},
+		}
+	})
+}
+
+func TestPostgresRepositories(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	migrateToLatest(t, db)
+	contract.Run(t, func(t *testing.T) contract.Repos {
+		truncateTables(t, db)
+
+		userRepo := pg.UserRepo{DB: db}
+		outboxRepo := pg.OutboxRepo{DB: db}
+		sessionRepo := pg.SessionRepo{DB: db}
+		reprRepo := pg.RepresentativeRepo{DB: db, UserRepo: userRepo, SessionRepo: sessionRepo}
+		return contract.Repos{
+			Users:           userRepo,
+			Students:        pg.StudentRepo{DB: db, UserRepo: userRepo},
+			Representatives: reprRepo,
+			Companies:       pg.CompanyRepo{DB: db, ReprRepo: reprRepo},
+			Projects:        pg.ProjectRepo{DB: db},
+			InviteLinks:     pg.InviteLinkRepo{DB: db, Outbox: outboxRepo},
+			Conversations:   pg.ConversationRepo{DB: db},
+			Messages:        pg.MessageRepo{DB: db, Outbox: outboxRepo},
+			Blocks:          pg.BlockRepo{DB: db},
+			Applications:    pg.ApplicationRepo{DB: db, Outbox: outboxRepo},
+			Shortlist:       pg.ShortlistRepo{DB: db},
+			ResumeRequests:  pg.ResumeRequestRepo{DB: db},
+			Sessions:        sessionRepo,
+			UserTokens:      pg.UserTokenRepo{DB: db},
+			Outbox:          outboxRepo,
+			UnitOfWork:      pg.UnitOfWork{DB: db},
+		}
+	})
+}
+
+// helper func that connects to the postgres database set in
+// CSCOUPLER_TEST_DSN, the test is skipped if there is none
+func openTestDB(t *testing.T) *sql.DB {
+	dsn := os.Getenv(testDSNVariable)
+	if dsn == "" {
+		t.Skip(testDSNVariable + " is not set, skipping the postgres tests")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// helper func that migrates the database to the latest version
+func migrateToLatest(t *testing.T, db *sql.DB) {
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// helper func that empties all tables but the one keeping track of
+// the migrations, so every contract starts without data
+func truncateTables(t *testing.T, db *sql.DB) {
+	rows, err := db.Query(`SELECT tablename FROM pg_tables WHERE schemaname = 'public'
+	AND tablename <> 'schema_migrations';`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			t.Fatal(err)
+		}
+
+		tables = append(tables, `"`+table+`"`)
+	}
+
+	if len(tables) == 0 {
+		return
+	}
+
+	_, err = db.Exec(`TRUNCATE ` + strings.Join(tables, ", ") + ` CASCADE;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+// roles of the representatives newRepresentativeService creates, by id.
+// Representatives r1 up to r4 work for c1, r5 is the owner of c2.
+var roles = map[string]domain.CompanyRole{
+	"r1": domain.CompanyOwner,
+	"r2": domain.CompanyAdmin,
+	"r3": domain.CompanyRecruiter,
+	"r4": domain.CompanyViewer,
+	"r5": domain.CompanyOwner,
+}
+
+func newRepresentativeService() (services.RepresentativeService, *memory.Store) {
+	store := memory.NewStore()
+	companies := map[string][]domain.Representative{}
+	for id, role := range roles {
+		companyID := "c1"
+		if id == "r5" {
+			companyID = "c2"
+		}
+
+		representative := domain.Representative{ID: id, CompanyID: companyID, CompanyRole: role}
+		representative.User = domain.User{ID: "u-" + id, Email: id + "@example.com", Role: domain.RepresentativeRole}
+		companies[companyID] = append(companies[companyID], representative)
+	}
+
+	for id, representatives := range companies {
+		mustSucceed(memory.CompanyRepo{Store: store}.Create(domain.Company{ID: id, Representatives: representatives}))
+	}
+
+	return services.RepresentativeService{RepresentativeRepo: memory.RepresentativeRepo{Store: store}}, store
+}
+
+func TestCompanyRolePermissions(t *testing.T) {
+	company := domain.Resource{CompanyID: "c1"}
+	allowed := map[domain.Action][]domain.CompanyRole{
+		domain.ReadApplications:     {domain.CompanyOwner, domain.CompanyAdmin, domain.CompanyRecruiter, domain.CompanyViewer},
+		domain.ManageShortlist:      {domain.CompanyOwner, domain.CompanyAdmin, domain.CompanyRecruiter},
+		domain.EditCompany:          {domain.CompanyOwner, domain.CompanyAdmin},
+		domain.InviteRepresentative: {domain.CompanyOwner, domain.CompanyAdmin},
+		domain.TransferOwnership:    {domain.CompanyOwner},
+	}
+
+	for action, wanted := range allowed {
+		for _, role := range []domain.CompanyRole{domain.CompanyOwner, domain.CompanyAdmin, domain.CompanyRecruiter, domain.CompanyViewer} {
+			want := false
+			for _, w := range wanted {
+				want = want || w == role
+			}
+
+			subject := domain.Subject{ID: "r", Role: domain.RepresentativeRole, CompanyID: "c1", CompanyRole: role}
+			if got := domain.IsAllowed(subject, action, company); got != want {
+				t.Errorf("IsAllowed(%s, %q) = %v, want %v", role, action, got, want)
+			}
+
+			// roles only count within their own company
+			subject.CompanyID = "c2"
+			if domain.IsAllowed(subject, action, company) {
+				t.Errorf("IsAllowed(%s of another company, %q) = true, want false", role, action)
+			}
+		}
+	}
+}
+
+func TestChangeCompanyRole(t *testing.T) {
+	r, _ := newRepresentativeService()
+	if err := r.ChangeCompanyRole("r3", domain.CompanyViewer); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := r.FindByID("r3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed.CompanyRole != domain.CompanyViewer {
+		t.Errorf("CompanyRole = %q, want %q", changed.CompanyRole, domain.CompanyViewer)
+	}
+
+	if err := r.ChangeCompanyRole("r3", "intern"); e.KindOf(err) != e.Validation {
+		t.Errorf("change to unknown role = %v, want a validation error", err)
+	}
+
+	// ownership can only be transferred
+	if err := r.ChangeCompanyRole("r2", domain.CompanyOwner); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("change to owner = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := r.ChangeCompanyRole("r1", domain.CompanyAdmin); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("change of the owner = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := r.ChangeCompanyRole("unknown", domain.CompanyAdmin); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("change of unknown representative = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+}
+
+func TestRemoveRepresentative(t *testing.T) {
+	r, store := newRepresentativeService()
+	mustSucceed(memory.CompanyRepo{Store: store}.AddProject(domain.Project{ID: "p1", CompanyID: "c1", ContactID: "r3"}))
+	conversation, err := domain.NewConversation("conv1", "u-r3", "u-s1", "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSucceed(memory.ConversationRepo{Store: store}.Create(conversation))
+
+	if err := r.Remove("r3", "r5"); e.KindOf(err) != e.Validation {
+		t.Errorf("remove with a successor of another company = %v, want a validation error", err)
+	}
+
+	if err := r.Remove("r3", "r3"); e.KindOf(err) != e.Validation {
+		t.Errorf("remove with themselves as successor = %v, want a validation error", err)
+	}
+
+	if err := r.Remove("r1", "r2"); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("remove of the owner = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := r.Remove("r3", "r2"); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := memory.ProjectRepo{Store: store}.FindByID("p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if project.ContactID != "r2" {
+		t.Errorf("ContactID = %q, want the project handed over to the successor", project.ContactID)
+	}
+
+	handedOver, err := memory.ConversationRepo{Store: store}.FindByID("conv1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !handedOver.HasParticipant("u-r2") || handedOver.HasParticipant("u-r3") {
+		t.Errorf("participants = %v, want the conversation handed over to the successor", handedOver.Participants)
+	}
+
+	// removed representatives can't act for their company anymore
+	policy := services.PolicyService{RepresentativeService: r}
+	if err := policy.AuthorizeRepresentative("r3", domain.ReadApplications, domain.Resource{CompanyID: "c1"}); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("removed representative = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := r.Remove("r3", "r2"); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("second removal = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+
+	if err := r.Remove("r4", "r3"); !e.Is(err, e.ErrorEntityNotFound) {
+		t.Errorf("remove with a removed successor = %v, want %v", err, e.ErrorEntityNotFound)
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	r, _ := newRepresentativeService()
+	if err := r.TransferOwnership("r2", "r3"); !e.Is(err, e.ErrorForbidden) {
+		t.Errorf("transfer by someone who isn't the owner = %v, want %v", err, e.ErrorForbidden)
+	}
+
+	if err := r.TransferOwnership("r1", "r5"); e.KindOf(err) != e.Validation {
+		t.Errorf("transfer to another company = %v, want a validation error", err)
+	}
+
+	if err := r.TransferOwnership("r1", "r3"); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[string]domain.CompanyRole{"r1": domain.CompanyAdmin, "r3": domain.CompanyOwner} {
+		representative, err := r.FindByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if representative.CompanyRole != want {
+			t.Errorf("CompanyRole of %s = %q, want %q", id, representative.CompanyRole, want)
+		}
+	}
+
+	// the former owner can be removed now
+	if err := r.Remove("r1", "r3"); err != nil {
+		t.Errorf("remove of the former owner = %v, want no error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+)
+
+func TestSearchSnippetIsEscaped(t *testing.T) {
+	store := memory.NewStore()
+	company := domain.Company{
+		ID:          "c1",
+		Name:        "acme",
+		Description: `we build <script>alert("golang")</script> services in golang`,
+	}
+	mustSucceed(memory.CompanyRepo{Store: store}.Create(company))
+
+	query, err := domain.NewSearchQuery("golang", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := memory.SearchRepo{Store: store}.Search(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hits) != 1 {
+		t.Fatalf("expected the company to be found, got %+v", hits)
+	}
+
+	snippet := hits[0].Snippet
+	if strings.Contains(snippet, "<script>") || !strings.Contains(snippet, "&lt;script&gt;") {
+		t.Errorf("snippet = %q, want the text of the company escaped", snippet)
+	}
+
+	if !strings.Contains(snippet, "<b>golang</b>") {
+		t.Errorf("snippet = %q, want the matches highlighted", snippet)
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/janabe/cscoupler/database/memory"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+func newSessionService() services.SessionService {
+	return services.SessionService{SessionRepo: memory.SessionRepo{Store: memory.NewStore()}}
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	s := newSessionService()
+	session, token, err := s.Start("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, newToken, err := s.Refresh(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if newToken == token || rotated.ID == session.ID {
+		t.Error("refreshing should give a new session with a new token")
+	}
+
+	if rotated.FamilyID != session.FamilyID || rotated.UserID != "u1" {
+		t.Errorf("rotated session = %+v, want it in the family of %+v", rotated, session)
+	}
+
+	if !s.IsActive(session.FamilyID) {
+		t.Error("family should stay active after refreshing")
+	}
+
+	if _, _, err := s.Refresh("unknown"); !e.Is(err, e.ErrorInvalidToken) {
+		t.Errorf("Refresh of unknown token = %v, want %v", err, e.ErrorInvalidToken)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	s := newSessionService()
+	session, token, err := s.Start("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _, err := s.Start("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, newToken, err := s.Refresh(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the old token being used again means it has been stolen
+	if _, _, err := s.Refresh(token); !e.Is(err, e.ErrorTokenReused) {
+		t.Fatalf("Refresh of used token = %v, want %v", err, e.ErrorTokenReused)
+	}
+
+	if s.IsActive(session.FamilyID) {
+		t.Error("family of the reused token should be revoked")
+	}
+
+	if _, _, err := s.Refresh(newToken); err == nil {
+		t.Error("token rotated to before the reuse should be revoked along with its family")
+	}
+
+	if !s.IsActive(other.FamilyID) {
+		t.Error("sessions of other devices should stay active")
+	}
+}
+
+func TestSignOutAll(t *testing.T) {
+	s := newSessionService()
+	first, _, err := s.Start("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := s.Start("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	someoneElse, _, err := s.Start("u2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SignOutAll("u1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.IsActive(first.FamilyID) || s.IsActive(second.FamilyID) {
+		t.Error("all sessions of the user should be revoked")
+	}
+
+	if !s.IsActive(someoneElse.FamilyID) {
+		t.Error("sessions of other users should stay active")
+	}
+}
+
+func TestExpiredRefreshToken(t *testing.T) {
+	s := newSessionService()
+	s.RefreshTTL = time.Nanosecond
+	_, token, err := s.Start("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Millisecond)
+	if _, _, err := s.Refresh(token); !e.Is(err, e.ErrorInvalidToken) {
+		t.Errorf("Refresh of expired token = %v, want %v", err, e.ErrorInvalidToken)
+	}
+}
@@ -2,8 +2,47 @@ package tests
 
 import (
 	"testing"
+
+	"github.com/janabe/cscoupler/domain"
 )
 
 func TestInitializer(t *testing.T) {
 
 }
+
+func TestStudentMatches(t *testing.T) {
+	unavailable := domain.Unavailable
+	student := domain.Student{
+		University:  "Hogeschool Utrecht",
+		City:        "Utrecht",
+		Skills:      []string{"Go", "SQL"},
+		Experiences: []string{"backend developer at a bank"},
+		Wishes:      "an internship in fintech",
+		Status:      domain.Available,
+	}
+
+	cases := map[string]struct {
+		query domain.StudentQuery
+		want  bool
+	}{
+		"no filters":               {domain.StudentQuery{}, true},
+		"university ignoring case": {domain.StudentQuery{University: "utrecht"}, true},
+		"other university":         {domain.StudentQuery{University: "delft"}, false},
+		"city":                     {domain.StudentQuery{City: "UTRECHT"}, true},
+		"other status":             {domain.StudentQuery{Status: &unavailable}, false},
+		"any skill":                {domain.StudentQuery{Skills: []string{"go", "java"}}, true},
+		"all skills":               {domain.StudentQuery{Skills: []string{"go", "java"}, AllSkills: true}, false},
+		"all skills present":       {domain.StudentQuery{Skills: []string{"sql", "go"}, AllSkills: true}, true},
+		"no skill":                 {domain.StudentQuery{Skills: []string{"java"}}, false},
+		"text in experiences":      {domain.StudentQuery{Text: "Backend"}, true},
+		"text in wishes":           {domain.StudentQuery{Text: "fintech"}, true},
+		"text nowhere":             {domain.StudentQuery{Text: "frontend"}, false},
+		"all filters":              {domain.StudentQuery{University: "hogeschool", City: "utrecht", Skills: []string{"go"}, Text: "bank"}, true},
+	}
+
+	for name, c := range cases {
+		if got := student.Matches(c.query); got != c.want {
+			t.Errorf("%s: Matches(%+v) = %v, want %v", name, c.query, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/janabe/cscoupler/database/memory"
+	"github.com/janabe/cscoupler/domain"
+	e "github.com/janabe/cscoupler/errors"
+	"github.com/janabe/cscoupler/services"
+)
+
+// mailbox is a mailer keeping the mails it sends
+type mailbox struct {
+	mails *[]domain.Mail
+}
+
+func (m mailbox) Send(mail domain.Mail) error {
+	*m.mails = append(*m.mails, mail)
+	return nil
+}
+
+// helper func that finds the token in the link of the latest mail
+func (m mailbox) latestToken(t *testing.T) string {
+	if len(*m.mails) == 0 {
+		t.Fatal("expected a mail to be sent")
+	}
+
+	body := (*m.mails)[len(*m.mails)-1].Body
+	i := strings.Index(body, "token=")
+	if i < 0 {
+		t.Fatalf("expected a link with a token in %q", body)
+	}
+
+	return strings.Fields(body[i+len("token="):])[0]
+}
+
+func newUserService() (services.UserService, services.SessionService, mailbox) {
+	store := memory.NewStore()
+	user := domain.User{ID: "u-s1", Email: "s1@example.com", HashedPassword: "old", Role: domain.StudentRole}
+	mustSucceed(memory.StudentRepo{Store: store}.Create(domain.Student{ID: "s1", User: user}))
+
+	mails := mailbox{mails: &[]domain.Mail{}}
+	sessions := services.SessionService{SessionRepo: memory.SessionRepo{Store: store}}
+	return services.UserService{
+		UserRepo:   memory.UserRepo{Store: store},
+		TokenRepo:  memory.UserTokenRepo{Store: store},
+		Mailer:     mails,
+		UnitOfWork: memory.UnitOfWork{Store: store},
+		ClientURL:  "http://localhost:8080",
+	}, sessions, mails
+}
+
+func TestResetPassword(t *testing.T) {
+	u, sessions, mails := newUserService()
+	session, _, err := sessions.Start("u-s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.RequestPasswordReset("unknown@example.com"); err != nil || len(*mails.mails) != 0 {
+		t.Fatalf("reset of unknown email = %v with %d mails, want no error and no mail", err, len(*mails.mails))
+	}
+
+	if err := u.RequestPasswordReset("S1@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	token := mails.latestToken(t)
+	if err := u.ResetPassword(token, "new password"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := u.FindByID("u-s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !u.ValidatePassword(user.HashedPassword, "new password") {
+		t.Error("password should be changed to the new one")
+	}
+
+	if sessions.IsActive(session.FamilyID) {
+		t.Error("sessions should be revoked when the password is reset")
+	}
+
+	// a token can only be used once
+	if err := u.ResetPassword(token, "another password"); !e.Is(err, e.ErrorInvalidToken) {
+		t.Errorf("second reset with the same token = %v, want %v", err, e.ErrorInvalidToken)
+	}
+}
+
+func TestResetPasswordWithExpiredToken(t *testing.T) {
+	u, _, _ := newUserService()
+	token, err := domain.NewUserToken("t1", "u-s1", domain.PasswordReset, "expired", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSucceed(u.TokenRepo.Create(token))
+
+	if err := u.ResetPassword("expired", "new password"); !e.Is(err, e.ErrorTokenExpired) {
+		t.Errorf("reset with expired token = %v, want %v", err, e.ErrorTokenExpired)
+	}
+
+	if err := u.ResetPassword("unknown", "new password"); !e.Is(err, e.ErrorInvalidToken) {
+		t.Errorf("reset with unknown token = %v, want %v", err, e.ErrorInvalidToken)
+	}
+
+	user, err := u.FindByID("u-s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.HashedPassword != "old" {
+		t.Error("password should stay the same when the reset fails")
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	u, _, mails := newUserService()
+	user, err := u.FindByID("u-s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.IsEmailVerified(user.ID) {
+		t.Fatal("email of a new user should not be verified")
+	}
+
+	if err := u.SendVerification(user); err != nil {
+		t.Fatal(err)
+	}
+
+	token := mails.latestToken(t)
+
+	// a verification token can't be used to reset the password
+	if err := u.ResetPassword(token, "new password"); !e.Is(err, e.ErrorInvalidToken) {
+		t.Errorf("reset with verification token = %v, want %v", err, e.ErrorInvalidToken)
+	}
+
+	if err := u.VerifyEmail(token); err != nil {
+		t.Fatal(err)
+	}
+
+	if !u.IsEmailVerified(user.ID) {
+		t.Error("email should be verified")
+	}
+
+	if err := u.VerifyEmail(token); !e.Is(err, e.ErrorInvalidToken) {
+		t.Errorf("second verification with the same token = %v, want %v", err, e.ErrorInvalidToken)
+	}
+
+	// verified users don't get another mail
+	user, err = u.FindByID("u-s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := len(*mails.mails)
+	if err := u.SendVerification(user); err != nil || len(*mails.mails) != sent {
+		t.Errorf("verification of verified user = %v with %d new mails, want none", err, len(*mails.mails)-sent)
+	}
+}
+
+func TestVerifyEmailWithExpiredToken(t *testing.T) {
+	u, _, _ := newUserService()
+	token, err := domain.NewUserToken("t1", "u-s1", domain.EmailVerification, "expired", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSucceed(u.TokenRepo.Create(token))
+
+	if err := u.VerifyEmail("expired"); !e.Is(err, e.ErrorTokenExpired) {
+		t.Errorf("verification with expired token = %v, want %v", err, e.ErrorTokenExpired)
+	}
+
+	if u.IsEmailVerified("u-s1") {
+		t.Error("email should not be verified with an expired token")
+	}
+}
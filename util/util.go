@@ -1,6 +1,8 @@
 package util
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -57,6 +59,140 @@ type dsn struct {
 	DSN string `json:"dsn"`
 }
 
+// Storage backends the app can store its data in
+const (
+	PostgresStorage = "postgres"
+	MemoryStorage   = "memory"
+)
+
+// GetStorage gets the storage backend from the provided file, being
+// either postgres or memory. Postgres is used when it is not present.
+func GetStorage(filepath string) string {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	config := storage{Storage: PostgresStorage}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	if config.Storage != PostgresStorage && config.Storage != MemoryStorage {
+		panic("storage should be " + PostgresStorage + " or " + MemoryStorage + ", got " + config.Storage)
+	}
+
+	return config.Storage
+}
+
+type storage struct {
+	Storage string `json:"storage"`
+}
+
+// MessagingConfig contains the anti-spam limits for messaging.
+// A limit of 0 means there is no limit.
+type MessagingConfig struct {
+	MaxConversationsPerRepresentative int `json:"maxConversationsPerRepresentative"`
+	MaxConversationsPerCompany        int `json:"maxConversationsPerCompany"`
+	WindowHours                       int `json:"windowHours"`
+}
+
+// GetMessagingConfig gets the messaging config from the
+// "messaging" object of the provided file. Defaults are
+// used for the fields that are not present.
+func GetMessagingConfig(filepath string) MessagingConfig {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	config := messagingConfig{
+		Messaging: MessagingConfig{
+			MaxConversationsPerRepresentative: 20,
+			MaxConversationsPerCompany:        50,
+			WindowHours:                       24,
+		},
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	return config.Messaging
+}
+
+type messagingConfig struct {
+	Messaging MessagingConfig `json:"messaging"`
+}
+
+// GetClientURL gets the base url of the client from the provided
+// file, which is used in links that are sent by mail. The url of
+// the client during development is used when it is not present.
+func GetClientURL(filepath string) string {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	config := clientURL{ClientURL: "http://localhost:8080"}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	return strings.TrimSuffix(config.ClientURL, "/")
+}
+
+type clientURL struct {
+	ClientURL string `json:"clientURL"`
+}
+
+// GetMailConfig gets the config of outgoing mails from the provided file.
+// All fields are left empty when it is not present, meaning mails should
+// be logged instead of being sent.
+func GetMailConfig(filepath string) MailConfig {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	config := mailConfig{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		fmt.Println(err)
+		panic(err)
+	}
+
+	return config.Mail
+}
+
+// MailConfig contains where outgoing mails go. Mails are sent through
+// the SMTP server when its host is set, otherwise they are written to
+// files in the directory when it is set.
+type MailConfig struct {
+	Dir  string `json:"dir"`
+	SMTP struct {
+		Host     string `json:"host"`
+		Port     int    `json:"port"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		From     string `json:"from"`
+	} `json:"smtp"`
+}
+
+type mailConfig struct {
+	Mail MailConfig `json:"mail"`
+}
+
 // HasCorrectContentType checks if the file's
 // content type matches the wanted/expected content type
 func HasCorrectContentType(file multipart.File, ct string) bool {
@@ -86,3 +222,15 @@ func CapitalizeLastWord(word string) string {
 	last := words[len(words)-1]
 	return strings.Replace(word, last, Capitalize(last), 1)
 }
+
+// GenerateToken generates a random, url safe token
+// that is hard to guess, such as a refresh token
+func GenerateToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}